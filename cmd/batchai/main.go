@@ -27,51 +27,20 @@ func main() {
 
 	check := batchai.CheckUrfaveCommand(x)
 
-	explain := &cli.Command{
-		Name:  "explain (TODO)",
-		Usage: "Explains the code, output result to console or as comment",
-		Flags: []cli.Flag{
-			&cli.BoolFlag{Name: "inline", Usage: "Explains as code comment", DefaultText: "false"},
-		},
-		Action: func(ctx *cli.Context) error {
-			fmt.Println("to be implemented")
-			x.Config.Init("explain")
-			return nil
-		},
-	}
-
-	refactor := &cli.Command{
-		Name:  "refactor (TODO)",
-		Usage: "Refactors the code",
-		Action: func(ctx *cli.Context) error {
-			fmt.Println("to be implemented")
-			x.Config.Init("refactor")
-			return nil
-		},
-	}
-
-	comment := &cli.Command{
-		Name:  "comment (TODO)",
-		Usage: "Comments the code",
-		Flags: []cli.Flag{
-			&cli.BoolFlag{Name: "level", Usage: "Level of detail (detailed, simple)"},
-		},
-		Action: func(ctx *cli.Context) error {
-			fmt.Println("to be implemented")
-			x.Config.Init("comment")
-			return nil
-		},
-	}
-
 	list := batchai.ListUrfaveCommand(x)
 	test := batchai.TestUrfaveCommand(x)
+	explain := batchai.ExplainUrfaveCommand(x)
+	comment := batchai.CommentUrfaveCommand(x)
+	refactor := batchai.RefactorUrfaveCommand(x)
+	review := batchai.ReviewUrfaveCommand(x)
+	symbols := batchai.SymbolsUrfaveCommand(x)
 
 	version := fmt.Sprintf("%s (%s)", Version, CommitId)
 
 	app := &cli.App{
 		Version:                version,
 		UseShortOptionHandling: true,
-		Commands:               []*cli.Command{check, list, test, explain, comment, refactor},
+		Commands:               []*cli.Command{check, list, test, explain, comment, refactor, review, symbols},
 		Name:                   "batchai",
 		Usage:                  "utilizes AI for batch processing of project codes",
 		Flags: []cli.Flag{
@@ -79,6 +48,12 @@ func main() {
 			&cli.BoolFlag{Name: "force", DefaultText: "false", Usage: "Ignores the cache"},
 			&cli.IntFlag{Name: "num", Aliases: []string{"n"}, DefaultText: "0", Usage: "Limits the number of file to process"},
 			&cli.BoolFlag{Name: "concurrent", DefaultText: "false", Usage: "If or not concurrent processing"},
+			&cli.IntFlag{Name: "workers", Aliases: []string{"w"}, DefaultText: "number of CPUs if --concurrent", Usage: "Limits the number of files processed concurrently, implies --concurrent if more than 1"},
+			&cli.BoolFlag{Name: "ordered-output", DefaultText: "false", Usage: "With --concurrent, prints the output of each file in the order of files instead of the order of completion"},
+			&cli.StringFlag{Name: "since", Usage: "Processes only the files changed since the specified git ref, e.g. origin/main"},
+			&cli.BoolFlag{Name: "staged", DefaultText: "false", Usage: "Processes only the staged files"},
+			&cli.BoolFlag{Name: "changed", DefaultText: "false", Usage: "Processes only the files changed in the working tree, including the untracked files"},
+			&cli.StringFlag{Name: "metrics-out", Usage: "Saves the summary metrics to the specified json file"},
 			&cli.BoolFlag{Name: "verbose", Hidden: true},
 			&cli.StringFlag{
 				Name:        "lang",
@@ -90,11 +65,19 @@ func main() {
 		},
 		Args:      true,
 		ArgsUsage: "<repository directory>  [target files/directories in the repository]",
+		// the exit code is decided below, instead of exiting immediately
+		ExitErrHandler: func(*cli.Context, error) {},
 	}
 
 	c := comm.NewConsole(true)
+	exitCode := batchai.EXIT_CODE_OK
 	if err := app.Run(os.Args); err != nil {
 		c.Redf("%+v\n", err)
+
+		exitCode = batchai.EXIT_CODE_ERROR
+		if exitErr, ok := err.(cli.ExitCoder); ok {
+			exitCode = exitErr.ExitCode()
+		}
 	}
 	c.Greenf(`
 
@@ -105,4 +88,6 @@ func main() {
 
 `, version)
 	c.Defaultln()
+
+	os.Exit(exitCode)
 }
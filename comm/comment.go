@@ -0,0 +1,172 @@
+package comm
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+type commentSyntaxT struct {
+	line       string
+	blockBegin string
+	blockEnd   string
+	quotes     string
+	docStrings bool
+	// the line comment begins a word only, e.g. `$#` and `${#arr[@]}` of shell aren't comments
+	wordComment bool
+}
+
+type commentSyntax = *commentSyntaxT
+
+var (
+	cStyleComment    = &commentSyntaxT{line: "//", blockBegin: "/*", blockEnd: "*/", quotes: "\"'"}
+	jsStyleComment   = &commentSyntaxT{line: "//", blockBegin: "/*", blockEnd: "*/", quotes: "\"'`"}
+	rustStyleComment = &commentSyntaxT{line: "//", blockBegin: "/*", blockEnd: "*/", quotes: "\""}
+	hashStyleComment = &commentSyntaxT{line: "#", quotes: "\"'", wordComment: true}
+	pyStyleComment   = &commentSyntaxT{line: "#", quotes: "\"'", docStrings: true}
+	luaStyleComment  = &commentSyntaxT{line: "--", blockBegin: "--[[", blockEnd: "]]", quotes: "\"'"}
+)
+
+var commentSyntaxes = map[string]commentSyntax{
+	".go":     jsStyleComment,
+	".java":   cStyleComment,
+	".kt":     cStyleComment,
+	".scala":  cStyleComment,
+	".cs":     cStyleComment,
+	".c":      cStyleComment,
+	".cc":     cStyleComment,
+	".cpp":    cStyleComment,
+	".h":      cStyleComment,
+	".hpp":    cStyleComment,
+	".swift":  cStyleComment,
+	".php":    cStyleComment,
+	".proto":  cStyleComment,
+	".ts":     jsStyleComment,
+	".js":     jsStyleComment,
+	".rs":     rustStyleComment,
+	".py":     pyStyleComment,
+	".python": pyStyleComment,
+	".ruby":   hashStyleComment,
+	".rb":     hashStyleComment,
+	".pl":     hashStyleComment,
+	".sh":     hashStyleComment,
+	".yaml":   hashStyleComment,
+	".yml":    hashStyleComment,
+	".lua":    luaStyleComment,
+}
+
+func resolveCommentSyntax(file string) commentSyntax {
+	base := filepath.Base(file)
+	if base == "Dockerfile" {
+		return hashStyleComment
+	}
+	return commentSyntaxes[strings.ToLower(filepath.Ext(base))]
+}
+
+// LineCommentPrefix returns the line comment prefix of the programming language
+// of the specified file, or empty string if the language is unknown or has no line comment
+func LineCommentPrefix(file string) string {
+	syntax := resolveCommentSyntax(file)
+	if syntax == nil {
+		return ""
+	}
+	return syntax.line
+}
+
+// ToLineComments converts the text to be line comments using the specified prefix
+func ToLineComments(prefix string, text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if len(line) == 0 {
+			lines[i] = prefix
+		} else {
+			lines[i] = prefix + " " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CodeWithoutComments removes the comments from the code, as well as blank lines and
+// trailing spaces, so that 2 versions of code could be compared regardless of comments.
+// Returns false if the language of the code is unknown.
+func CodeWithoutComments(file string, code string) (string, bool) {
+	syntax := resolveCommentSyntax(file)
+	if syntax == nil {
+		return code, false
+	}
+
+	var buf strings.Builder
+	n := len(code)
+	for i := 0; i < n; {
+		if syntax.docStrings && (strings.HasPrefix(code[i:], `"""`) || strings.HasPrefix(code[i:], "'''")) {
+			// treats python docstrings as comments
+			delim := code[i : i+3]
+			end := strings.Index(code[i+3:], delim)
+			if end < 0 {
+				break
+			}
+			i += 3 + end + 3
+			continue
+		}
+
+		if len(syntax.blockBegin) > 0 && strings.HasPrefix(code[i:], syntax.blockBegin) {
+			end := strings.Index(code[i+len(syntax.blockBegin):], syntax.blockEnd)
+			if end < 0 {
+				break
+			}
+			i += len(syntax.blockBegin) + end + len(syntax.blockEnd)
+			continue
+		}
+
+		if strings.HasPrefix(code[i:], syntax.line) && (!syntax.wordComment || i == 0 || strings.IndexByte(" \t\r\n;&|(", code[i-1]) >= 0) {
+			end := strings.IndexByte(code[i:], '\n')
+			if end < 0 {
+				break
+			}
+			i += end
+			continue
+		}
+
+		ch := code[i]
+		if strings.IndexByte(syntax.quotes, ch) >= 0 {
+			// copies the string literal as is
+			j := i + 1
+			for ; j < n && code[j] != ch; j++ {
+				if code[j] == '\\' && ch != '`' {
+					j++
+				} else if code[j] == '\n' && ch != '`' {
+					break
+				}
+			}
+			if j >= n {
+				j = n - 1
+			}
+			buf.WriteString(code[i : j+1])
+			i = j + 1
+			continue
+		}
+
+		buf.WriteByte(ch)
+		i++
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+// OnlyCommentsChanged tells whether or not the changes between the old code and the new code
+// are all about comments
+func OnlyCommentsChanged(file string, oldCode string, newCode string) bool {
+	oldCode, known := CodeWithoutComments(file, oldCode)
+	if !known {
+		return false
+	}
+	newCode, _ = CodeWithoutComments(file, newCode)
+	return oldCode == newCode
+}
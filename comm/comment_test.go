@@ -0,0 +1,45 @@
+package comm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineCommentPrefix(t *testing.T) {
+	a := require.New(t)
+
+	a.Equal("//", LineCommentPrefix("a/b.go"))
+	a.Equal("#", LineCommentPrefix("a/b.py"))
+	a.Equal("#", LineCommentPrefix("a/Dockerfile"))
+	a.Equal("--", LineCommentPrefix("a/b.lua"))
+	a.Equal("", LineCommentPrefix("a/b.json"))
+}
+
+func TestOnlyCommentsChanged(t *testing.T) {
+	a := require.New(t)
+
+	oldCode := "package a\n\nfunc F() string {\n\treturn \"http://x\"\n}\n"
+
+	// adds doc comments only
+	newCode := "package a\n\n// F returns the url\n/* block\ncomment */\nfunc F() string { // trailing\n\treturn \"http://x\"\n}\n"
+	a.True(OnlyCommentsChanged("a.go", oldCode, newCode))
+
+	// the string literal looks like a comment but is changed
+	newCode = "package a\n\n// F returns the url\nfunc F() string {\n\treturn \"http://y\"\n}\n"
+	a.False(OnlyCommentsChanged("a.go", oldCode, newCode))
+
+	// python docstrings are treated as comments
+	a.True(OnlyCommentsChanged("a.py", "def f():\n    return 1\n", "def f():\n    \"\"\"Returns 1.\"\"\"\n    return 1 # one\n"))
+	a.False(OnlyCommentsChanged("a.py", "def f():\n    return 1\n", "def f():\n    return 2\n"))
+
+	// `#` in the string literal or after `$` isn't a comment
+	a.False(OnlyCommentsChanged("a.py", "x = \"#\"\n", "x = \"#1\"\n"))
+	a.True(OnlyCommentsChanged("a.py", "x = \"#\"\n", "x = \"#\"  # hash\n"))
+	a.False(OnlyCommentsChanged("a.sh", "n=${#arr[@]}\necho $#\n", "n=${#arr}\necho $#\n"))
+	a.False(OnlyCommentsChanged("a.sh", "echo $#\n", "echo $# 1\n"))
+	a.True(OnlyCommentsChanged("a.sh", "n=${#arr[@]}\necho $#\n", "# count\nn=${#arr[@]} # of arr\necho $# # args\n"))
+
+	// unknown language is never treated as comments only
+	a.False(OnlyCommentsChanged("a.json", "{}", "{}"))
+}
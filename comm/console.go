@@ -34,6 +34,7 @@ type ConsoleT struct {
 
 	passThroughBuffer bool
 	buf               []string
+	output            func(text string)
 }
 
 type Console = *ConsoleT
@@ -196,10 +197,21 @@ func (me Console) End() {
 		return
 	}
 
-	fmt.Print(strings.Join(me.buf, ""))
+	text := strings.Join(me.buf, "")
+	if me.output == nil {
+		fmt.Print(text)
+	} else {
+		me.output(text)
+	}
 	me.buf = nil
 }
 
+// WithOutput sets where the buffered text goes to when End() is called, instead of the stdout
+func (me Console) WithOutput(output func(text string)) Console {
+	me.output = output
+	return me
+}
+
 func NewConsole(passThroughBuffer bool) Console {
 	return &ConsoleT{
 		indent:            0,
@@ -1,6 +1,7 @@
 package comm
 
 import (
+	"fmt"
 	"os/exec"
 	"path"
 	"strings"
@@ -37,6 +38,97 @@ func GetUnstagedFiles(fs afero.Fs, workDir string) ([]string, error) {
 	return lines, nil
 }
 
+// GetGitDiffFiles lists the absolute paths of the files under the working directory, which are
+// added, copied, modified or renamed per `git diff <diffArgs>`, e.g. `--cached` for the staged files, or a ref for the changes since it
+func GetGitDiffFiles(fs afero.Fs, workDir string, diffArgs ...string) ([]string, error) {
+	args := append([]string{"diff", "--name-only", "-z", "--relative", "--diff-filter=ACMR"}, diffArgs...)
+	output, err := ExecGit(fs, workDir, args, false)
+	if err != nil {
+		return nil, err
+	}
+	return gitOutputToPaths(fs, workDir, output)
+}
+
+// GetUntrackedFiles lists the absolute paths of the untracked files which are not ignored
+func GetUntrackedFiles(fs afero.Fs, workDir string) ([]string, error) {
+	output, err := ExecGit(fs, workDir, []string{"ls-files", "-z", "--others", "--exclude-standard"}, false)
+	if err != nil {
+		return nil, err
+	}
+	return gitOutputToPaths(fs, workDir, output)
+}
+
+// GetGitDiff returns the diff hunks of the file per `git diff <diffArgs> -- <file>`
+func GetGitDiff(fs afero.Fs, workDir string, file string, diffArgs ...string) (string, error) {
+	args := append([]string{"diff", "--no-color", "--no-ext-diff"}, diffArgs...)
+	args = append(args, "--", file)
+	return ExecGit(fs, workDir, args, false)
+}
+
+// IsGitTracked tells if the file is tracked by git, i.e. neither untracked nor ignored
+func IsGitTracked(fs afero.Fs, workDir string, file string) bool {
+	_, err := ExecGit(fs, workDir, []string{"ls-files", "--error-unmatch", "--", file}, true)
+	return err == nil
+}
+
+// AddedFileGitDiff returns the diff of a new file, all lines of which are added. `git diff` outputs nothing for an untracked file.
+func AddedFileGitDiff(relativePath string, code string) string {
+	lines := strings.SplitAfter(code, "\n")
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+
+	var r strings.Builder
+	fmt.Fprintf(&r, "--- /dev/null\n+++ b/%s\n@@ -0,0 +1,%d @@\n", relativePath, len(lines))
+	for _, line := range lines {
+		r.WriteString("+" + line)
+	}
+	if !strings.HasSuffix(code, "\n") && len(code) > 0 {
+		r.WriteString("\n\\ No newline at end of file\n")
+	}
+	return r.String()
+}
+
+// GetGitRevision resolves the ref to the full commit SHA
+func GetGitRevision(fs afero.Fs, workDir string, ref string) (string, error) {
+	return ExecGit(fs, workDir, []string{"rev-parse", "--verify", "--quiet", ref + "^{commit}"}, true)
+}
+
+// HasGitCommit tells if HEAD refers to a commit, that is false for a repository with no commit yet
+func HasGitCommit(fs afero.Fs, workDir string) bool {
+	_, err := GetGitRevision(fs, workDir, "HEAD")
+	return err == nil
+}
+
+// GetGitMergeBase returns the SHA of the best common ancestor of the 2 refs
+func GetGitMergeBase(fs afero.Fs, workDir string, ref1 string, ref2 string) (string, error) {
+	return ExecGit(fs, workDir, []string{"merge-base", ref1, ref2}, true)
+}
+
+// ShortenGitSha returns the abbreviated SHA for display
+func ShortenGitSha(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func gitOutputToPaths(fs afero.Fs, workDir string, output string) ([]string, error) {
+	workDir, err := AbsPath(workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// the paths are relative to the working directory, and separated by NUL (`-z`) so that they're not quoted
+	r := []string{}
+	for _, p := range strings.Split(output, "\x00") {
+		if len(p) > 0 {
+			r = append(r, path.Join(workDir, p))
+		}
+	}
+	return r, nil
+}
+
 func ExecGit(fs afero.Fs, workDir string, args []string, trim bool) (string, error) {
 	var err error
 
@@ -0,0 +1,34 @@
+package comm
+
+import (
+	"context"
+	"os/exec"
+	"unicode/utf8"
+)
+
+// ExecShell runs the command line by the shell of the OS under the working directory,
+// and returns the combined stdout and stderr. The error is *exec.ExitError if the command exits with non-zero code.
+func ExecShell(ctx context.Context, workDir string, commandLine string) (string, error) {
+	var command *exec.Cmd
+	if IsWindows() {
+		command = exec.CommandContext(ctx, "cmd", "/C", commandLine)
+	} else {
+		command = exec.CommandContext(ctx, "sh", "-c", commandLine)
+	}
+	command.Dir = workDir
+
+	output, err := command.CombinedOutput()
+	return string(output), err
+}
+
+// TailText keeps the last maxLen bytes of the text, e.g. the most relevant part of a build output
+func TailText(text string, maxLen int) string {
+	if len(text) <= maxLen {
+		return text
+	}
+	start := len(text) - maxLen
+	for start < len(text) && !utf8.RuneStart(text[start]) {
+		start++
+	}
+	return "...\n" + text[start:]
+}
@@ -0,0 +1,115 @@
+package comm
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// WorkerPoolT runs the submitted tasks with a bounded number of goroutines.
+// Each task gets an output function to flush its buffered output: either as soon as
+// it is flushed (streamed), or in the order the tasks were submitted (ordered).
+type WorkerPoolT struct {
+	slots   chan struct{}
+	wg      sync.WaitGroup
+	ordered bool
+	out     io.Writer
+
+	mutex     sync.Mutex
+	submitted int
+	flushed   int
+	outputs   map[int][]string
+	done      map[int]bool
+
+	running    atomic.Int32
+	maxRunning atomic.Int32
+}
+
+type WorkerPool = *WorkerPoolT
+
+func NewWorkerPool(size int, ordered bool) WorkerPool {
+	if size <= 0 {
+		size = 1
+	}
+
+	return &WorkerPoolT{
+		slots:   make(chan struct{}, size),
+		ordered: ordered,
+		out:     os.Stdout,
+		outputs: map[int][]string{},
+		done:    map[int]bool{},
+	}
+}
+
+func (me WorkerPool) Size() int {
+	return cap(me.slots)
+}
+
+// MaxRunning returns the most tasks observed running at the same time
+func (me WorkerPool) MaxRunning() int {
+	return int(me.maxRunning.Load())
+}
+
+// Submit runs the task once a worker is free, and blocks the caller while all workers are busy
+func (me WorkerPool) Submit(task func(output func(text string))) {
+	me.mutex.Lock()
+	index := me.submitted
+	me.submitted++
+	me.mutex.Unlock()
+
+	me.slots <- struct{}{}
+	me.wg.Add(1)
+
+	go func() {
+		defer me.wg.Done()
+		defer func() { <-me.slots }()
+		defer me.complete(index)
+
+		running := me.running.Add(1)
+		defer me.running.Add(-1)
+		for {
+			maxRunning := me.maxRunning.Load()
+			if running <= maxRunning || me.maxRunning.CompareAndSwap(maxRunning, running) {
+				break
+			}
+		}
+
+		task(func(text string) { me.write(index, text) })
+	}()
+}
+
+// Wait waits for all submitted tasks to complete
+func (me WorkerPool) Wait() {
+	me.wg.Wait()
+}
+
+func (me WorkerPool) write(index int, text string) {
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+
+	if !me.ordered || index == me.flushed {
+		fmt.Fprint(me.out, text)
+		return
+	}
+	me.outputs[index] = append(me.outputs[index], text)
+}
+
+func (me WorkerPool) complete(index int) {
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+
+	me.done[index] = true
+
+	// flush the outputs of the tasks that are now at the head of the order
+	for me.done[me.flushed] {
+		delete(me.done, me.flushed)
+		me.flushed++
+
+		for _, text := range me.outputs[me.flushed] {
+			fmt.Fprint(me.out, text)
+		}
+		delete(me.outputs, me.flushed)
+	}
+}
@@ -0,0 +1,84 @@
+package comm
+
+import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPoolLimit(t *testing.T) {
+	a := require.New(t)
+
+	pool := NewWorkerPool(3, false)
+	pool.out = &bytes.Buffer{}
+
+	var running, maxRunning, completed int32
+	for i := 0; i < 20; i++ {
+		pool.Submit(func(output func(text string)) {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&completed, 1)
+		})
+	}
+	pool.Wait()
+
+	a.Equal(int32(20), completed)
+	a.Equal(int32(3), maxRunning)
+	a.Equal(3, pool.MaxRunning())
+}
+
+func TestWorkerPoolOrderedOutput(t *testing.T) {
+	a := require.New(t)
+
+	pool := NewWorkerPool(4, true)
+	out := &bytes.Buffer{}
+	pool.out = out
+
+	expected := ""
+	for i := 0; i < 8; i++ {
+		i := i
+		expected += fmt.Sprintf("<%d>", i)
+
+		pool.Submit(func(output func(text string)) {
+			// the later submitted, the sooner completed
+			time.Sleep(time.Duration(8-i) * 3 * time.Millisecond)
+			output(fmt.Sprintf("<%d", i))
+			output(">")
+		})
+	}
+	pool.Wait()
+
+	a.Equal(expected, out.String())
+}
+
+func TestWorkerPoolStreamedOutput(t *testing.T) {
+	a := require.New(t)
+
+	pool := NewWorkerPool(2, false)
+	out := &bytes.Buffer{}
+	pool.out = out
+
+	pool.Submit(func(output func(text string)) {
+		time.Sleep(20 * time.Millisecond)
+		output("slow")
+	})
+	pool.Submit(func(output func(text string)) {
+		output("fast")
+	})
+	pool.Wait()
+
+	a.Equal("fastslow", out.String())
+}
@@ -0,0 +1,217 @@
+package batchai
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/qiangyt/batchai/comm"
+)
+
+const (
+	ANTHROPIC_DEFAULT_BASE_URL         = "https://api.anthropic.com/v1/"
+	ANTHROPIC_API_VERSION              = "2023-06-01"
+	ANTHROPIC_DEFAULT_MAX_TOKENS int64 = 4096
+)
+
+type anthropicMessageT struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequestT struct {
+	Model       string              `json:"model"`
+	System      string              `json:"system,omitempty"`
+	Messages    []anthropicMessageT `json:"messages"`
+	MaxTokens   int64               `json:"max_tokens"`
+	Temperature float64             `json:"temperature"`
+	Stream      bool                `json:"stream,omitempty"`
+}
+
+type anthropicUsageT struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+}
+
+type anthropicResponseT struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string          `json:"stop_reason"`
+	Usage      anthropicUsageT `json:"usage"`
+}
+
+type anthropicStreamEventT struct {
+	Type    string             `json:"type"`
+	Message anthropicResponseT `json:"message"`
+	Delta   struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage anthropicUsageT `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// AnthropicProviderT talks to the Anthropic Messages API natively
+type AnthropicProviderT struct {
+	config     ModelConfig
+	httpClient *http.Client
+}
+
+type AnthropicProvider = *AnthropicProviderT
+
+func NewAnthropicProvider(config ModelConfig) AnthropicProvider {
+	return &AnthropicProviderT{
+		config:     config,
+		httpClient: buildHttpClient(config),
+	}
+}
+
+func (me AnthropicProvider) url() string {
+	baseUrl := me.config.BaseUrl
+	if len(baseUrl) == 0 {
+		baseUrl = ANTHROPIC_DEFAULT_BASE_URL
+	}
+	return strings.TrimSuffix(baseUrl, "/") + "/messages"
+}
+
+func (me AnthropicProvider) headers() map[string]string {
+	return map[string]string{
+		"x-api-key":         me.config.ApiKey,
+		"anthropic-version": ANTHROPIC_API_VERSION,
+	}
+}
+
+func (me AnthropicProvider) newRequest(memory ChatMemory, requestedMaxCompletionTokens int64, stream bool) *anthropicRequestT {
+	// system messages are not part of the conversation in Anthropic Messages API
+	systemPrompts := []string{}
+	msgs := []anthropicMessageT{}
+	for _, msg := range memory.Messages() {
+		if msg.Role == "system" {
+			systemPrompts = append(systemPrompts, msg.Content)
+		} else {
+			msgs = append(msgs, anthropicMessageT{Role: msg.Role, Content: msg.Content})
+		}
+	}
+
+	maxTokens := requestedMaxCompletionTokens
+	if maxTokens <= 0 {
+		maxTokens = ANTHROPIC_DEFAULT_MAX_TOKENS
+	}
+
+	return &anthropicRequestT{
+		Model:       me.config.Name,
+		System:      strings.Join(systemPrompts, "\n\n"),
+		Messages:    msgs,
+		MaxTokens:   maxTokens,
+		Temperature: me.config.Temperature,
+		Stream:      stream,
+	}
+}
+
+func anthropicTokenUsage(usage anthropicUsageT) TokenUsage {
+	return &TokenUsageT{
+		PromptTokens:     usage.InputTokens,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      usage.InputTokens + usage.OutputTokens,
+	}
+}
+
+func (me AnthropicProvider) Chat(x Kontext, memory ChatMemory, requestedMaxCompletionTokens int64) ChatAnswer {
+	resp := postModelApi(x, me.httpClient, me.url(), me.headers(), me.newRequest(memory, requestedMaxCompletionTokens, false))
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		panic(errors.Wrap(err, "failed to read response of messages API"))
+	}
+
+	r := &anthropicResponseT{}
+	comm.FromJsonP(string(body), false, r)
+
+	texts := []string{}
+	for _, content := range r.Content {
+		if content.Type == "text" {
+			texts = append(texts, content.Text)
+		}
+	}
+
+	return &ChatAnswerT{
+		Content: strings.Join(texts, ""),
+		Usage:   anthropicTokenUsage(r.Usage),
+	}
+}
+
+func (me AnthropicProvider) ChatStream(x Kontext, memory ChatMemory, output io.Writer, requestedMaxCompletionTokens int64) ChatAnswer {
+	resp := postModelApi(x, me.httpClient, me.url(), me.headers(), me.newRequest(memory, requestedMaxCompletionTokens, true))
+	defer resp.Body.Close()
+
+	w := NewStreamLineWriter(output)
+
+	var content strings.Builder
+	usage := anthropicUsageT{}
+	stopped := false
+
+	err := comm.ReadServerSentEvents(resp.Body, func(event string, data string) error {
+		evt := &anthropicStreamEventT{}
+		if err := comm.FromJson(data, false, evt); err != nil {
+			return err
+		}
+
+		switch evt.Type {
+		case "message_start":
+			usage.InputTokens = evt.Message.Usage.InputTokens
+		case "content_block_delta":
+			if evt.Delta.Type == "text_delta" {
+				content.WriteString(evt.Delta.Text)
+				w.WriteChunk(evt.Delta.Text)
+			}
+		case "message_delta":
+			usage.OutputTokens = evt.Usage.OutputTokens
+		case "message_stop":
+			stopped = true
+		case "error":
+			return &ModelApiErrorT{StatusCode: anthropicErrorStatusCode(evt.Error.Type), Body: data}
+		}
+		return nil
+	})
+
+	w.Flush()
+
+	if err != nil {
+		panic(errors.Wrap(err, "failed to stream messages API"))
+	}
+	if !stopped {
+		panic(errors.Wrap(ErrTruncatedStream, "messages API"))
+	}
+
+	return &ChatAnswerT{
+		Content: content.String(),
+		Usage:   anthropicTokenUsage(usage),
+	}
+}
+
+// anthropicErrorStatusCode maps the error type of a streamed error event to the equivalent HTTP status code
+func anthropicErrorStatusCode(errorType string) int {
+	switch errorType {
+	case "rate_limit_error":
+		return http.StatusTooManyRequests
+	case "overloaded_error":
+		return 529
+	case "api_error":
+		return http.StatusInternalServerError
+	case "authentication_error":
+		return http.StatusUnauthorized
+	case "permission_error":
+		return http.StatusForbidden
+	case "not_found_error":
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
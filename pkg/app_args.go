@@ -2,12 +2,25 @@ package batchai
 
 import (
 	"fmt"
+	"runtime"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/qiangyt/batchai/comm"
 	"github.com/urfave/cli/v2"
 )
 
+// exit codes of batchai, so that it could be used as a CI quality gate
+const (
+	EXIT_CODE_OK = 0
+	// found issues at or above the severity threshold
+	EXIT_CODE_ISSUES = 1
+	// failed to process some files, e.g. because of model or transport failures
+	EXIT_CODE_FAILURES = 2
+	// invalid arguments, or other errors
+	EXIT_CODE_ERROR = 3
+)
+
 type AppArgsT struct {
 	EnableSymbolReference  bool
 	Verbose                bool
@@ -17,6 +30,14 @@ type AppArgsT struct {
 	Force                  bool
 	NumberOfFilesToProcess int
 	Concurrent             bool
+	Workers                int
+	OrderedOutput          bool
+	MetricsOut             string
+	Since                  string
+	Staged                 bool
+	Changed                bool
+	// the repository has no commit yet, so --changed takes the staged files instead of the diff against HEAD
+	NoCommits bool
 }
 
 type AppArgs = *AppArgsT
@@ -28,6 +49,17 @@ func (me AppArgs) WithCliContext(x Kontext, cliContext *cli.Context) error {
 	me.Lang = cliContext.String("lang")
 	me.NumberOfFilesToProcess = cliContext.Int("num")
 	me.Concurrent = cliContext.IsSet("concurrent")
+	me.OrderedOutput = cliContext.IsSet("ordered-output")
+
+	me.Workers = cliContext.Int("workers")
+	if me.Workers < 0 {
+		return fmt.Errorf("invalid --workers: %d", me.Workers)
+	}
+	if me.Workers > 1 {
+		me.Concurrent = true
+	} else if me.Workers == 0 && me.Concurrent {
+		me.Workers = runtime.NumCPU()
+	}
 
 	if len(me.Lang) > 0 {
 		x.Config.Lang = me.Lang
@@ -45,6 +77,18 @@ func (me AppArgs) WithCliContext(x Kontext, cliContext *cli.Context) error {
 		return fmt.Errorf("%s is NOT a git repository directory", me.Repository)
 	}
 
+	me.Since = cliContext.String("since")
+	me.Staged = cliContext.IsSet("staged")
+	me.Changed = cliContext.IsSet("changed")
+	if err := me.validateGitDiffFilter(); err != nil {
+		return err
+	}
+	me.NoCommits = me.Changed && !comm.HasGitCommit(x.Fs, me.Repository)
+
+	if metricsOut := cliContext.String("metrics-out"); len(metricsOut) > 0 {
+		me.MetricsOut = comm.AbsPathWithP(metricsOut, workDir)
+	}
+
 	me.TargetPaths = []string{}
 	if len(repoAndFiles) > 1 {
 		for _, f := range repoAndFiles[1:] {
@@ -58,3 +102,56 @@ func (me AppArgs) WithCliContext(x Kontext, cliContext *cli.Context) error {
 
 	return nil
 }
+
+// EnsureNoUnstagedFiles refuses to go on if there are local changes not yet staged,
+// so that the changes made by batchai could be reviewed and reverted easily
+func (me AppArgs) EnsureNoUnstagedFiles(x Kontext, action string) error {
+	unstagedFiles, err := comm.GetUnstagedFiles(x.Fs, me.Repository)
+	if err != nil {
+		return errors.Wrap(err, "failed to check unstaged files")
+	}
+	if len(unstagedFiles) > 0 {
+		return fmt.Errorf("please stage your local changes before %s.\nunstaged files: \n%s", action, strings.Join(unstagedFiles, "\n"))
+	}
+	return nil
+}
+
+func (me AppArgs) validateGitDiffFilter() error {
+	amount := 0
+	if len(me.Since) > 0 {
+		amount++
+	}
+	if me.Staged {
+		amount++
+	}
+	if me.Changed {
+		amount++
+	}
+
+	if amount > 1 {
+		return errors.New("--since, --staged and --changed are mutually exclusive")
+	}
+	return nil
+}
+
+// HasGitDiffFilter tells if only the files changed per git diff are to be processed
+func (me AppArgs) HasGitDiffFilter() bool {
+	return len(me.Since) > 0 || me.Staged || me.Changed
+}
+
+// GitDiffArgs returns the arguments of `git diff` for the files to process
+func (me AppArgs) GitDiffArgs() []string {
+	if len(me.Since) > 0 {
+		return []string{me.Since}
+	}
+	if me.Staged {
+		return []string{"--cached"}
+	}
+	if me.Changed {
+		if me.NoCommits {
+			return []string{"--cached"}
+		}
+		return []string{"HEAD"}
+	}
+	return nil
+}
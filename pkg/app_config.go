@@ -26,12 +26,16 @@ func LoadEnv(fs afero.Fs) {
 }
 
 type AppConfigT struct {
-	Excludes []string      `mapstructure:"excludes"`
-	CacheDir string        `mapstructure:"cache_dir"`
-	Lang     string        `mapstructure:"lang"`
-	Test     TestConfig    `mapstructure:"test"`
-	Check    CheckConfig   `mapstructure:"check"`
-	Models   []ModelConfig `mapstructure:"models"`
+	Excludes []string       `mapstructure:"excludes"`
+	CacheDir string         `mapstructure:"cache_dir"`
+	Lang     string         `mapstructure:"lang"`
+	Test     TestConfig     `mapstructure:"test"`
+	Check    CheckConfig    `mapstructure:"check"`
+	Explain  ExplainConfig  `mapstructure:"explain"`
+	Comment  CommentConfig  `mapstructure:"comment"`
+	Refactor RefactorConfig `mapstructure:"refactor"`
+	Review   ReviewConfig   `mapstructure:"review"`
+	Models   []ModelConfig  `mapstructure:"models"`
 
 	include comm.FileMatch
 	exclude comm.FileMatch
@@ -69,6 +73,14 @@ func (me AppConfig) Init(command string) {
 		me.include = comm.CompileMatchLines(nil, me.Test.Includes...)
 	case "check":
 		me.include = comm.CompileMatchLines(nil, me.Check.Includes...)
+	case "explain":
+		me.include = comm.CompileMatchLines(nil, me.Explain.Includes...)
+	case "comment":
+		me.include = comm.CompileMatchLines(nil, me.Comment.Includes...)
+	case "refactor":
+		me.include = comm.CompileMatchLines(nil, me.Refactor.Includes...)
+	case "review":
+		me.include = comm.CompileMatchLines(nil, me.Review.Includes...)
 	default:
 		me.include = comm.CompileMatchLines(nil, me.Check.Includes...)
 	}
@@ -91,6 +103,26 @@ func (me AppConfig) Init(command string) {
 		me.Check = &CheckConfigT{}
 	}
 	me.Check.Init(me)
+
+	if me.Explain == nil {
+		me.Explain = &ExplainConfigT{}
+	}
+	me.Explain.Init(me)
+
+	if me.Comment == nil {
+		me.Comment = &CommentConfigT{}
+	}
+	me.Comment.Init(me)
+
+	if me.Refactor == nil {
+		me.Refactor = &RefactorConfigT{}
+	}
+	me.Refactor.Init(me)
+
+	if me.Review == nil {
+		me.Review = &ReviewConfigT{}
+	}
+	me.Review.Init(me)
 }
 
 func (me AppConfig) LoadModel(modelId string) ModelConfig {
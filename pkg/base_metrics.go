@@ -9,12 +9,16 @@ import (
 type BaseMetricsT struct {
 	ModelUsageMetricsT
 
-	Files     int
-	Processed int
-	Succeeded int
-	Ignored   int
-	Failed    int
-	Skipped   int
+	Files     int `json:"files"`
+	Processed int `json:"processed"`
+	Succeeded int `json:"succeeded"`
+	Ignored   int `json:"ignored"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+
+	// wall-clock time of the whole run
+	Elapsed   time.Duration `json:"elapsed"`
+	startTime time.Time
 }
 
 type BaseMetrics = *BaseMetricsT
@@ -22,11 +26,22 @@ type BaseMetrics = *BaseMetricsT
 func NewBaseMetrics() BaseMetrics {
 	return &BaseMetricsT{
 		ModelUsageMetricsT: *NewModelUsageMetrics(),
+		startTime:          time.Now(),
 	}
 }
 
+// WithWorkingFiles records the result of CollectWorkingFiles()
+func (me BaseMetrics) WithWorkingFiles(targetFiles []string, ignored int, failed int) {
+	me.Files = len(targetFiles)
+	me.Ignored += ignored
+	me.Failed += failed
+}
+
 func (me BaseMetrics) PreparePrint(console comm.Console) {
+	me.Elapsed = time.Since(me.startTime)
+
 	me.ModelUsageMetricsT.Print(console, comm.GREEN)
+	console.NewLine().Greenf("Elapsed: %v", comm.FormatDurationForConsole(me.Elapsed))
 
 	if me.Processed == 0 {
 		console.NewLine().Green("Average time: 0")
@@ -36,3 +51,14 @@ func (me BaseMetrics) PreparePrint(console comm.Console) {
 
 	console.NewLine()
 }
+
+// WriteMetricsJson saves the metrics to the file specified by --metrics-out, if any
+func WriteMetricsJson(x Kontext, c comm.Console, metrics any) {
+	metricsOut := x.Args.MetricsOut
+	if len(metricsOut) == 0 {
+		return
+	}
+
+	comm.WriteFileTextP(x.Fs, metricsOut, comm.ToJsonP(metrics, true))
+	c.NewLine().Default("metrics saved to ").Yellow(metricsOut)
+}
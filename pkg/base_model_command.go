@@ -1,7 +1,7 @@
 package batchai
 
 import (
-	"sync"
+	"github.com/qiangyt/batchai/comm"
 )
 
 type BaseModelCommandT struct {
@@ -9,6 +9,7 @@ type BaseModelCommandT struct {
 	listCommand     ListCommand
 	codeFileManager CodeFileManager
 	symbolManager   SymbolManager
+	workerPool      comm.WorkerPool
 }
 
 type BaseModelCommand = *BaseModelCommandT
@@ -19,6 +20,7 @@ func NewBaseModelCommand(x Kontext) BaseModelCommand {
 		listCommand:     NewListCommand(),
 		codeFileManager: NewCodeFileManager(),
 		symbolManager:   NewSymbolManager(),
+		workerPool:      newWorkerPool(x.Args),
 	}
 }
 
@@ -26,14 +28,28 @@ func NewBaseModelCommand(x Kontext) BaseModelCommand {
 func (me BaseModelCommand) launchSymbolAgents(x Kontext, repoFiles []string) {
 	me.symbolManager.LoadAll(x, repoFiles)
 
-	wg := &sync.WaitGroup{}
 	resultChan := make(chan []Symbol, len(repoFiles))
 
 	for _, f := range repoFiles {
-		agent := NewSymbolAgent(me.symbolManager, me.codeFileManager, me.modelService, f)
-		agent.Run(x, resultChan, wg)
+		// the cheapest extractor available, or the model if none
+		extractor := me.symbolManager.Extractor(f)
+		agent := NewSymbolAgent(me.symbolManager, me.modelService, extractor, f)
+		agent.Run(x, resultChan, me.workerPool)
 	}
 
-	wg.Wait()
+	me.workerPool.Wait()
 	close(resultChan)
+
+	me.symbolManager.Flush(x)
+}
+
+// newWorkerPool builds the pool shared by all kinds of agents, bounded by --workers
+func newWorkerPool(args AppArgs) comm.WorkerPool {
+	workers := 1
+	ordered := false
+	if args != nil {
+		workers = args.Workers
+		ordered = args.OrderedOutput
+	}
+	return comm.NewWorkerPool(workers, ordered)
 }
@@ -33,6 +33,10 @@ func (me ChatMemory) AddUserMessage(content string) ChatMemory {
 	return me
 }
 
+func (me ChatMemory) Messages() []ChatMessage {
+	return me.msgs
+}
+
 func (me ChatMemory) ToChatCompletionMessageParamUnion() []openai.ChatCompletionMessageParamUnion {
 	r := make([]openai.ChatCompletionMessageParamUnion, len(me.msgs))
 	for i, msg := range me.msgs {
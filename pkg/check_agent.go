@@ -1,16 +1,21 @@
 package batchai
 
 import (
+	"fmt"
+	"io"
 	"strings"
-	"sync"
 
+	"github.com/pkg/errors"
 	"github.com/qiangyt/batchai/comm"
 )
 
 type CheckResultT struct {
-	Report  CheckReport
-	Skipped bool
-	Failed  bool
+	Report CheckReport
+	// the report of only the new issues, per the baseline
+	Reported   CheckReport
+	Suppressed int
+	Skipped    bool
+	Failed     bool
 }
 
 type CheckResult = *CheckResultT
@@ -42,13 +47,13 @@ func NewCheckAgent(reportManager CheckReportManager,
 	}
 }
 
-func (me CheckAgent) run(x Kontext, checkArgs CheckArgs, resultChan chan<- CheckResult) {
-	c := comm.NewConsole(!x.Args.Concurrent)
+func (me CheckAgent) run(x Kontext, checkArgs CheckArgs, resultChan chan<- CheckResult, output func(text string)) {
+	c := comm.NewConsole(!x.Args.Concurrent).WithOutput(output)
 	me.relativeFile = me.file[len(x.Args.Repository)+1:]
 
-	c.Greenf("\n\n▹▹▹▹▹ processing: %s\n", me.relativeFile)
 	c.Begin()
 	defer c.End()
+	c.Greenf("\n\n▹▹▹▹▹ processing: %s\n", me.relativeFile)
 
 	defer func() {
 		if e := recover(); e != nil {
@@ -62,19 +67,15 @@ func (me CheckAgent) run(x Kontext, checkArgs CheckArgs, resultChan chan<- Check
 	resultChan <- result
 }
 
-func (me CheckAgent) Run(x Kontext, checkArgs CheckArgs, resultChan chan<- CheckResult, wg *sync.WaitGroup) {
+func (me CheckAgent) Run(x Kontext, checkArgs CheckArgs, resultChan chan<- CheckResult, pool comm.WorkerPool) {
 	if !x.Args.Concurrent {
-		me.run(x, checkArgs, resultChan)
+		me.run(x, checkArgs, resultChan, nil)
 		return
 	}
 
-	wg.Add(1)
-
-	go func() {
-		defer wg.Done()
-
-		me.run(x, checkArgs, resultChan)
-	}()
+	pool.Submit(func(output func(text string)) {
+		me.run(x, checkArgs, resultChan, output)
+	})
 }
 
 func (me CheckAgent) checkFile(x Kontext, checkArgs CheckArgs, c comm.Console) CheckResult {
@@ -83,33 +84,102 @@ func (me CheckAgent) checkFile(x Kontext, checkArgs CheckArgs, c comm.Console) C
 
 	newCode := comm.ReadFileCodeP(x.Fs, me.file)
 
+	diff := ""
+	if checkArgs.WithDiff {
+		diff = me.gitDiff(x, newCode)
+	}
+
 	lastReport := me.reportManager.LoadReport(x, me.file)
 	if lastReport != nil {
-		noCodeChanges := (newCode == lastReport.OriginalCode)
+		// the report checked with a different diff, e.g. per another --since, is not reused
+		noCodeChanges := (newCode == lastReport.OriginalCode) && (diff == lastReport.Diff)
 		if noCodeChanges {
 			if !x.Args.Force {
-				if !checkArgs.Fix || (newCode == lastReport.FixedCode) {
+				if checkArgs.Fix != FIX_MODE_WRITE || (newCode == lastReport.FixedCode) {
 					c.NewLine().Default("✔ no code changes since last execution, skipped")
-					return &CheckResultT{Report: lastReport, Skipped: true}
+					reported, suppressed := checkArgs.Baseline.Filter(x.Args.Repository, lastReport)
+					return &CheckResultT{Report: lastReport, Reported: reported, Suppressed: suppressed, Skipped: true}
 				}
 			}
 		}
 	}
 
-	newReport := me.checkCode(x, c, newCode)
-	newReport.Print(c)
+	newReport := me.checkCode(x, c, newCode, diff)
+
+	reported, suppressed := checkArgs.Baseline.Filter(x.Args.Repository, newReport)
+	reported.Print(c)
+	if suppressed > 0 {
+		c.NewLine().Gray("suppressed by baseline: ").Defaultf("%d known issues", suppressed)
+	}
 
 	if newReport.HasIssue {
-		if checkArgs.Fix {
-			// replace the original code file with checked code
-			comm.WriteFileTextP(x.Fs, me.file, newReport.FixedCode)
+		if checkArgs.Fix == FIX_MODE_WRITE {
+			if checkArgs.Verifier != nil {
+				me.applyVerifiedFix(x, c, checkArgs.Verifier, newReport)
+			} else {
+				// replace the original code file with checked code
+				comm.WriteFileTextP(x.Fs, me.file, newReport.FixedCode)
+			}
 		}
 	}
 
 	reportFile := me.reportManager.SaveReport(x, me.file, newReport)
 	c.NewLine().Blue("✔ report: ").Default(reportFile[len(x.Args.Repository)+1:])
 
-	return &CheckResultT{Report: newReport, Skipped: false}
+	return &CheckResultT{Report: newReport, Reported: reported, Suppressed: suppressed, Skipped: false}
+}
+
+// applyVerifiedFix writes the fixed code only if it passes the verify command. If it fails, the output of
+// verify command is fed back to the model to repair the fix, for a bounded number of attempts.
+func (me CheckAgent) applyVerifiedFix(x Kontext, c comm.Console, verifier CheckVerifier, report CheckReport) {
+	ok, output := verifier.Apply(x, me.file, report.FixedCode)
+	if ok {
+		report.FixStatus = FIX_STATUS_VERIFIED
+		c.NewLine().Green("✔ fix verified by: ").Default(verifier.Command)
+		return
+	}
+
+	for attempt := 1; attempt <= verifier.MaxRepairAttempts; attempt++ {
+		c.NewLine().Yellowf("fix failed to verify, repair attempt %d/%d", attempt, verifier.MaxRepairAttempts)
+		report.RepairAttempts = attempt
+
+		fixedCode, metrics := me.repairFix(x, c, verifier.Command, output)
+		report.ModelUsageMetrics.IncreaseUsage(metrics)
+		if len(fixedCode) == 0 || fixedCode == report.OriginalCode {
+			c.NewLine().Yellow("no repaired code answered")
+			break
+		}
+
+		report.FixedCode = fixedCode
+		if ok, output = verifier.Apply(x, me.file, fixedCode); ok {
+			report.FixStatus = FIX_STATUS_REPAIRED
+			c.NewLine().Green("✔ fix repaired and verified by: ").Default(verifier.Command)
+			return
+		}
+	}
+
+	// the fixed code is not a valid fix any more, so neither cached nor exported as a patch
+	report.RejectedFixedCode = report.FixedCode
+	report.FixedCode = report.OriginalCode
+	report.FixStatus = FIX_STATUS_ROLLED_BACK
+	report.VerifyOutput = output
+	c.NewLine().Red("fix rolled back, failed to verify by: ").Default(verifier.Command)
+	c.NewLine().Default(output)
+}
+
+func (me CheckAgent) repairFix(x Kontext, c comm.Console, verifyCommand string, verifyOutput string) (string, ModelUsageMetrics) {
+	mem := me.memory
+	mem.AddUserMessage(fmt.Sprintf("The fixed file failed the verify command `%s`, with below output:\n```\n%s\n```\n"+
+		"Repair the fixed file so that it passes the verify command, and output the complete repaired file as a separate segment starting with %s and ending with %s.",
+		verifyCommand, verifyOutput, FIX_BEGIN, FIX_END))
+	if x.Args.Verbose {
+		c.NewLine().Gray("chat: ").Default("repair the fix")
+	}
+
+	answer, metrics := me.modelService.Chat(x, c, x.Config.Check.ModelId, true, mem, func() io.Writer { return NewFixCodeWriter(c) })
+
+	fixedCode, _ := ExtractFixedCode(answer)
+	return comm.NormalizeCode(fixedCode), metrics
 }
 
 type FixCodeWriterT struct {
@@ -145,16 +215,43 @@ func (me FixCodeWriter) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
-func (me CheckAgent) checkCode(x Kontext, c comm.Console, code string) CheckReport {
+// gitDiff returns the diff hunks of the file per --since, --staged or --changed.
+// The untracked file is taken as all lines added.
+func (me CheckAgent) gitDiff(x Kontext, code string) string {
+	diff, err := comm.GetGitDiff(x.Fs, x.Args.Repository, me.file, x.Args.GitDiffArgs()...)
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to get the diff of %s", me.relativeFile))
+	}
+	if len(diff) == 0 && !comm.IsGitTracked(x.Fs, x.Args.Repository, me.file) {
+		diff = comm.AddedFileGitDiff(me.relativeFile, code)
+	}
+	return diff
+}
+
+func (me CheckAgent) checkCode(x Kontext, c comm.Console, code string, diff string) CheckReport {
 	verbose := x.Args.Verbose
 
-	sysPrompt := x.Config.Check.RenderPrompt(code, me.relativeFile)
+	suppression := ParseCheckSuppression(code)
+	if suppression.IgnoreFile {
+		c.NewLine().Default("✔ ignored by " + CHECK_IGNORE_FILE_MARKER)
+		return &CheckReportT{
+			Issues:            []CheckIssue{},
+			FixedCode:         code,
+			OriginalCode:      code,
+			Path:              me.relativeFile,
+			IgnoredByMarker:   true,
+			ModelUsageMetrics: NewModelUsageMetrics(),
+		}
+	}
+
+	sysPrompt := x.Config.Check.RenderPrompt(code, me.relativeFile, diff)
+	if x.Args.EnableSymbolReference {
+		sysPrompt = me.provideSymbols(x, c, x.Config.Check.ModelId, me.file, code, sysPrompt)
+	}
 	mem := me.memory
 	mem.AddSystemMessage(sysPrompt)
 
 	if x.Args.EnableSymbolReference {
-		// TODO: merge metrics
-		me.provideSymbols(x, c, me.file)
 		mem.AddUserMessage("check the code, with provided symbols as references")
 	} else {
 		mem.AddUserMessage("check the code")
@@ -163,7 +260,7 @@ func (me CheckAgent) checkCode(x Kontext, c comm.Console, code string) CheckRepo
 		c.NewLine().Gray("chat: ").Default("check the code")
 	}
 
-	answer, metrics := me.modelService.Chat(x, c, x.Config.Check.ModelId, true, mem, NewFixCodeWriter(c))
+	answer, metrics := me.modelService.Chat(x, c, x.Config.Check.ModelId, true, mem, func() io.Writer { return NewFixCodeWriter(c) })
 	if verbose {
 		c.NewLine().Gray("answer: ").Default(mem.Format())
 	}
@@ -175,8 +272,14 @@ func (me CheckAgent) checkCode(x Kontext, c comm.Console, code string) CheckRepo
 	r.ModelUsageMetrics = metrics
 	r.FixedCode = fixedCode
 	r.OriginalCode = code
+	r.Diff = diff
 	r.Path = me.relativeFile
 
+	r.Issues, r.SuppressedByMarker = suppression.Filter(r.Issues)
+	if r.SuppressedByMarker > 0 && len(r.Issues) == 0 {
+		r.HasIssue = false
+	}
+
 	if !r.HasIssue {
 		r.Issues = []CheckIssue{}
 		r.FixedCode = code
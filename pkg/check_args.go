@@ -1,23 +1,153 @@
 package batchai
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"strings"
 
-	"github.com/pkg/errors"
 	"github.com/qiangyt/batchai/comm"
 	"github.com/urfave/cli/v2"
 )
 
+const (
+	CHECK_FORMAT_CONSOLE = "console"
+	CHECK_FORMAT_SARIF   = "sarif"
+)
+
+type FixMode string
+
+const (
+	FIX_MODE_NONE  FixMode = ""
+	FIX_MODE_WRITE FixMode = "write"
+	FIX_MODE_PATCH FixMode = "patch"
+)
+
+// FixModeValueT is the value of the --fix flag, which works as a boolean flag (--fix, writes the fixed files),
+// or takes the fix mode (--fix=write, or --fix=patch)
+type FixModeValueT struct {
+	mode FixMode
+}
+
+type FixModeValue = *FixModeValueT
+
+func (me FixModeValue) Set(value string) error {
+	switch strings.ToLower(value) {
+	case "", "false":
+		me.mode = FIX_MODE_NONE
+	case "true", string(FIX_MODE_WRITE):
+		me.mode = FIX_MODE_WRITE
+	case string(FIX_MODE_PATCH):
+		me.mode = FIX_MODE_PATCH
+	default:
+		return fmt.Errorf("unsupported fix mode: %s, must be either %s or %s", value, FIX_MODE_WRITE, FIX_MODE_PATCH)
+	}
+	return nil
+}
+
+func (me FixModeValue) String() string {
+	if me == nil {
+		return ""
+	}
+	return string(me.mode)
+}
+
+func (me FixModeValue) IsBoolFlag() bool {
+	return true
+}
+
 type CheckArgsT struct {
-	Fix bool
+	Fix       FixMode
+	PatchFile string
+	Format    string
+	Output    string
+
+	// exits with EXIT_CODE_ISSUES if any issue is at or above this severity
+	FailOn string
+
+	// passes the diff hunks per --since, --staged or --changed to the model
+	WithDiff bool
+
+	// verifies the fixes before accepting them, nil if no verify command
+	Verifier CheckVerifier
+
+	// known issues to suppress, loaded from the --baseline file
+	Baseline          CheckBaseline
+	BaselineFile      string
+	WriteBaselineFile string
 }
 
 type CheckArgs = *CheckArgsT
 
 func (me CheckArgs) WithCliContext(x Kontext, cliContext *cli.Context) error {
-	me.Fix = cliContext.Bool("fix")
+	me.Fix = FIX_MODE_NONE
+	if v, ok := cliContext.Generic("fix").(FixModeValue); ok && v != nil {
+		me.Fix = v.mode
+	}
+
+	if me.Fix == FIX_MODE_PATCH {
+		patchFile := cliContext.String("patch-file")
+		if len(patchFile) == 0 {
+			patchFile = path.Join(x.Config.CacheDir, path.Base(x.Args.Repository)+".fix.patch")
+		}
+		me.PatchFile = comm.AbsPathWithP(patchFile, comm.WorkingDirectoryP())
+	}
+
+	me.Format = cliContext.String("format")
+	switch me.Format {
+	case "", CHECK_FORMAT_CONSOLE:
+		me.Format = CHECK_FORMAT_CONSOLE
+	case CHECK_FORMAT_SARIF:
+		output := cliContext.String("output")
+		if len(output) == 0 {
+			return fmt.Errorf("please specifies the output file via --output for --format %s", me.Format)
+		}
+		me.Output = comm.AbsPathWithP(output, comm.WorkingDirectoryP())
+	default:
+		return fmt.Errorf("unsupported format: %s, must be either %s or %s", me.Format, CHECK_FORMAT_CONSOLE, CHECK_FORMAT_SARIF)
+	}
+
+	if baselineFile := cliContext.String("baseline"); len(baselineFile) > 0 {
+		me.BaselineFile = comm.AbsPathWithP(baselineFile, comm.WorkingDirectoryP())
+
+		baseline, err := LoadCheckBaseline(x, me.BaselineFile)
+		if err != nil {
+			return err
+		}
+		me.Baseline = baseline
+	}
+
+	if writeBaselineFile := cliContext.String("write-baseline"); len(writeBaselineFile) > 0 {
+		me.WriteBaselineFile = comm.AbsPathWithP(writeBaselineFile, comm.WorkingDirectoryP())
+	}
+
+	verifyCommand := cliContext.String("verify")
+	if len(verifyCommand) > 0 {
+		if me.Fix != FIX_MODE_WRITE {
+			return errors.New("--verify requires --fix")
+		}
+	} else if me.Fix == FIX_MODE_WRITE {
+		verifyCommand = x.Config.Check.VerifyCommand
+	}
+	me.Verifier = NewCheckVerifier(verifyCommand, x.Config.Check.MaxRepairAttempts)
+
+	me.WithDiff = cliContext.Bool("with-diff")
+	if me.WithDiff && !x.Args.HasGitDiffFilter() {
+		return errors.New("--with-diff requires either --since, --staged or --changed")
+	}
+
+	me.FailOn = strings.ToLower(cliContext.String("fail-on"))
+	if len(me.FailOn) > 0 {
+		if CheckSeverityLevel(me.FailOn) < 0 {
+			return fmt.Errorf("unsupported --fail-on: %s, must be either %s, %s, %s or %s", me.FailOn,
+				CHECK_SEVERITY_TRIVIAL, CHECK_SEVERITY_MINOR, CHECK_SEVERITY_MAJOR, CHECK_SEVERITY_CRITICAL)
+		}
+	} else if me.Baseline != nil {
+		// any new issue fails, by default
+		me.FailOn = CHECK_SEVERITY_TRIVIAL
+	}
+
 	return nil
 }
 
@@ -26,7 +156,16 @@ func CheckUrfaveCommand(x Kontext) *cli.Command {
 		Name:  "check",
 		Usage: fmt.Sprintf("Scans project codes to check issues. Report is outputed to console and also saved to '%s'", os.Getenv("BATCHAI_CACHE_DIR")),
 		Flags: []cli.Flag{
-			&cli.BoolFlag{Name: "fix", Aliases: []string{"f"}, DefaultText: "false", Usage: "Replaces the target files"},
+			&cli.GenericFlag{Name: "fix", Aliases: []string{"f"}, Value: &FixModeValueT{}, DefaultText: "false",
+				Usage: "Replaces the target files (--fix or --fix=write), or writes a single unified patch of all fixes and leaves the target files untouched (--fix=patch)"},
+			&cli.StringFlag{Name: "patch-file", Usage: "Patch file for --fix=patch, defaults to <cache dir>/<repository name>.fix.patch"},
+			&cli.StringFlag{Name: "format", Value: CHECK_FORMAT_CONSOLE, Usage: "Report format, either 'console' or 'sarif'. The sarif format merges all reports of this run into the --output file"},
+			&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "Output file of the report, e.g. results.sarif"},
+			&cli.StringFlag{Name: "baseline", Usage: "Baseline file of the known issues, e.g. .batchai-baseline.json. Only the new issues are reported, and fail the command"},
+			&cli.StringFlag{Name: "write-baseline", Usage: "Writes all issues of this run, including the cached ones, to the specified baseline file"},
+			&cli.StringFlag{Name: "verify", Usage: "With --fix, runs the command after each fix, e.g. \"go build ./...\". The fix is reverted if the command fails, and the model is asked to repair it. Overrides the check.verify_command config"},
+			&cli.BoolFlag{Name: "with-diff", DefaultText: "false", Usage: "With --since, --staged or --changed, passes the diff hunks to the model to focus on the changed lines"},
+			&cli.StringFlag{Name: "fail-on", Usage: fmt.Sprintf("Exits with code %d if any issue is at or above the severity, either minor, major or critical. Exits with code %d if any file failed to check", EXIT_CODE_ISSUES, EXIT_CODE_FAILURES)},
 		},
 		Action: CheckFunc(x),
 	}
@@ -47,17 +186,30 @@ func CheckFunc(x Kontext) func(*cli.Context) error {
 			return err
 		}
 
-		if ca.Fix {
-			unstagedFiles, err := comm.GetUnstagedFiles(x.Fs, a.Repository)
-			if err != nil {
-				return errors.Wrap(err, "failed to check unstaged files")
+		if ca.Fix == FIX_MODE_WRITE {
+			if err := a.EnsureNoUnstagedFiles(x, "fix"); err != nil {
+				return err
 			}
-			if len(unstagedFiles) > 0 {
-				return fmt.Errorf("please stage your local changes before fix.\nunstaged files: \n%s", strings.Join(unstagedFiles, "\n"))
+		}
+
+		if ca.Verifier != nil {
+			// the fixes would be always rolled back if the repository fails the verification already
+			if ok, output := ca.Verifier.Verify(x); !ok {
+				return fmt.Errorf("verify command '%s' fails before any fix:\n%s", ca.Verifier.Command, output)
 			}
 		}
 
-		NewCheckCommand(x).Check(x, ca)
+		metrics := NewCheckCommand(x).Check(x, ca)
+
+		if metrics.Failed > 0 {
+			return cli.Exit(fmt.Sprintf("failed to check %d files", metrics.Failed), EXIT_CODE_FAILURES)
+		}
+		if metrics.FailingIssues > 0 {
+			if ca.Baseline != nil {
+				return cli.Exit(fmt.Sprintf("%d new issues at or above '%s' not in baseline %s", metrics.FailingIssues, ca.FailOn, ca.BaselineFile), EXIT_CODE_ISSUES)
+			}
+			return cli.Exit(fmt.Sprintf("%d issues at or above '%s'", metrics.FailingIssues, ca.FailOn), EXIT_CODE_ISSUES)
+		}
 
 		return nil
 	}
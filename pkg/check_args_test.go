@@ -0,0 +1,109 @@
+package batchai
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func parseCheckArgs(t *testing.T, args ...string) (CheckArgs, error) {
+	return parseCheckArgsWith(t, &AppArgsT{Repository: "/work/repo"}, args...)
+}
+
+func parseCheckArgsWith(t *testing.T, appArgs AppArgs, args ...string) (CheckArgs, error) {
+	x := NewKontext(afero.NewMemMapFs())
+	x.Config = &AppConfigT{CacheDir: "/cache", Check: &CheckConfigT{VerifyCommand: "make", MaxRepairAttempts: 2}}
+	x.Args = appArgs
+
+	ca := &CheckArgsT{}
+	var err error
+
+	command := CheckUrfaveCommand(x)
+	command.Action = func(cliContext *cli.Context) error {
+		err = ca.WithCliContext(x, cliContext)
+		return nil
+	}
+
+	app := &cli.App{Commands: []*cli.Command{command}}
+	require.NoError(t, app.Run(append([]string{"batchai", "check"}, args...)))
+
+	return ca, err
+}
+
+func TestCheckArgsFixMode(t *testing.T) {
+	a := require.New(t)
+
+	ca, err := parseCheckArgs(t, ".")
+	a.NoError(err)
+	a.Equal(FIX_MODE_NONE, ca.Fix)
+
+	ca, err = parseCheckArgs(t, "--fix", ".")
+	a.NoError(err)
+	a.Equal(FIX_MODE_WRITE, ca.Fix)
+
+	ca, err = parseCheckArgs(t, "--fix=write", ".")
+	a.NoError(err)
+	a.Equal(FIX_MODE_WRITE, ca.Fix)
+
+	ca, err = parseCheckArgs(t, "--fix=patch", ".")
+	a.NoError(err)
+	a.Equal(FIX_MODE_PATCH, ca.Fix)
+	a.Equal("/cache/repo.fix.patch", ca.PatchFile)
+
+	ca, err = parseCheckArgs(t, "--fix=patch", "--patch-file", "/tmp/fix.patch", ".")
+	a.NoError(err)
+	a.Equal("/tmp/fix.patch", ca.PatchFile)
+}
+
+func TestCheckArgsFailOn(t *testing.T) {
+	a := require.New(t)
+
+	ca, err := parseCheckArgs(t, ".")
+	a.NoError(err)
+	a.Empty(ca.FailOn)
+
+	ca, err = parseCheckArgs(t, "--fail-on", "Major", ".")
+	a.NoError(err)
+	a.Equal(CHECK_SEVERITY_MAJOR, ca.FailOn)
+
+	_, err = parseCheckArgs(t, "--fail-on", "blocker", ".")
+	a.Error(err)
+}
+
+func TestCheckArgsVerify(t *testing.T) {
+	a := require.New(t)
+
+	ca, err := parseCheckArgs(t, ".")
+	a.NoError(err)
+	a.Nil(ca.Verifier)
+
+	// the verify command of config applies to --fix
+	ca, err = parseCheckArgs(t, "--fix", ".")
+	a.NoError(err)
+	a.Equal("make", ca.Verifier.Command)
+	a.Equal(2, ca.Verifier.MaxRepairAttempts)
+
+	ca, err = parseCheckArgs(t, "--fix", "--verify", "go build ./...", ".")
+	a.NoError(err)
+	a.Equal("go build ./...", ca.Verifier.Command)
+
+	_, err = parseCheckArgs(t, "--verify", "go build ./...", ".")
+	a.Error(err)
+}
+
+func TestCheckArgsWithDiff(t *testing.T) {
+	a := require.New(t)
+
+	ca, err := parseCheckArgs(t, ".")
+	a.NoError(err)
+	a.False(ca.WithDiff)
+
+	_, err = parseCheckArgs(t, "--with-diff", ".")
+	a.Error(err)
+
+	ca, err = parseCheckArgsWith(t, &AppArgsT{Repository: "/work/repo", Since: "origin/main"}, "--with-diff", ".")
+	a.NoError(err)
+	a.True(ca.WithDiff)
+}
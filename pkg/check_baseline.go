@@ -0,0 +1,120 @@
+package batchai
+
+import (
+	"path"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/qiangyt/batchai/comm"
+)
+
+const CHECK_BASELINE_VERSION = 1
+
+type CheckBaselineIssueT struct {
+	Fingerprint      string `json:"fingerprint"`
+	Path             string `json:"path"`
+	ShortDescription string `json:"short_description"`
+	Severity         string `json:"severity"`
+}
+
+type CheckBaselineIssue = *CheckBaselineIssueT
+
+// CheckBaselineT records the already-known issues, which are suppressed by --baseline
+type CheckBaselineT struct {
+	Version int                  `json:"version"`
+	Issues  []CheckBaselineIssue `json:"issues"`
+
+	fingerprints map[string]bool
+}
+
+type CheckBaseline = *CheckBaselineT
+
+// NewCheckBaseline collects the issues of the reports
+func NewCheckBaseline(repository string, reports []CheckReport) CheckBaseline {
+	r := &CheckBaselineT{
+		Version: CHECK_BASELINE_VERSION,
+		Issues:  []CheckBaselineIssue{},
+	}
+
+	known := map[string]bool{}
+	for _, report := range reports {
+		p := RelativeRepositoryPath(repository, report.Path)
+
+		for _, issue := range report.Issues {
+			fingerprint := issue.Fingerprint(p, report.OriginalCode)
+			if known[fingerprint] {
+				continue
+			}
+			known[fingerprint] = true
+
+			r.Issues = append(r.Issues, &CheckBaselineIssueT{
+				Fingerprint:      fingerprint,
+				Path:             p,
+				ShortDescription: issue.ShortDescription,
+				Severity:         issue.Severity,
+			})
+		}
+	}
+
+	// stable output, to minimize the diff when the baseline file is committed
+	sort.Slice(r.Issues, func(i, j int) bool {
+		if r.Issues[i].Path != r.Issues[j].Path {
+			return r.Issues[i].Path < r.Issues[j].Path
+		}
+		return r.Issues[i].Fingerprint < r.Issues[j].Fingerprint
+	})
+
+	r.fingerprints = known
+	return r
+}
+
+func LoadCheckBaseline(x Kontext, file string) (CheckBaseline, error) {
+	r := &CheckBaselineT{}
+	if err := comm.FromJsonFile(x.Fs, file, false, r); err != nil {
+		return nil, errors.Wrapf(err, "failed to load baseline file: %s", file)
+	}
+	if r.Version != CHECK_BASELINE_VERSION {
+		return nil, errors.Errorf("unsupported version %d of baseline file: %s", r.Version, file)
+	}
+
+	r.fingerprints = map[string]bool{}
+	for _, issue := range r.Issues {
+		r.fingerprints[issue.Fingerprint] = true
+	}
+	return r, nil
+}
+
+func (me CheckBaseline) Write(x Kontext, file string) {
+	comm.MkdirP(x.Fs, path.Dir(file))
+	comm.WriteFileTextP(x.Fs, file, comm.ToJsonP(me, true))
+}
+
+// Contains tells if the issue is already known
+func (me CheckBaseline) Contains(repository string, report CheckReport, issue CheckIssue) bool {
+	return me.fingerprints[issue.Fingerprint(RelativeRepositoryPath(repository, report.Path), report.OriginalCode)]
+}
+
+// Filter returns a copy of the report that has only the new issues, and the amount of suppressed issues.
+// The report itself is untouched, so that the cache keeps all issues.
+func (me CheckBaseline) Filter(repository string, report CheckReport) (CheckReport, int) {
+	if me == nil || report == nil || len(report.Issues) == 0 {
+		return report, 0
+	}
+
+	newIssues := []CheckIssue{}
+	for _, issue := range report.Issues {
+		if !me.Contains(repository, report, issue) {
+			newIssues = append(newIssues, issue)
+		}
+	}
+
+	suppressed := len(report.Issues) - len(newIssues)
+	if suppressed == 0 {
+		return report, 0
+	}
+
+	r := *report
+	r.Issues = newIssues
+	r.HasIssue = len(newIssues) > 0
+	return &r, suppressed
+}
@@ -0,0 +1,77 @@
+package batchai
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const testBaselineCode = `package main
+
+func main() {
+	x := 1
+	println(x / 0)
+}
+`
+
+func TestCheckIssueFingerprint(t *testing.T) {
+	a := require.New(t)
+
+	issue := &CheckIssueT{ShortDescription: "Division by zero.", IssueLineBegin: 5, IssueLineEnd: 5}
+	fingerprint := issue.Fingerprint("main.go", testBaselineCode)
+
+	// shifted lines, re-indented code and rephrased punctuations don't matter
+	shifted := &CheckIssueT{ShortDescription: "division  by zero", IssueLineBegin: 7, IssueLineEnd: 7}
+	a.Equal(fingerprint, shifted.Fingerprint("main.go", "// header\n\n"+testBaselineCode))
+	a.Equal(fingerprint, issue.Fingerprint("main.go", "package main\n\nfunc main() {\n    x := 1\n    println(x / 0)\n}\n"))
+
+	a.NotEqual(fingerprint, issue.Fingerprint("other.go", testBaselineCode))
+	a.NotEqual(fingerprint, issue.Fingerprint("main.go", "package main\n\nfunc main() {\n\tx := 2\n\tprintln(x / 0)\n}\n"))
+	a.NotEqual(fingerprint, (&CheckIssueT{ShortDescription: "Unused variable", IssueLineBegin: 5, IssueLineEnd: 5}).Fingerprint("main.go", testBaselineCode))
+
+	// out of range lines are tolerated
+	a.NotEmpty((&CheckIssueT{ShortDescription: "x", IssueLineBegin: 100, IssueLineEnd: 90}).Fingerprint("main.go", testBaselineCode))
+	a.NotEmpty((&CheckIssueT{ShortDescription: "x"}).Fingerprint("main.go", ""))
+}
+
+func TestCheckBaseline(t *testing.T) {
+	a := require.New(t)
+
+	known := &CheckIssueT{ShortDescription: "Division by zero", IssueLineBegin: 5, IssueLineEnd: 5, Severity: "critical"}
+	report := &CheckReportT{HasIssue: true, Path: "/repo/main.go", OriginalCode: testBaselineCode, Issues: []CheckIssue{known}}
+
+	baseline := NewCheckBaseline("/repo", []CheckReport{report, {Path: "b.go"}})
+	a.Len(baseline.Issues, 1)
+	a.Equal("main.go", baseline.Issues[0].Path)
+
+	x := NewKontext(afero.NewMemMapFs())
+	baseline.Write(x, "/repo/.batchai-baseline.json")
+	baseline, err := LoadCheckBaseline(x, "/repo/.batchai-baseline.json")
+	a.NoError(err)
+
+	// the known issue is suppressed, even though the line shifts
+	fresh := &CheckIssueT{ShortDescription: "Unused result", IssueLineBegin: 4, IssueLineEnd: 4, Severity: "minor"}
+	shifted := &CheckIssueT{ShortDescription: "division by zero", IssueLineBegin: 6, IssueLineEnd: 6, Severity: "critical"}
+	next := &CheckReportT{HasIssue: true, Path: "main.go", OriginalCode: "\n" + testBaselineCode, Issues: []CheckIssue{shifted, fresh}}
+
+	reported, suppressed := baseline.Filter("/repo", next)
+	a.Equal(1, suppressed)
+	a.True(reported.HasIssue)
+	a.Equal([]CheckIssue{fresh}, reported.Issues)
+	a.Len(next.Issues, 2)
+
+	reported, suppressed = baseline.Filter("/repo", &CheckReportT{HasIssue: true, Path: "main.go", OriginalCode: testBaselineCode, Issues: []CheckIssue{known}})
+	a.Equal(1, suppressed)
+	a.False(reported.HasIssue)
+	a.Empty(reported.Issues)
+
+	// nil baseline suppresses nothing
+	var none CheckBaseline
+	reported, suppressed = none.Filter("/repo", next)
+	a.Equal(0, suppressed)
+	a.Same(next, reported)
+
+	_, err = LoadCheckBaseline(x, "/repo/missing.json")
+	a.Error(err)
+}
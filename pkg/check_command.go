@@ -1,7 +1,7 @@
 package batchai
 
 import (
-	"sync"
+	"path"
 
 	"github.com/qiangyt/batchai/comm"
 )
@@ -20,54 +20,74 @@ func NewCheckCommand(x Kontext) CheckCommand {
 	}
 }
 
-func (me CheckCommand) launchCheckAgents(x Kontext, checkArgs CheckArgs, targetFiles []string, metrics CheckMetrics) {
+// launchCheckAgents returns the reports of all issues, and the reports of only the new issues per the baseline
+func (me CheckCommand) launchCheckAgents(x Kontext, checkArgs CheckArgs, targetFiles []string, metrics CheckMetrics) ([]CheckReport, []CheckReport) {
 	// launch check agents and wait for them
-	wg := &sync.WaitGroup{}
 	resultChan := make(chan CheckResult, len(targetFiles))
 
 	for _, f := range targetFiles {
 		metrics.Processed++
 
 		agent := NewCheckAgent(me.reportManager, me.symbolManager, me.modelService, f)
-		agent.Run(x, checkArgs, resultChan, wg)
+		agent.Run(x, checkArgs, resultChan, me.workerPool)
 	}
 
-	wg.Wait()
+	me.workerPool.Wait()
 	close(resultChan)
 
+	reports := []CheckReport{}
+	reportedReports := []CheckReport{}
 	for r := range resultChan {
-		if r.Failed {
-			metrics.Failed++
-		} else if r.Skipped {
-			metrics.Skipped++
-		} else {
-			metrics.Succeeded++
-
-			report := r.Report
-			metrics.ModelUsageMetricsT.IncreaseUsage(report.ModelUsageMetrics)
-
-			if report.HasIssue {
-				metrics.HasIssue++
-				metrics.TotalIssue += len(report.Issues)
-			}
+		if r.Report != nil {
+			reports = append(reports, r.Report)
+			reportedReports = append(reportedReports, r.Reported)
 		}
+		metrics.AddResult(checkArgs, r)
 	}
+
+	return reports, reportedReports
 }
 
-func (me CheckCommand) Check(x Kontext, checkArgs CheckArgs) {
+func (me CheckCommand) Check(x Kontext, checkArgs CheckArgs) CheckMetrics {
 	metrics := NewCheckMetrics()
 	c := comm.NewConsole(!x.Args.Concurrent)
 
 	c.NewLine().Default("test command uses model ").Yellowf("'%s'\n\n", x.Config.Check.ModelId)
 
-	targetFiles, _, _, repoFiles := me.listCommand.CollectWorkingFiles(x, c)
+	targetFiles, ignored, failed, repoFiles := me.listCommand.CollectWorkingFiles(x, c)
+	metrics.WithWorkingFiles(targetFiles, ignored, failed)
+
+	reports := []CheckReport{}
+	reportedReports := []CheckReport{}
 	if len(targetFiles) > 0 {
 		if x.Args.EnableSymbolReference {
 			me.launchSymbolAgents(x, repoFiles)
 		}
-		me.launchCheckAgents(x, checkArgs, targetFiles, metrics)
+		reports, reportedReports = me.launchCheckAgents(x, checkArgs, targetFiles, metrics)
 	}
 
-	// c.NewLine()
-	// metrics.Print(✔ c)
+	if checkArgs.Fix == FIX_MODE_PATCH {
+		patch, amount := BuildCheckPatch(x, c, reports)
+		comm.MkdirP(x.Fs, path.Dir(checkArgs.PatchFile))
+		comm.WriteFileTextP(x.Fs, checkArgs.PatchFile, patch)
+		c.NewLine().Defaultf("patch of %d files saved to ", amount).Yellow(checkArgs.PatchFile)
+		c.NewLine().Default("review and apply it by: ").Yellowf("git apply %s", checkArgs.PatchFile)
+	}
+
+	if checkArgs.Format == CHECK_FORMAT_SARIF {
+		NewSarifLog(x.Args.Repository, reportedReports).Write(x, checkArgs.Output)
+		c.NewLine().Default("SARIF report saved to ").Yellow(checkArgs.Output)
+	}
+
+	if len(checkArgs.WriteBaselineFile) > 0 {
+		baseline := NewCheckBaseline(x.Args.Repository, reports)
+		baseline.Write(x, checkArgs.WriteBaselineFile)
+		c.NewLine().Defaultf("baseline of %d issues saved to ", len(baseline.Issues)).Yellow(checkArgs.WriteBaselineFile)
+	}
+
+	c.NewLine()
+	metrics.Print(c)
+	WriteMetricsJson(x, c, metrics)
+
+	return metrics
 }
@@ -10,6 +10,9 @@ type CheckConfigT struct {
 	Severity  string      `mapstructure:"severity"`
 	Prompt    CheckPrompt `mapstructure:"prompt"`
 	Includes  []string    `mapstructure:"includes"`
+	// runs after each fix, e.g. `go build ./...`
+	VerifyCommand     string `mapstructure:"verify_command"`
+	MaxRepairAttempts int    `mapstructure:"max_repair_attempts"`
 }
 
 type CheckConfig = *CheckConfigT
@@ -29,11 +32,12 @@ func (me CheckConfig) Init(config AppConfig) {
 	}
 }
 
-func (me CheckConfig) RenderPrompt(codeToCheck string, codeFile string) string {
+func (me CheckConfig) RenderPrompt(codeToCheck string, codeFile string, diff string) string {
 	vars := NewCheckPromptVariables().
 		WithSeverity(me.Severity).
 		WithPath(codeFile).
 		WithLang(me.AppConfig.Lang).
-		WithCodeToCheck(codeToCheck)
+		WithCodeToCheck(codeToCheck).
+		WithDiff(diff)
 	return me.Prompt.Generate(vars)
 }
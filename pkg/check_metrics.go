@@ -1,21 +1,89 @@
 package batchai
 
 import (
+	"sort"
+
 	"github.com/qiangyt/batchai/comm"
 )
 
 type CheckMetricsT struct {
 	BaseMetricsT
 
-	HasIssue   int
-	TotalIssue int
+	// amount of files with issues, including the cached ones
+	HasIssue   int `json:"has_issue"`
+	TotalIssue int `json:"total_issue"`
+	// amount of issues by severity, including the cached ones
+	IssuesBySeverity map[string]int `json:"issues_by_severity"`
+	// with --baseline, amount of the issues not in the baseline, including the cached ones
+	NewIssues int `json:"new_issues,omitempty"`
+	// amount of known issues suppressed by the baseline
+	SuppressedByBaseline int `json:"suppressed_by_baseline,omitempty"`
+	// amount of issues suppressed by the batchai:ignore markers
+	SuppressedByMarker int `json:"suppressed_by_marker"`
+	// amount of files ignored by the batchai:ignore-file marker
+	IgnoredByMarker int `json:"ignored_by_marker"`
+	// amount of fixes per the verify command
+	Verified   int `json:"verified"`
+	Repaired   int `json:"repaired"`
+	RolledBack int `json:"rolled_back"`
+	// with --fail-on, amount of the issues at or above the threshold, including the cached ones
+	FailingIssues int `json:"failing_issues,omitempty"`
 }
 
 type CheckMetrics = *CheckMetricsT
 
 func NewCheckMetrics() CheckMetrics {
 	return &CheckMetricsT{
-		BaseMetricsT: *NewBaseMetrics(),
+		BaseMetricsT:     *NewBaseMetrics(),
+		IssuesBySeverity: map[string]int{},
+	}
+}
+
+// AddResult counts the result of a file. The issues of the cached reports are counted as well,
+// same as the exit code, SARIF and baseline.
+func (me CheckMetrics) AddResult(checkArgs CheckArgs, r CheckResult) {
+	if r.Report != nil {
+		if checkArgs.Baseline != nil {
+			me.SuppressedByBaseline += r.Suppressed
+			if r.Reported.HasIssue {
+				me.NewIssues += len(r.Reported.Issues)
+			}
+		}
+		if len(checkArgs.FailOn) > 0 {
+			me.FailingIssues += r.Reported.CountIssuesAtOrAbove(checkArgs.FailOn)
+		}
+
+		me.SuppressedByMarker += r.Report.SuppressedByMarker
+		if r.Report.IgnoredByMarker {
+			me.IgnoredByMarker++
+		}
+
+		if r.Reported.HasIssue {
+			me.HasIssue++
+			me.TotalIssue += len(r.Reported.Issues)
+			for _, issue := range r.Reported.Issues {
+				me.IssuesBySeverity[issue.Severity]++
+			}
+		}
+	}
+
+	if r.Failed {
+		me.Failed++
+	} else if r.Skipped {
+		me.Skipped++
+	} else {
+		me.Succeeded++
+
+		me.ModelUsageMetricsT.IncreaseUsage(r.Report.ModelUsageMetrics)
+
+		switch r.Report.FixStatus {
+		case FIX_STATUS_VERIFIED:
+			me.Verified++
+		case FIX_STATUS_REPAIRED:
+			me.Repaired++
+		case FIX_STATUS_ROLLED_BACK:
+			me.RolledBack++
+		}
 	}
 }
 
@@ -31,4 +99,33 @@ func (me CheckMetrics) Print(console comm.Console) {
 		me.TotalIssue,
 		me.Skipped,
 	)
+
+	if me.SuppressedByMarker > 0 || me.IgnoredByMarker > 0 {
+		console.NewLine().Greenf("Suppressed by markers: %d issues, %d files", me.SuppressedByMarker, me.IgnoredByMarker)
+	}
+
+	if me.NewIssues > 0 || me.SuppressedByBaseline > 0 {
+		console.NewLine().Greenf("New issues: %d, Suppressed by baseline: %d", me.NewIssues, me.SuppressedByBaseline)
+	}
+
+	if me.Verified > 0 || me.Repaired > 0 || me.RolledBack > 0 {
+		console.NewLine().Greenf("Fixes verified: %d, Repaired: %d, Rolled back: %d", me.Verified, me.Repaired, me.RolledBack)
+	}
+
+	if me.FailingIssues > 0 {
+		console.NewLine().Redf("Failing issues: %d", me.FailingIssues)
+	}
+
+	if len(me.IssuesBySeverity) > 0 {
+		severities := make([]string, 0, len(me.IssuesBySeverity))
+		for severity := range me.IssuesBySeverity {
+			severities = append(severities, severity)
+		}
+		sort.Strings(severities)
+
+		console.NewLine().Green("Issues by severity:")
+		for _, severity := range severities {
+			console.Greenf(" %s=%d", severity, me.IssuesBySeverity[severity])
+		}
+	}
 }
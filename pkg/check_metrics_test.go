@@ -0,0 +1,60 @@
+package batchai
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+func TestWriteCheckMetricsJson(t *testing.T) {
+	a := require.New(t)
+
+	x := NewKontext(afero.NewMemMapFs())
+	x.Args = &AppArgsT{MetricsOut: "/work/metrics.json"}
+
+	metrics := NewCheckMetrics()
+	metrics.WithWorkingFiles([]string{"a.go", "b.go", "c.go"}, 2, 1)
+	metrics.Processed = 3
+	metrics.Succeeded = 2
+	metrics.Skipped = 1
+	metrics.Usage.TotalTokens = 100
+	metrics.TotalIssue = 3
+	metrics.IssuesBySeverity["major"] = 2
+	metrics.IssuesBySeverity["minor"] = 1
+
+	WriteMetricsJson(x, comm.NewConsole(true), metrics)
+
+	m := map[string]any{}
+	comm.FromJsonP(comm.ReadFileTextP(x.Fs, "/work/metrics.json"), false, &m)
+
+	a.Equal(float64(3), m["files"])
+	a.Equal(float64(2), m["ignored"])
+	a.Equal(float64(1), m["failed"])
+	a.Equal(float64(1), m["skipped"])
+	a.Equal(float64(100), m["usage"].(map[string]any)["total_tokens"])
+	a.Equal(map[string]any{"major": float64(2), "minor": float64(1)}, m["issues_by_severity"])
+}
+
+func TestCheckMetricsAddResult(t *testing.T) {
+	a := require.New(t)
+
+	report := &CheckReportT{HasIssue: true, Issues: []CheckIssue{{Severity: "major"}, {Severity: "minor"}}, ModelUsageMetrics: NewModelUsageMetrics()}
+	checkArgs := &CheckArgsT{FailOn: "major"}
+
+	metrics := NewCheckMetrics()
+	metrics.AddResult(checkArgs, &CheckResultT{Report: report, Reported: report})
+	// the cached report is counted the same as the exit code
+	metrics.AddResult(checkArgs, &CheckResultT{Report: report, Reported: report, Skipped: true})
+	metrics.AddResult(checkArgs, &CheckResultT{Failed: true})
+
+	a.Equal(1, metrics.Succeeded)
+	a.Equal(1, metrics.Skipped)
+	a.Equal(1, metrics.Failed)
+	a.Equal(2, metrics.HasIssue)
+	a.Equal(4, metrics.TotalIssue)
+	a.Equal(map[string]int{"major": 2, "minor": 2}, metrics.IssuesBySeverity)
+	a.Equal(2, metrics.FailingIssues)
+}
@@ -0,0 +1,55 @@
+package batchai
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+// BuildCheckPatch builds a single unified patch of the fixes in all reports, against the current
+// content of the files in the working tree. Returns the patch and the amount of patched files.
+func BuildCheckPatch(x Kontext, c comm.Console, reports []CheckReport) (string, int) {
+	repository := x.Args.Repository
+
+	files := map[string]CheckReport{}
+	for _, report := range reports {
+		if report == nil || !report.HasIssue || len(report.FixedCode) == 0 || report.FixedCode == report.OriginalCode {
+			continue
+		}
+		files[RelativeRepositoryPath(repository, report.Path)] = report
+	}
+
+	relativeFiles := make([]string, 0, len(files))
+	for f := range files {
+		relativeFiles = append(relativeFiles, f)
+	}
+	sort.Strings(relativeFiles)
+
+	var r strings.Builder
+	amount := 0
+	for _, f := range relativeFiles {
+		report := files[f]
+
+		currentCode := comm.ReadFileTextP(x.Fs, filepath.Join(repository, filepath.FromSlash(f)))
+		if comm.NormalizeCode(currentCode) != report.OriginalCode {
+			c.NewLine().Yellow("patch skipped: ").Defaultf("%s changed since it was checked", f)
+			continue
+		}
+
+		patch, err := BuildUnifiedPatch(f, currentCode, report.FixedCode)
+		if err != nil {
+			c.NewLine().Red("patch failed: ").Defaultf("%s, %+v", f, err)
+			continue
+		}
+		if len(patch) == 0 {
+			continue
+		}
+
+		r.WriteString(patch)
+		amount++
+	}
+
+	return r.String(), amount
+}
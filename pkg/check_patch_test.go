@@ -0,0 +1,60 @@
+package batchai
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+func TestBuildCheckPatch(t *testing.T) {
+	a := require.New(t)
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	repo := t.TempDir()
+	fs := afero.NewOsFs()
+
+	_, err := comm.ExecGit(fs, repo, []string{"init", "-q"}, true)
+	a.NoError(err)
+
+	comm.WriteFileTextP(fs, filepath.Join(repo, "a.go"), "package a\n\nfunc A() {\n\tprintln(1)\n}")
+	comm.WriteFileTextP(fs, filepath.Join(repo, "b.go"), "package b\n")
+	comm.WriteFileTextP(fs, filepath.Join(repo, "c.go"), "package c\n")
+
+	x := NewKontext(fs)
+	x.Args = &AppArgsT{Repository: repo}
+
+	reports := []CheckReport{
+		{
+			HasIssue:     true,
+			Path:         "a.go",
+			OriginalCode: "package a\n\nfunc A() {\n\tprintln(1)\n}\n",
+			FixedCode:    "package a\n\nfunc A() {\n\tprintln(2)\n}\n",
+		},
+		{HasIssue: false, Path: filepath.Join(repo, "b.go"), OriginalCode: "package b\n", FixedCode: "package b\n"},
+		{
+			// changed since it was checked
+			HasIssue:     true,
+			Path:         "c.go",
+			OriginalCode: "package c0\n",
+			FixedCode:    "package c1\n",
+		},
+	}
+
+	patch, amount := BuildCheckPatch(x, comm.NewConsole(true), reports)
+	a.Equal(1, amount)
+
+	patchFile := filepath.Join(t.TempDir(), "fix.patch")
+	comm.WriteFileTextP(fs, patchFile, patch)
+
+	_, err = comm.ExecGit(fs, repo, []string{"apply", patchFile}, true)
+	a.NoError(err)
+	a.Equal("package a\n\nfunc A() {\n\tprintln(2)\n}\n", comm.ReadFileTextP(fs, filepath.Join(repo, "a.go")))
+}
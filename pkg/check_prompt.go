@@ -25,6 +25,9 @@ func NewCheckPromptVariables() CheckPromptVariables {
 		"fix_begin":                FIX_BEGIN,
 		"fix_end":                  FIX_END,
 		"check_report_json_format": CHECK_REPORT_JSON_FORMAT,
+		"diff":                     "",
+		"ignore_marker":            CHECK_IGNORE_MARKER,
+		"ignore_file_marker":       CHECK_IGNORE_FILE_MARKER,
 	}}
 }
 
@@ -41,6 +44,12 @@ func (me CheckPromptVariables) WithCodeToCheck(codeToCheck string) CheckPromptVa
 	return me
 }
 
+// WithDiff provides the diff hunks of the file, so that the model focuses on the changed lines
+func (me CheckPromptVariables) WithDiff(diff string) CheckPromptVariables {
+	me.Data["diff"] = strings.TrimSpace(diff)
+	return me
+}
+
 func (me CheckPromptVariables) WithLang(lang string) CheckPromptVariables {
 	me.Data["lang"] = lang
 	return me
@@ -1,13 +1,14 @@
 package batchai
 
 import (
-	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
 	"errors"
+	"path/filepath"
 	"strings"
+	"unicode"
 
 	"github.com/qiangyt/batchai/comm"
-
-	"github.com/pkg/diff"
 )
 
 const CHECK_REPORT_JSON_FORMAT = `
@@ -51,6 +52,71 @@ type CheckIssueT struct {
 
 type CheckIssue = *CheckIssueT
 
+const (
+	CHECK_SEVERITY_TRIVIAL  = "trivial"
+	CHECK_SEVERITY_MINOR    = "minor"
+	CHECK_SEVERITY_MAJOR    = "major"
+	CHECK_SEVERITY_CRITICAL = "critical"
+)
+
+// CheckSeverityLevel orders the severities from trivial (0) to critical (3), or returns -1 if unknown
+func CheckSeverityLevel(severity string) int {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case CHECK_SEVERITY_TRIVIAL:
+		return 0
+	case CHECK_SEVERITY_MINOR:
+		return 1
+	case CHECK_SEVERITY_MAJOR:
+		return 2
+	case CHECK_SEVERITY_CRITICAL:
+		return 3
+	}
+	return -1
+}
+
+// IsAtOrAbove tells if the severity of issue is at or above the threshold.
+// Unknown severity is taken as minor, so that it's not missed by the default threshold.
+func (me CheckIssue) IsAtOrAbove(threshold string) bool {
+	level := CheckSeverityLevel(me.Severity)
+	if level < 0 {
+		level = CheckSeverityLevel(CHECK_SEVERITY_MINOR)
+	}
+	return level >= CheckSeverityLevel(threshold)
+}
+
+// amount of lines around the issue lines, which are taken into the fingerprint
+const CHECK_ISSUE_FINGERPRINT_CONTEXT_LINES = 2
+
+// NormalizedShortDescription lower-cases the short description, and collapses the punctuations and spaces
+func (me CheckIssue) NormalizedShortDescription() string {
+	words := strings.FieldsFunc(strings.ToLower(me.ShortDescription), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// Fingerprint identifies the issue by the path, the normalized short description and the hash of
+// the code lines around the issue, instead of the raw line numbers which shift on every change
+func (me CheckIssue) Fingerprint(path string, code string) string {
+	// ignores the indentation and blank lines, which don't change the meaning of code
+	surrounding := []string{}
+	if me.IssueLineBegin > 0 {
+		lines := strings.Split(code, "\n")
+
+		begin := max(me.IssueLineBegin-CHECK_ISSUE_FINGERPRINT_CONTEXT_LINES, 1)
+		end := min(max(me.IssueLineEnd, me.IssueLineBegin)+CHECK_ISSUE_FINGERPRINT_CONTEXT_LINES, len(lines))
+		for i := begin; i <= end; i++ {
+			if line := strings.TrimSpace(lines[i-1]); len(line) > 0 {
+				surrounding = append(surrounding, line)
+			}
+		}
+	}
+	codeHash := sha1.Sum([]byte(strings.Join(surrounding, "\n")))
+
+	hash := sha1.Sum([]byte(path + "\x00" + me.NormalizedShortDescription() + "\x00" + hex.EncodeToString(codeHash[:])))
+	return hex.EncodeToString(hash[:])
+}
+
 func (me CheckIssue) Print(console comm.Console) {
 	console.NewLine().Printf("Short Description: %s", me.ShortDescription)
 	console.NewLine().Printf("Detailed Description: %s", me.DetailedExplaination)
@@ -63,25 +129,72 @@ func (me CheckIssue) Print(console comm.Console) {
 }
 
 type CheckReportT struct {
-	HasIssue          bool              `json:"has_issue"`
-	OverallSeverity   string            `json:"overall_severity"`
-	Issues            []CheckIssue      `json:"issues"`
-	FixedCode         string            `json:"fixed_code"`
-	OriginalCode      string            `json:"original_code"`
+	HasIssue        bool         `json:"has_issue"`
+	OverallSeverity string       `json:"overall_severity"`
+	Issues          []CheckIssue `json:"issues"`
+	FixedCode       string       `json:"fixed_code"`
+	OriginalCode    string       `json:"original_code"`
+	// with --with-diff, the diff hunks sent to the model along with the original code
+	Diff              string            `json:"diff,omitempty"`
 	Path              string            `json:"path"`
 	ModelUsageMetrics ModelUsageMetrics `json:"model_usage_metrics"`
+
+	// amount of issues suppressed by the batchai:ignore markers
+	SuppressedByMarker int `json:"suppressed_by_marker"`
+	// if the file is ignored by the batchai:ignore-file marker
+	IgnoredByMarker bool `json:"ignored_by_marker"`
+
+	// with the verify command, either verified, repaired or rolled_back
+	FixStatus      string `json:"fix_status,omitempty"`
+	RepairAttempts int    `json:"repair_attempts,omitempty"`
+	// output of the verify command which failed at last
+	VerifyOutput string `json:"verify_output,omitempty"`
+	// the fixed code failed to verify at last, and rolled back
+	RejectedFixedCode string `json:"rejected_fixed_code,omitempty"`
 }
 
 type CheckReport = *CheckReportT
 
+// RelativeRepositoryPath returns the slash-separated path of the file relative to the repository.
+// The path of a report could be either absolute or relative.
+func RelativeRepositoryPath(repository string, file string) string {
+	if filepath.IsAbs(file) {
+		if rel, err := filepath.Rel(repository, file); err == nil {
+			file = rel
+		}
+	}
+	return filepath.ToSlash(file)
+}
+
+// CountIssuesAtOrAbove counts the issues whose severity is at or above the threshold
+func (me CheckReport) CountIssuesAtOrAbove(threshold string) int {
+	if !me.HasIssue {
+		return 0
+	}
+
+	r := 0
+	for _, issue := range me.Issues {
+		if issue.IsAtOrAbove(threshold) {
+			r++
+		}
+	}
+	return r
+}
+
 func ExtractFixedCode(input string) (string, string) {
-	begin := strings.Index(input, FIX_BEGIN_LINE)
+	return ExtractMarkedCode(input, FIX_BEGIN_LINE, FIX_END_LINE)
+}
+
+// ExtractMarkedCode extracts the code surrounded by the begin and end markers,
+// returns the extracted code and the remained input
+func ExtractMarkedCode(input string, beginMarker string, endMarker string) (string, string) {
+	begin := strings.Index(input, beginMarker)
 	if begin < 0 {
 		return "", input
 	}
-	block := input[begin+len(FIX_BEGIN_LINE):]
+	block := input[begin+len(beginMarker):]
 
-	end := strings.LastIndex(block, FIX_END_LINE)
+	end := strings.LastIndex(block, endMarker)
 	if end <= 0 {
 		panic(errors.New("unmatched separator tag"))
 	}
@@ -91,7 +204,7 @@ func ExtractFixedCode(input string) (string, string) {
 		result, _ = comm.ExtractMarkdownCodeBlocksP(result)
 	}
 
-	remained := input[:begin] + block[end+len(FIX_END_LINE):]
+	remained := input[:begin] + block[end+len(endMarker):]
 	return result, remained
 }
 
@@ -119,6 +232,10 @@ func ExtractCheckReport(answer string, isGolang bool) CheckReport {
 }
 
 func (me CheckReport) Print(console comm.Console) {
+	if me.SuppressedByMarker > 0 {
+		console.NewLine().Gray("suppressed by "+CHECK_IGNORE_MARKER+": ").Defaultf("%d issues", me.SuppressedByMarker)
+	}
+
 	if !me.HasIssue {
 		console.NewLine().Print("no issue")
 		return
@@ -139,20 +256,5 @@ func (me CheckReport) Print(console comm.Console) {
 	console.NewLine().Print("Check:")
 	console.NewLine()
 
-	var buf bytes.Buffer
-	if err := diff.Text("original", "fixed", me.OriginalCode, me.FixedCode, &buf); err == nil {
-		for i, line := range strings.Split(buf.String(), "\n") {
-			if i < 3 {
-				console2.Yellowln(line)
-			} else {
-				if strings.HasPrefix(line, "+") {
-					console2.Greenln(line)
-				} else if strings.HasPrefix(line, "-") {
-					console2.Redln(line)
-				} else {
-					console2.Defaultln(line)
-				}
-			}
-		}
-	}
+	PrintCodeDiff(console2, "original", "fixed", me.OriginalCode, me.FixedCode)
 }
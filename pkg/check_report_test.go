@@ -47,3 +47,23 @@ abc
 	a.Equal("abc\n", code)
 	a.Equal("\n```\n", remaining)
 }
+
+func TestCountIssuesAtOrAbove(t *testing.T) {
+	a := require.New(t)
+
+	report := &CheckReportT{HasIssue: true, Issues: []CheckIssue{
+		{Severity: "trivial"},
+		{Severity: "minor"},
+		{Severity: "Major"},
+		{Severity: "critical"},
+		{Severity: "unknown"},
+	}}
+
+	a.Equal(5, report.CountIssuesAtOrAbove(CHECK_SEVERITY_TRIVIAL))
+	a.Equal(4, report.CountIssuesAtOrAbove(CHECK_SEVERITY_MINOR))
+	a.Equal(2, report.CountIssuesAtOrAbove(CHECK_SEVERITY_MAJOR))
+	a.Equal(1, report.CountIssuesAtOrAbove(CHECK_SEVERITY_CRITICAL))
+
+	report.HasIssue = false
+	a.Equal(0, report.CountIssuesAtOrAbove(CHECK_SEVERITY_TRIVIAL))
+}
@@ -0,0 +1,189 @@
+package batchai
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+const (
+	SARIF_VERSION = "2.1.0"
+	SARIF_SCHEMA  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	sarifSrcRoot = "SRCROOT"
+)
+
+type SarifMessageT struct {
+	Text string `json:"text"`
+}
+
+type SarifArtifactLocationT struct {
+	Uri       string `json:"uri"`
+	UriBaseId string `json:"uriBaseId,omitempty"`
+}
+
+type SarifRegionT struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+type SarifPhysicalLocationT struct {
+	ArtifactLocation SarifArtifactLocationT `json:"artifactLocation"`
+	Region           *SarifRegionT          `json:"region,omitempty"`
+}
+
+type SarifLocationT struct {
+	PhysicalLocation SarifPhysicalLocationT `json:"physicalLocation"`
+}
+
+type SarifRuleT struct {
+	Id               string         `json:"id"`
+	ShortDescription SarifMessageT  `json:"shortDescription"`
+	HelpUri          string         `json:"helpUri,omitempty"`
+	Help             *SarifMessageT `json:"help,omitempty"`
+}
+
+type SarifResultT struct {
+	RuleId     string            `json:"ruleId"`
+	RuleIndex  int               `json:"ruleIndex"`
+	Level      string            `json:"level"`
+	Message    SarifMessageT     `json:"message"`
+	Locations  []SarifLocationT  `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type SarifDriverT struct {
+	Name           string       `json:"name"`
+	InformationUri string       `json:"informationUri"`
+	Rules          []SarifRuleT `json:"rules"`
+}
+
+type SarifRunT struct {
+	Tool struct {
+		Driver SarifDriverT `json:"driver"`
+	} `json:"tool"`
+	OriginalUriBaseIds map[string]SarifArtifactLocationT `json:"originalUriBaseIds,omitempty"`
+	Results            []SarifResultT                    `json:"results"`
+}
+
+// SarifLogT is the SARIF 2.1.0 log of the check reports, for code-scanning tools to ingest
+type SarifLogT struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []SarifRunT `json:"runs"`
+}
+
+type SarifLog = *SarifLogT
+
+// SarifLevel maps the severity of batchai to the SARIF level
+func SarifLevel(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical", "major":
+		return "error"
+	case "trivial":
+		return "note"
+	}
+	return "warning"
+}
+
+func sarifRuleId(issue CheckIssue) string {
+	hash := sha1.Sum([]byte(issue.NormalizedShortDescription()))
+	return "batchai/" + hex.EncodeToString(hash[:])[:12]
+}
+
+// NewSarifLog merges the check reports into one SARIF log.
+// The issues sharing the same short description become the same rule.
+func NewSarifLog(repository string, reports []CheckReport) SarifLog {
+	run := SarifRunT{
+		OriginalUriBaseIds: map[string]SarifArtifactLocationT{
+			sarifSrcRoot: {Uri: "file://" + filepath.ToSlash(repository) + "/"},
+		},
+		Results: []SarifResultT{},
+	}
+	run.Tool.Driver = SarifDriverT{
+		Name:           "batchai",
+		InformationUri: "https://github.com/qiangyt/batchai",
+		Rules:          []SarifRuleT{},
+	}
+
+	sorted := make([]CheckReport, 0, len(reports))
+	for _, report := range reports {
+		if report != nil && report.HasIssue {
+			sorted = append(sorted, report)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return RelativeRepositoryPath(repository, sorted[i].Path) < RelativeRepositoryPath(repository, sorted[j].Path)
+	})
+
+	ruleIndexes := map[string]int{}
+	for _, report := range sorted {
+		uri := RelativeRepositoryPath(repository, report.Path)
+
+		for _, issue := range report.Issues {
+			ruleId := sarifRuleId(issue)
+			ruleIndex, exists := ruleIndexes[ruleId]
+			if !exists {
+				rule := SarifRuleT{
+					Id:               ruleId,
+					ShortDescription: SarifMessageT{Text: issue.ShortDescription},
+				}
+				ruleIndex = len(run.Tool.Driver.Rules)
+				ruleIndexes[ruleId] = ruleIndex
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+			}
+
+			if rule := &run.Tool.Driver.Rules[ruleIndex]; len(rule.HelpUri) == 0 && len(issue.IssueReferenceUrls) > 0 {
+				rule.HelpUri = issue.IssueReferenceUrls[0]
+				rule.Help = &SarifMessageT{Text: strings.Join(issue.IssueReferenceUrls, "\n")}
+			}
+
+			message := issue.ShortDescription
+			if len(issue.DetailedExplaination) > 0 {
+				message += "\n\n" + issue.DetailedExplaination
+			}
+			if len(issue.Suggestion) > 0 {
+				message += "\n\nSuggestion: " + issue.Suggestion
+			}
+
+			location := SarifLocationT{
+				PhysicalLocation: SarifPhysicalLocationT{
+					ArtifactLocation: SarifArtifactLocationT{Uri: uri, UriBaseId: sarifSrcRoot},
+				},
+			}
+			if issue.IssueLineBegin > 0 {
+				region := &SarifRegionT{StartLine: issue.IssueLineBegin}
+				if issue.IssueLineEnd >= issue.IssueLineBegin {
+					region.EndLine = issue.IssueLineEnd
+				}
+				location.PhysicalLocation.Region = region
+			}
+
+			run.Results = append(run.Results, SarifResultT{
+				RuleId:    ruleId,
+				RuleIndex: ruleIndex,
+				Level:     SarifLevel(issue.Severity),
+				Message:   SarifMessageT{Text: message},
+				Locations: []SarifLocationT{location},
+				Properties: map[string]string{
+					"severity":        issue.Severity,
+					"severity_reason": issue.SeverityReason,
+				},
+			})
+		}
+	}
+
+	return &SarifLogT{
+		Schema:  SARIF_SCHEMA,
+		Version: SARIF_VERSION,
+		Runs:    []SarifRunT{run},
+	}
+}
+
+func (me SarifLog) Write(x Kontext, file string) {
+	comm.WriteFileTextP(x.Fs, file, comm.ToJsonP(me, true))
+}
@@ -0,0 +1,70 @@
+package batchai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSarifLevel(t *testing.T) {
+	a := require.New(t)
+
+	a.Equal("error", SarifLevel("critical"))
+	a.Equal("error", SarifLevel("major"))
+	a.Equal("warning", SarifLevel("minor"))
+	a.Equal("note", SarifLevel("trivial"))
+	a.Equal("warning", SarifLevel(""))
+}
+
+func TestNewSarifLog(t *testing.T) {
+	a := require.New(t)
+
+	reports := []CheckReport{
+		{
+			HasIssue: true,
+			Path:     "/repo/src/b.go",
+			Issues: []CheckIssue{
+				{ShortDescription: "Unchecked error", IssueLineBegin: 3, IssueLineEnd: 5, Severity: "major",
+					IssueReferenceUrls: []string{"https://go.dev/doc/effective_go#errors", "https://example.com"}},
+			},
+		},
+		{HasIssue: false, Path: "src/c.go"},
+		{
+			HasIssue: true,
+			Path:     "src/a.go",
+			Issues: []CheckIssue{
+				{ShortDescription: "unchecked  error", IssueLineBegin: 7, IssueLineEnd: 7, Severity: "minor"},
+				{ShortDescription: "Magic number", Severity: "trivial"},
+			},
+		},
+	}
+
+	log := NewSarifLog("/repo", reports)
+	a.Equal(SARIF_VERSION, log.Version)
+	a.Len(log.Runs, 1)
+
+	run := log.Runs[0]
+	a.Equal("batchai", run.Tool.Driver.Name)
+	a.Equal("file:///repo/", run.OriginalUriBaseIds["SRCROOT"].Uri)
+
+	// same short description shares the same rule
+	a.Len(run.Tool.Driver.Rules, 2)
+	a.Len(run.Results, 3)
+
+	r := run.Results[0]
+	a.Equal("src/a.go", r.Locations[0].PhysicalLocation.ArtifactLocation.Uri)
+	a.Equal("warning", r.Level)
+	a.Equal(7, r.Locations[0].PhysicalLocation.Region.StartLine)
+
+	r = run.Results[1]
+	a.Equal("note", r.Level)
+	a.Nil(r.Locations[0].PhysicalLocation.Region)
+
+	r = run.Results[2]
+	a.Equal("src/b.go", r.Locations[0].PhysicalLocation.ArtifactLocation.Uri)
+	a.Equal("error", r.Level)
+	a.Equal(run.Results[0].RuleId, r.RuleId)
+	a.Equal("https://go.dev/doc/effective_go#errors", run.Tool.Driver.Rules[r.RuleIndex].HelpUri)
+	a.Equal(3, r.Locations[0].PhysicalLocation.Region.StartLine)
+	a.Equal(5, r.Locations[0].PhysicalLocation.Region.EndLine)
+}
@@ -0,0 +1,117 @@
+package batchai
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// suppresses the issues beginning at the marked line, or at the next line if the marker is on its own line above
+	CHECK_IGNORE_MARKER = "batchai:ignore"
+	// suppresses all issues of the file, must be in the top lines of the file
+	CHECK_IGNORE_FILE_MARKER = "batchai:ignore-file"
+	// the batchai:ignore-file marker is recognized only within the top lines
+	CHECK_IGNORE_FILE_TOP_LINES = 10
+)
+
+// the markers are recognized only in comments
+var checkMarkerCommentTokens = []string{"//", "#", "/*", "<!--", "--"}
+
+// CheckSuppressionT is the batchai:ignore markers found in the code
+type CheckSuppressionT struct {
+	IgnoreFile bool
+	// reasons by the line numbers of the markers
+	Reasons map[int]string
+	// lines of the markers which are the only content of the line, so apply to the next line as well
+	standalone map[int]bool
+}
+
+type CheckSuppression = *CheckSuppressionT
+
+func ParseCheckSuppression(code string) CheckSuppression {
+	r := &CheckSuppressionT{Reasons: map[int]string{}, standalone: map[int]bool{}}
+
+	for i, line := range strings.Split(code, "\n") {
+		lineNo := i + 1
+
+		index := strings.Index(line, CHECK_IGNORE_MARKER)
+		if index < 0 || !isCheckMarkerCommented(line[:index]) {
+			continue
+		}
+		rest := line[index+len(CHECK_IGNORE_MARKER):]
+
+		if strings.HasPrefix(rest, "-file") {
+			if lineNo <= CHECK_IGNORE_FILE_TOP_LINES && isCheckMarkerEnd(rest[len("-file"):]) {
+				r.IgnoreFile = true
+			}
+			continue
+		}
+
+		if isCheckMarkerEnd(rest) {
+			r.Reasons[lineNo] = checkMarkerReason(rest)
+			// nothing but the comment syntax before the marker
+			r.standalone[lineNo] = !strings.ContainsFunc(line[:index], func(ch rune) bool {
+				return unicode.IsLetter(ch) || unicode.IsDigit(ch)
+			})
+		}
+	}
+
+	return r
+}
+
+// isCheckMarkerCommented tells if the text before the marker ends with a comment token,
+// so that e.g. the marker in a string literal is not taken
+func isCheckMarkerCommented(before string) bool {
+	before = strings.TrimRight(before, " \t")
+	for _, token := range checkMarkerCommentTokens {
+		if strings.HasSuffix(before, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCheckMarkerEnd tells if the marker ends here, so that e.g. 'batchai:ignored' is not a marker
+func isCheckMarkerEnd(rest string) bool {
+	if len(rest) == 0 {
+		return true
+	}
+	ch := rune(rest[0])
+	return !unicode.IsLetter(ch) && !unicode.IsDigit(ch) && ch != '-' && ch != '_'
+}
+
+func checkMarkerReason(rest string) string {
+	rest = strings.TrimSpace(rest)
+	for _, closing := range []string{"*/", "-->", "#}"} {
+		rest = strings.TrimSuffix(rest, closing)
+	}
+	return strings.TrimSpace(strings.TrimLeft(rest, ":"))
+}
+
+// Suppresses tells if a marker is on the first line of the issue, or is on its own line just above it.
+// The marker inside the issue lines doesn't suppress, e.g. a function-level issue is not silenced by a marker in the function
+func (me CheckSuppression) Suppresses(issue CheckIssue) bool {
+	if me.IgnoreFile {
+		return true
+	}
+	if issue.IssueLineBegin <= 0 {
+		return false
+	}
+
+	if me.standalone[issue.IssueLineBegin-1] {
+		return true
+	}
+	_, has := me.Reasons[issue.IssueLineBegin]
+	return has
+}
+
+// Filter returns the issues not suppressed, and the amount of suppressed issues
+func (me CheckSuppression) Filter(issues []CheckIssue) ([]CheckIssue, int) {
+	r := []CheckIssue{}
+	for _, issue := range issues {
+		if !me.Suppresses(issue) {
+			r = append(r, issue)
+		}
+	}
+	return r, len(issues) - len(r)
+}
@@ -0,0 +1,65 @@
+package batchai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSuppressionCode = `package main
+
+func main() {
+	// batchai:ignore division by zero is intended
+	println(1 / 0)
+	x := 1 // batchai:ignore: unused on purpose
+	y := 2 /* batchai:ignored */
+	println(3 / 0)
+	println("batchai:ignore")
+}
+`
+
+func TestParseCheckSuppression(t *testing.T) {
+	a := require.New(t)
+
+	s := ParseCheckSuppression(testSuppressionCode)
+	a.False(s.IgnoreFile)
+	a.Equal(map[int]string{
+		4: "division by zero is intended",
+		6: "unused on purpose",
+	}, s.Reasons)
+
+	a.True(ParseCheckSuppression("// Copyright\n\n/* batchai:ignore-file generated */\npackage main\n").IgnoreFile)
+	a.True(ParseCheckSuppression("# batchai:ignore-file").IgnoreFile)
+	a.False(ParseCheckSuppression("# batchai:ignore-files").IgnoreFile)
+
+	// not at the top of file
+	a.False(ParseCheckSuppression("\n\n\n\n\n\n\n\n\n\n// batchai:ignore-file\n").IgnoreFile)
+
+	// not in a comment
+	a.False(ParseCheckSuppression("msg := \"batchai:ignore-file\"\n").IgnoreFile)
+	a.Empty(ParseCheckSuppression("msg := \"batchai:ignore\"\n").Reasons)
+	a.Equal(map[int]string{1: "sql", 2: "html"}, ParseCheckSuppression("SELECT 1; -- batchai:ignore sql\n<!-- batchai:ignore html -->\n").Reasons)
+}
+
+func TestCheckSuppressionFilter(t *testing.T) {
+	a := require.New(t)
+
+	s := ParseCheckSuppression(testSuppressionCode)
+
+	below := &CheckIssueT{ShortDescription: "division by zero", IssueLineBegin: 5, IssueLineEnd: 5}
+	on := &CheckIssueT{ShortDescription: "unused x", IssueLineBegin: 6, IssueLineEnd: 6}
+	// the markers in the function don't silence the function-level issue
+	covering := &CheckIssueT{ShortDescription: "block", IssueLineBegin: 3, IssueLineEnd: 10}
+	unmarked := &CheckIssueT{ShortDescription: "unused y", IssueLineBegin: 7, IssueLineEnd: 7}
+	later := &CheckIssueT{ShortDescription: "division by zero", IssueLineBegin: 8, IssueLineEnd: 8}
+	literal := &CheckIssueT{ShortDescription: "hard-coded string", IssueLineBegin: 9, IssueLineEnd: 9}
+	unknownLine := &CheckIssueT{ShortDescription: "naming"}
+
+	issues, suppressed := s.Filter([]CheckIssue{below, on, covering, unmarked, later, literal, unknownLine})
+	a.Equal(2, suppressed)
+	a.Equal([]CheckIssue{covering, unmarked, later, literal, unknownLine}, issues)
+
+	issues, suppressed = ParseCheckSuppression("// batchai:ignore-file\n" + testSuppressionCode).Filter([]CheckIssue{unmarked, unknownLine})
+	a.Equal(2, suppressed)
+	a.Empty(issues)
+}
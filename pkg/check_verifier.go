@@ -0,0 +1,69 @@
+package batchai
+
+import (
+	"os/exec"
+	"sync"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+const (
+	// the fix passed the verify command at the first time
+	FIX_STATUS_VERIFIED = "verified"
+	// the fix failed the verify command, then the model repaired it
+	FIX_STATUS_REPAIRED = "repaired"
+	// the fix never passed the verify command, so the file is reverted to the original code
+	FIX_STATUS_ROLLED_BACK = "rolled_back"
+)
+
+// the output of verify command is truncated to keep the repair prompt small
+const CHECK_VERIFY_OUTPUT_MAX_LEN = 4000
+
+// CheckVerifierT runs the verify command, e.g. `go build ./...`, against the fixed files
+type CheckVerifierT struct {
+	Command           string
+	MaxRepairAttempts int
+
+	// the fixed files are written and verified one by one, so that the concurrent fixes don't break the verification of each other
+	lock sync.Mutex
+}
+
+type CheckVerifier = *CheckVerifierT
+
+// NewCheckVerifier returns nil if no verify command
+func NewCheckVerifier(command string, maxRepairAttempts int) CheckVerifier {
+	if len(command) == 0 {
+		return nil
+	}
+	return &CheckVerifierT{
+		Command:           command,
+		MaxRepairAttempts: max(maxRepairAttempts, 0),
+	}
+}
+
+// Verify runs the verify command under the repository directory, returns false and the output if it fails
+func (me CheckVerifier) Verify(x Kontext) (bool, string) {
+	output, err := comm.ExecShell(x.Context, x.Args.Repository, me.Command)
+	if err != nil {
+		if _, isExitError := err.(*exec.ExitError); !isExitError {
+			output = output + "\n" + err.Error()
+		}
+		return false, comm.TailText(output, CHECK_VERIFY_OUTPUT_MAX_LEN)
+	}
+	return true, ""
+}
+
+// Apply writes the fixed code then verifies it. If the verification fails, the file is reverted.
+func (me CheckVerifier) Apply(x Kontext, file string, fixedCode string) (bool, string) {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+
+	originalText := comm.ReadFileTextP(x.Fs, file)
+	comm.WriteFileTextP(x.Fs, file, fixedCode)
+
+	ok, output := me.Verify(x)
+	if !ok {
+		comm.WriteFileTextP(x.Fs, file, originalText)
+	}
+	return ok, output
+}
@@ -0,0 +1,112 @@
+package batchai
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+const testVerifyCommand = "if grep -q broken main.go; then echo 'main.go: still broken'; exit 1; fi"
+
+// newTestAnswerServer answers the chat completions in order, and repeats the last answer
+func newTestAnswerServer(t *testing.T, answers ...string) (*httptest.Server, *int) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := readTestRequest(t, r)
+
+		answer := answers[min(requests, len(answers)-1)]
+		requests++
+
+		content, _ := json.Marshal(answer)
+		if req["stream"] == true {
+			writeTestEvents(w,
+				fmt.Sprintf(`data: {"id":"1","object":"chat.completion.chunk","created":1,"model":"test-model","choices":[{"index":0,"delta":{"role":"assistant","content":%s},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`, content),
+				`data: [DONE]`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"1","object":"chat.completion","created":1,"model":"test-model","choices":[{"index":0,"message":{"role":"assistant","content":%s},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`, content)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func testFixAnswer(fixedCode string) string {
+	return "```json\n" + `{"has_issue": true, "overall_severity": "major", "issues": [{"short_description": "x is unused", "issue_line_begin": 3, "issue_line_end": 3, "severity": "major"}]}` +
+		"\n```\n" + FIX_BEGIN_LINE + fixedCode + FIX_END_LINE
+}
+
+func checkWithTestVerifier(t *testing.T, maxRepairAttempts int, answers ...string) (CheckReport, string, int) {
+	fs := afero.NewOsFs()
+	repo := t.TempDir()
+	file := filepath.Join(repo, "main.go")
+	comm.WriteFileTextP(fs, file, "package main\n\nvar x = 1\n")
+
+	server, requests := newTestAnswerServer(t, answers...)
+	model := newTestModelConfig(MODEL_PROVIDER_OPENAI, server.URL+"/")
+
+	config := &AppConfigT{CacheDir: t.TempDir(), Models: []ModelConfig{model}}
+	config.Check = &CheckConfigT{
+		AppConfig: config,
+		ModelId:   model.Id,
+		Prompt:    &CheckPromptT{Template: "{{.code_to_check}}"},
+	}
+
+	x := NewKontext(fs)
+	x.Config = config
+	x.Args = &AppArgsT{Repository: repo}
+
+	checkArgs := &CheckArgsT{Fix: FIX_MODE_WRITE, Verifier: NewCheckVerifier(testVerifyCommand, maxRepairAttempts)}
+
+	resultChan := make(chan CheckResult, 1)
+	NewCheckAgent(NewCheckReportManager(), NewSymbolManager(), NewModelService(config), file).run(x, checkArgs, resultChan, nil)
+	result := <-resultChan
+	require.False(t, result.Failed)
+
+	return result.Report, comm.ReadFileTextP(fs, file), *requests
+}
+
+func TestCheckVerifierVerified(t *testing.T) {
+	a := require.New(t)
+
+	report, code, requests := checkWithTestVerifier(t, 2, testFixAnswer("package main\n\nvar _ = 1\n"))
+	a.Equal(FIX_STATUS_VERIFIED, report.FixStatus)
+	a.Equal(0, report.RepairAttempts)
+	a.Equal("package main\n\nvar _ = 1\n", code)
+	a.Equal(1, requests)
+}
+
+func TestCheckVerifierRepaired(t *testing.T) {
+	a := require.New(t)
+
+	report, code, requests := checkWithTestVerifier(t, 2,
+		testFixAnswer("package main\n\nvar x = broken\n"),
+		FIX_BEGIN_LINE+"package main\n\nvar _ = 1\n"+FIX_END_LINE)
+	a.Equal(FIX_STATUS_REPAIRED, report.FixStatus)
+	a.Equal(1, report.RepairAttempts)
+	a.Equal("package main\n\nvar _ = 1\n", report.FixedCode)
+	a.Equal("package main\n\nvar _ = 1\n", code)
+	a.Equal(2, requests)
+}
+
+func TestCheckVerifierRolledBack(t *testing.T) {
+	a := require.New(t)
+
+	report, code, requests := checkWithTestVerifier(t, 2, testFixAnswer("package main\n\nvar x = broken\n"))
+	a.Equal(FIX_STATUS_ROLLED_BACK, report.FixStatus)
+	a.Equal(2, report.RepairAttempts)
+	a.Contains(report.VerifyOutput, "main.go: still broken")
+	a.Equal("package main\n\nvar x = 1\n", code)
+	// the rejected fix is neither cached nor exported as the fixed code
+	a.Equal(report.OriginalCode, report.FixedCode)
+	a.Equal("package main\n\nvar x = broken\n", report.RejectedFixedCode)
+	a.Equal(3, requests)
+}
@@ -0,0 +1,67 @@
+package batchai
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/diff"
+	"github.com/qiangyt/batchai/comm"
+)
+
+// PrintCodeDiff prints the colorized unified diff between the old code and the new code
+func PrintCodeDiff(console comm.Console, oldName string, newName string, oldCode string, newCode string) {
+	var buf bytes.Buffer
+	if err := diff.Text(oldName, newName, oldCode, newCode, &buf); err != nil {
+		return
+	}
+
+	for i, line := range strings.Split(buf.String(), "\n") {
+		if i < 3 {
+			console.Yellowln(line)
+		} else {
+			if strings.HasPrefix(line, "+") {
+				console.Greenln(line)
+			} else if strings.HasPrefix(line, "-") {
+				console.Redln(line)
+			} else {
+				console.Defaultln(line)
+			}
+		}
+	}
+}
+
+// marks the last line without trailing newline, so that the line differs from the one with newline
+const noNewlineSentinel = "\x00batchai-no-newline\x00"
+
+// BuildUnifiedPatch builds a git-apply-compatible unified patch of the file,
+// of which the path is relative to the repository root
+func BuildUnifiedPatch(file string, oldCode string, newCode string) (string, error) {
+	if oldCode == newCode {
+		return "", nil
+	}
+
+	markNoNewline := func(code string) string {
+		if len(code) > 0 && !strings.HasSuffix(code, "\n") {
+			return code + noNewlineSentinel + "\n"
+		}
+		return code
+	}
+
+	var buf bytes.Buffer
+	if err := diff.Text("a/"+file, "b/"+file, markNoNewline(oldCode), markNoNewline(newCode), &buf); err != nil {
+		return "", err
+	}
+
+	var r strings.Builder
+	fmt.Fprintf(&r, "diff --git a/%s b/%s\n", file, file)
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		if strings.Contains(line, noNewlineSentinel) {
+			r.WriteString(strings.Replace(line, noNewlineSentinel, "", 1))
+			r.WriteString("\\ No newline at end of file\n")
+		} else {
+			r.WriteString(line)
+		}
+	}
+	return r.String(), nil
+}
@@ -0,0 +1,37 @@
+package batchai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildUnifiedPatch(t *testing.T) {
+	a := require.New(t)
+
+	patch, err := BuildUnifiedPatch("src/x.go", "l1\nl2\nl3\n", "l1\nL2\nl3\n")
+	a.NoError(err)
+	a.Equal("diff --git a/src/x.go b/src/x.go\n"+
+		"--- a/src/x.go\n"+
+		"+++ b/src/x.go\n"+
+		"@@ -1,3 +1,3 @@\n"+
+		" l1\n"+
+		"-l2\n"+
+		"+L2\n"+
+		" l3\n", patch)
+
+	patch, err = BuildUnifiedPatch("x.go", "l1\nl2", "l1\nl2\n")
+	a.NoError(err)
+	a.Equal("diff --git a/x.go b/x.go\n"+
+		"--- a/x.go\n"+
+		"+++ b/x.go\n"+
+		"@@ -1,2 +1,2 @@\n"+
+		" l1\n"+
+		"-l2\n"+
+		"\\ No newline at end of file\n"+
+		"+l2\n", patch)
+
+	patch, err = BuildUnifiedPatch("x.go", "same\n", "same\n")
+	a.NoError(err)
+	a.Empty(patch)
+}
@@ -0,0 +1,197 @@
+package batchai
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+type CommentResultT struct {
+	Report  CommentReport
+	Skipped bool
+	Failed  bool
+}
+
+type CommentResult = *CommentResultT
+
+type CommentAgentT struct {
+	SymbolAwareAgentT
+
+	reportManager   CommentReportManager
+	codeFileManager CodeFileManager
+
+	file         string
+	relativeFile string
+}
+
+type CommentAgent = *CommentAgentT
+
+func NewCommentAgent(reportManager CommentReportManager,
+	codeFileManager CodeFileManager,
+	symbolManager SymbolManager,
+	modelService ModelService,
+	codeFile string,
+) CommentAgent {
+	return &CommentAgentT{
+		SymbolAwareAgentT: newSymbolAwareAgent(symbolManager, modelService),
+		reportManager:     reportManager,
+		codeFileManager:   codeFileManager,
+
+		file: codeFile,
+	}
+}
+
+func (me CommentAgent) run(x Kontext, commentArgs CommentArgs, resultChan chan<- CommentResult, output func(text string)) {
+	c := comm.NewConsole(!x.Args.Concurrent).WithOutput(output)
+	me.relativeFile = me.file[len(x.Args.Repository)+1:]
+
+	c.Begin()
+	defer c.End()
+	c.Greenf("\n\n▹▹▹▹▹ processing: %s\n", me.relativeFile)
+
+	defer func() {
+		if e := recover(); e != nil {
+			c.NewLine().Red("failed: ").Defaultf("%v, %+v", me.relativeFile, e)
+			resultChan <- &CommentResultT{Failed: true}
+		}
+	}()
+
+	result := me.commentFile(x, commentArgs, c)
+
+	resultChan <- result
+}
+
+func (me CommentAgent) Run(x Kontext, commentArgs CommentArgs, resultChan chan<- CommentResult, pool comm.WorkerPool) {
+	if !x.Args.Concurrent {
+		me.run(x, commentArgs, resultChan, nil)
+		return
+	}
+
+	pool.Submit(func(output func(text string)) {
+		me.run(x, commentArgs, resultChan, output)
+	})
+}
+
+func (me CommentAgent) commentFile(x Kontext, commentArgs CommentArgs, c comm.Console) CommentResult {
+	c.NewLine().Green("--------------------")
+	c.NewLine().Greenln(me.relativeFile)
+
+	code := me.codeFileManager.Load(x, me.file)
+	if code == nil {
+		panic(fmt.Errorf("file not found: %s", me.file))
+	}
+	if code.IsChanged() {
+		// takes the latest code as the original code to compare with
+		me.codeFileManager.Save(x, me.file, code.Latest)
+	}
+	newCode := comm.NormalizeCode(code.Original)
+
+	lastReport := me.reportManager.LoadReport(x, me.file)
+	if lastReport != nil && lastReport.Level == commentArgs.Level {
+		noCodeChanges := (newCode == lastReport.OriginalCode)
+		alreadyCommented := lastReport.HasChange && (newCode == lastReport.CommentedCode)
+		if noCodeChanges || alreadyCommented {
+			if !x.Args.Force {
+				c.NewLine().Default("✔ no code changes since last execution, skipped")
+				return &CommentResultT{Report: lastReport, Skipped: true}
+			}
+		}
+	}
+
+	newReport := me.commentCode(x, c, commentArgs, newCode)
+	newReport.Print(c)
+
+	if newReport.HasChange && !newReport.Rejected {
+		me.codeFileManager.Save(x, me.file, newReport.CommentedCode)
+	}
+
+	reportFile := me.reportManager.SaveReport(x, me.file, newReport)
+	c.NewLine().Blue("✔ report: ").Default(reportFile[len(x.Args.Repository)+1:])
+
+	return &CommentResultT{Report: newReport, Skipped: false}
+}
+
+type CommentCodeWriterT struct {
+	console       comm.Console
+	inCommentCode bool
+}
+
+type CommentCodeWriter = *CommentCodeWriterT
+
+func NewCommentCodeWriter(console comm.Console) CommentCodeWriter {
+	return &CommentCodeWriterT{
+		console:       console,
+		inCommentCode: false,
+	}
+}
+
+// Write method to implement io.Writer
+func (me CommentCodeWriter) Write(p []byte) (n int, err error) {
+	s := string(p)
+
+	if me.inCommentCode {
+		if strings.Contains(s, COMMENT_END) {
+			me.inCommentCode = false
+		} else {
+			me.console.Default(s)
+		}
+	} else {
+		if strings.Contains(s, COMMENT_BEGIN) {
+			me.inCommentCode = true
+		}
+	}
+
+	return len(p), nil
+}
+
+func (me CommentAgent) commentCode(x Kontext, c comm.Console, commentArgs CommentArgs, code string) CommentReport {
+	verbose := x.Args.Verbose
+
+	sysPrompt := x.Config.Comment.RenderPrompt(commentArgs.Level, commentArgs.MissingOnly, code, me.relativeFile)
+	if x.Args.EnableSymbolReference {
+		sysPrompt = me.provideSymbols(x, c, x.Config.Comment.ModelId, me.file, code, sysPrompt)
+	}
+	mem := me.memory
+	mem.AddSystemMessage(sysPrompt)
+
+	if x.Args.EnableSymbolReference {
+		mem.AddUserMessage("comment the code, with provided symbols as references")
+	} else {
+		mem.AddUserMessage("comment the code")
+	}
+	if verbose {
+		c.NewLine().Gray("chat: ").Default("comment the code")
+	}
+
+	answer, metrics := me.modelService.Chat(x, c, x.Config.Comment.ModelId, true, mem, func() io.Writer { return NewCommentCodeWriter(c) })
+	if verbose {
+		c.NewLine().Gray("answer: ").Default(mem.Format())
+	}
+
+	commentedCode, _ := ExtractMarkedCode(answer, COMMENT_BEGIN_LINE, COMMENT_END_LINE)
+	commentedCode = comm.NormalizeCode(commentedCode)
+
+	r := &CommentReportT{
+		Path:              me.relativeFile,
+		ModelUsageMetrics: metrics,
+		Level:             commentArgs.Level,
+		OriginalCode:      code,
+		CommentedCode:     code,
+	}
+
+	if len(commentedCode) == 0 || commentedCode == code {
+		return r
+	}
+
+	r.HasChange = true
+	r.CommentedCode = commentedCode
+
+	// guards against any change other than comments
+	if !comm.OnlyCommentsChanged(me.file, code, commentedCode) {
+		r.Rejected = true
+	}
+
+	return r
+}
@@ -0,0 +1,76 @@
+package batchai
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+type CommentLevel string
+
+const (
+	COMMENT_LEVEL_SIMPLE   CommentLevel = "simple"
+	COMMENT_LEVEL_DETAILED CommentLevel = "detailed"
+)
+
+func ParseCommentLevel(level string) (CommentLevel, error) {
+	switch CommentLevel(level) {
+	case COMMENT_LEVEL_SIMPLE, COMMENT_LEVEL_DETAILED:
+		return CommentLevel(level), nil
+	}
+	return "", fmt.Errorf("invalid comment level: '%s', should be either '%s' or '%s'", level, COMMENT_LEVEL_SIMPLE, COMMENT_LEVEL_DETAILED)
+}
+
+type CommentArgsT struct {
+	Level       CommentLevel
+	MissingOnly bool
+}
+
+type CommentArgs = *CommentArgsT
+
+func (me CommentArgs) WithCliContext(x Kontext, cliContext *cli.Context) error {
+	level, err := ParseCommentLevel(cliContext.String("level"))
+	if err != nil {
+		return err
+	}
+	me.Level = level
+	me.MissingOnly = cliContext.Bool("missing-only")
+	return nil
+}
+
+func CommentUrfaveCommand(x Kontext) *cli.Command {
+	return &cli.Command{
+		Name:  "comment",
+		Usage: "Adds or updates doc comments for exported symbols in the code",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "level", Value: string(COMMENT_LEVEL_SIMPLE), Usage: "Level of detail (detailed, simple)"},
+			&cli.BoolFlag{Name: "missing-only", DefaultText: "false", Usage: "Only comments the symbols that have no comments yet"},
+		},
+		Action: CommentFunc(x),
+	}
+}
+
+func CommentFunc(x Kontext) func(*cli.Context) error {
+	return func(cliContext *cli.Context) error {
+		x.Config.Init("comment")
+
+		a := &AppArgsT{}
+		if err := a.WithCliContext(x, cliContext); err != nil {
+			return err
+		}
+		x.Args = a
+
+		ca := &CommentArgsT{}
+		if err := ca.WithCliContext(x, cliContext); err != nil {
+			return err
+		}
+
+		if err := a.EnsureNoUnstagedFiles(x, "comment"); err != nil {
+			return err
+		}
+
+		NewCommentCommand(x).Comment(x, ca)
+
+		return nil
+	}
+}
@@ -0,0 +1,73 @@
+package batchai
+
+import (
+	"github.com/qiangyt/batchai/comm"
+)
+
+type CommentCommandT struct {
+	BaseModelCommandT
+	reportManager CommentReportManager
+}
+
+type CommentCommand = *CommentCommandT
+
+func NewCommentCommand(x Kontext) CommentCommand {
+	return &CommentCommandT{
+		BaseModelCommandT: *NewBaseModelCommand(x),
+		reportManager:     NewCommentReportManager(),
+	}
+}
+
+func (me CommentCommand) launchCommentAgents(x Kontext, commentArgs CommentArgs, targetFiles []string, metrics CommentMetrics) {
+	// launch comment agents and wait for them
+	resultChan := make(chan CommentResult, len(targetFiles))
+
+	for _, f := range targetFiles {
+		metrics.Processed++
+
+		agent := NewCommentAgent(me.reportManager, me.codeFileManager, me.symbolManager, me.modelService, f)
+		agent.Run(x, commentArgs, resultChan, me.workerPool)
+	}
+
+	me.workerPool.Wait()
+	close(resultChan)
+
+	for r := range resultChan {
+		if r.Failed {
+			metrics.Failed++
+		} else if r.Skipped {
+			metrics.Skipped++
+		} else {
+			metrics.Succeeded++
+
+			report := r.Report
+			metrics.ModelUsageMetricsT.IncreaseUsage(report.ModelUsageMetrics)
+
+			if report.Rejected {
+				metrics.Rejected++
+			} else if report.HasChange {
+				metrics.Commented++
+			}
+		}
+	}
+}
+
+func (me CommentCommand) Comment(x Kontext, commentArgs CommentArgs) {
+	metrics := NewCommentMetrics()
+	c := comm.NewConsole(!x.Args.Concurrent)
+
+	c.NewLine().Default("comment command uses model ").Yellowf("'%s'\n\n", x.Config.Comment.ModelId)
+
+	targetFiles, ignored, failed, repoFiles := me.listCommand.CollectWorkingFiles(x, c)
+	metrics.WithWorkingFiles(targetFiles, ignored, failed)
+	if len(targetFiles) > 0 {
+		if x.Args.EnableSymbolReference {
+			me.launchSymbolAgents(x, repoFiles)
+		}
+		me.launchCommentAgents(x, commentArgs, targetFiles, metrics)
+	}
+
+	c.NewLine()
+	metrics.Print(c)
+	WriteMetricsJson(x, c, metrics)
+}
@@ -0,0 +1,39 @@
+package batchai
+
+import (
+	"fmt"
+)
+
+type CommentConfigT struct {
+	AppConfig AppConfig
+	ModelId   string        `mapstructure:"model_id"`
+	Prompt    CommentPrompt `mapstructure:"prompt"`
+	Includes  []string      `mapstructure:"includes"`
+}
+
+type CommentConfig = *CommentConfigT
+
+func (me CommentConfig) Init(config AppConfig) {
+	me.AppConfig = config
+
+	model := config.LoadModel(me.ModelId)
+
+	if me.Prompt == nil {
+		if model.CommentPrompt == nil {
+			panic(fmt.Errorf("missing code comment prompt for model: %s", me.ModelId))
+		}
+		me.Prompt = model.CommentPrompt
+	} else {
+		me.Prompt.Init(config)
+	}
+}
+
+func (me CommentConfig) RenderPrompt(level CommentLevel, missingOnly bool, codeToComment string, codeFile string) string {
+	vars := NewCommentPromptVariables().
+		WithLevel(level).
+		WithMissingOnly(missingOnly).
+		WithPath(codeFile).
+		WithLang(me.AppConfig.Lang).
+		WithCodeToComment(codeToComment)
+	return me.Prompt.Generate(vars)
+}
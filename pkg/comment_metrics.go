@@ -0,0 +1,34 @@
+package batchai
+
+import (
+	"github.com/qiangyt/batchai/comm"
+)
+
+type CommentMetricsT struct {
+	BaseMetricsT
+
+	Commented int `json:"commented"`
+	Rejected  int `json:"rejected"`
+}
+
+type CommentMetrics = *CommentMetricsT
+
+func NewCommentMetrics() CommentMetrics {
+	return &CommentMetricsT{
+		BaseMetricsT: *NewBaseMetrics(),
+	}
+}
+
+func (me CommentMetrics) Print(console comm.Console) {
+	me.PreparePrint(console)
+
+	console.NewLine().Greenf("Files: %d, Processed: %d, Ignored: %d, Failed: %d, Commented: %d, Rejected: %d, Skipped: %d",
+		me.Files,
+		me.Processed,
+		me.Ignored,
+		me.Failed,
+		me.Commented,
+		me.Rejected,
+		me.Skipped,
+	)
+}
@@ -0,0 +1,84 @@
+package batchai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+type CommentPromptVariablesT struct {
+	Data map[string]any
+}
+
+type CommentPromptVariables = *CommentPromptVariablesT
+
+const (
+	COMMENT_BEGIN      = "!!!!comment_begin!!!!"
+	COMMENT_BEGIN_LINE = COMMENT_BEGIN + "\n"
+	COMMENT_END        = "!!!!comment_end!!!!"
+	COMMENT_END_LINE   = COMMENT_END
+)
+
+func NewCommentPromptVariables() CommentPromptVariables {
+	return &CommentPromptVariablesT{Data: map[string]any{
+		"comment_begin": COMMENT_BEGIN,
+		"comment_end":   COMMENT_END,
+	}}
+}
+
+func (me CommentPromptVariables) WithLevel(level CommentLevel) CommentPromptVariables {
+	if len(level) == 0 {
+		level = COMMENT_LEVEL_SIMPLE
+	}
+	me.Data["level"] = string(level)
+	me.Data["detailed"] = (level == COMMENT_LEVEL_DETAILED)
+	return me
+}
+
+func (me CommentPromptVariables) WithMissingOnly(missingOnly bool) CommentPromptVariables {
+	me.Data["missing_only"] = missingOnly
+	return me
+}
+
+func (me CommentPromptVariables) WithCodeToComment(codeToComment string) CommentPromptVariables {
+	me.Data["code_to_comment"] = codeToComment
+	return me
+}
+
+func (me CommentPromptVariables) WithLang(lang string) CommentPromptVariables {
+	me.Data["lang"] = lang
+	return me
+}
+
+func (me CommentPromptVariables) WithPath(path string) CommentPromptVariables {
+	me.Data["path"] = path
+	return me
+}
+
+type CommentPromptT struct {
+	Rules    []string `mapstructure:"rules"`
+	Template string   `mapstructure:"template"`
+}
+
+type CommentPrompt = *CommentPromptT
+
+func (me CommentPrompt) Init(config AppConfig) {
+	me.Rules = comm.StringArrayTrimSpace(me.Rules)
+	for i, rule := range me.Rules {
+		me.Rules[i] = fmt.Sprintf("## %s\n", rule)
+	}
+
+	me.Template = strings.TrimSpace(me.Template)
+}
+
+func (me CommentPrompt) Generate(vars CommentPromptVariables) string {
+	data := vars.Data
+
+	rules := comm.RenderAsTemplateArrayP(me.Rules, data)
+	if len(rules) > 0 {
+		data["comment_rules"] = strings.Join(rules, "\n")
+	}
+
+	return comm.RenderAsTemplateP(me.Template, data)
+}
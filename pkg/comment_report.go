@@ -0,0 +1,37 @@
+package batchai
+
+import (
+	"github.com/qiangyt/batchai/comm"
+)
+
+type CommentReportT struct {
+	Path              string            `json:"path"`
+	ModelUsageMetrics ModelUsageMetrics `json:"model_usage_metrics"`
+
+	Level         CommentLevel `json:"level"`
+	HasChange     bool         `json:"has_change"`
+	Rejected      bool         `json:"rejected"`
+	OriginalCode  string       `json:"original_code"`
+	CommentedCode string       `json:"commented_code"`
+}
+
+type CommentReport = *CommentReportT
+
+func (me CommentReport) Print(console comm.Console) {
+	me.ModelUsageMetrics.Print(console, comm.DEFAULT_COLOR)
+
+	if me.Rejected {
+		console.NewLine().Yellow("rejected: ").Default("not only comments are changed")
+		return
+	}
+
+	if !me.HasChange {
+		console.NewLine().Print("no comment to add or update")
+		return
+	}
+
+	console.NewLine().Print("Comment:")
+	console.NewLine()
+
+	PrintCodeDiff(console.NewIndented(), "original", "commented", me.OriginalCode, me.CommentedCode)
+}
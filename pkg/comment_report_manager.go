@@ -0,0 +1,65 @@
+package batchai
+
+import (
+	"path"
+	"sync"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+type CommentReportManagerT struct {
+	reportsMapByFile map[string]CommentReport
+	lock             sync.Mutex
+}
+
+type CommentReportManager = *CommentReportManagerT
+
+func NewCommentReportManager() CommentReportManager {
+	return &CommentReportManagerT{
+		reportsMapByFile: map[string]CommentReport{},
+	}
+}
+
+func (me CommentReportManager) LoadReport(x Kontext, file string) CommentReport {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+
+	r, has := me.reportsMapByFile[file]
+	if has {
+		return r
+	}
+
+	reportFile := ResolveCommentReportFile(x.Config.CacheDir, x.Args.Repository, file)
+
+	r = &CommentReportT{}
+	if err := comm.FromJsonFile(x.Fs, reportFile, false, r); err != nil {
+		return nil
+	}
+
+	me.reportsMapByFile[file] = r
+
+	return r
+}
+
+func (me CommentReportManager) SaveReport(x Kontext, file string, report CommentReport) string {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+
+	me.reportsMapByFile[file] = report
+
+	reportText := comm.ToJsonP(report, true)
+
+	reportFile := ResolveCommentReportFile(x.Config.CacheDir, x.Args.Repository, file)
+
+	comm.Mkdir(x.Fs, path.Dir(reportFile))
+	comm.WriteFileText(x.Fs, reportFile, reportText)
+
+	return reportFile
+}
+
+func ResolveCommentReportFile(cacheDir string, repository string, file string) string {
+	// the file is relative to working directory, so take the relative path
+	relativePath := file[len(repository):]
+	repoName := path.Base(repository)
+	return path.Join(cacheDir, repoName, relativePath+".comment.batchai.json")
+}
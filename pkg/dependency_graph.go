@@ -0,0 +1,300 @@
+package batchai
+
+import (
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/qiangyt/batchai/comm"
+	"github.com/spf13/afero"
+)
+
+var (
+	javaImportPattern = regexp.MustCompile(`(?m)^\s*import\s+(static\s+)?([\w.]+?)(\.\*)?\s*;`)
+
+	pythonImportPattern     = regexp.MustCompile(`(?m)^[ \t]*import[ \t]+([^#\n]+)`)
+	pythonFromImportPattern = regexp.MustCompile(`(?m)^[ \t]*from[ \t]+(\.*)([\w.]*)[ \t]+import[ \t]+([^#\n]+)`)
+
+	// the names are matched across lines, e.g. `import {\n a,\n b\n} from './x'`
+	scriptFromPattern    = regexp.MustCompile(`(?m)^\s*(?:import|export)\s[^;'"]*?from\s*['"]([^'"]+)['"]`)
+	scriptImportPattern  = regexp.MustCompile(`(?m)^\s*import\s*['"]([^'"]+)['"]`)
+	scriptRequirePattern = regexp.MustCompile(`\b(?:require|import)\s*\(\s*['"]([^'"]+)['"]\s*\)`)
+)
+
+var scriptExtensions = []string{".ts", ".tsx", ".d.ts", ".mts", ".cts", ".js", ".jsx", ".mjs", ".cjs"}
+
+// DependencyGraphT resolves the imports of a file to the files in the repository, i.e. the direct dependencies.
+// The imports of Go are parsed by go/parser, and the imports of Java, Python, TypeScript and JavaScript are matched by regex.
+type DependencyGraphT struct {
+	fs         afero.Fs
+	repository string
+
+	files       map[string]bool
+	filesByBase map[string][]string
+	filesByDir  map[string][]string
+
+	// the Go import paths by the directories
+	goPackages map[string]string
+	// the resolved dependencies by the files
+	dependencies map[string][]string
+	lock         sync.Mutex
+}
+
+type DependencyGraph = *DependencyGraphT
+
+func NewDependencyGraph(fs afero.Fs, repository string, files []string) DependencyGraph {
+	r := &DependencyGraphT{
+		fs:           fs,
+		repository:   repository,
+		files:        map[string]bool{},
+		filesByBase:  map[string][]string{},
+		filesByDir:   map[string][]string{},
+		goPackages:   map[string]string{},
+		dependencies: map[string][]string{},
+	}
+
+	for _, f := range files {
+		r.files[f] = true
+		r.filesByBase[path.Base(f)] = append(r.filesByBase[path.Base(f)], f)
+		r.filesByDir[path.Dir(f)] = append(r.filesByDir[path.Dir(f)], f)
+	}
+	return r
+}
+
+// Dependencies returns the files in the repository which the file directly depends on, in the order of imports.
+// For Go and Java, the other files of same package are included as well, because they're visible without import.
+func (me DependencyGraph) Dependencies(file string) []string {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+
+	if r, has := me.dependencies[file]; has {
+		return r
+	}
+
+	var dependencies []string
+	if comm.FileExistsP(me.fs, file) {
+		code := comm.ReadFileTextP(me.fs, file)
+
+		switch ext := path.Ext(file); {
+		case ext == ".go":
+			dependencies = me.goDependencies(file, code)
+		case ext == ".java":
+			dependencies = me.javaDependencies(file, code)
+		case ext == ".py":
+			dependencies = me.pythonDependencies(file, code)
+		case slices.Contains(scriptExtensions, ext):
+			dependencies = me.scriptDependencies(file, code)
+		}
+	}
+
+	// de-duplicated, and excludes the file itself
+	r := []string{}
+	found := map[string]bool{file: true}
+	for _, d := range dependencies {
+		if !found[d] {
+			found[d] = true
+			r = append(r, d)
+		}
+	}
+
+	me.dependencies[file] = r
+	return r
+}
+
+// samePackageFiles returns the other files of the extension in the directory, excluding Go test files
+func (me DependencyGraph) samePackageFiles(dir string, ext string) []string {
+	r := []string{}
+	for _, f := range me.filesByDir[dir] {
+		if path.Ext(f) == ext && !strings.HasSuffix(f, "_test.go") {
+			r = append(r, f)
+		}
+	}
+	return r
+}
+
+func (me DependencyGraph) goPackage(dir string) string {
+	r, has := me.goPackages[dir]
+	if !has {
+		r = GoPackagePath(me.fs, me.repository, dir)
+		me.goPackages[dir] = r
+	}
+	return r
+}
+
+func (me DependencyGraph) goDependencies(file string, code string) []string {
+	f, _ := parser.ParseFile(token.NewFileSet(), file, code, parser.ImportsOnly)
+	if f == nil {
+		return nil
+	}
+
+	r := []string{}
+	for _, spec := range f.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+
+		// the directory is usually named by the last element of import path, excluding the major version suffix
+		bases := []string{path.Base(importPath)}
+		if goImportVersionSuffix.MatchString(bases[0]) {
+			bases = append(bases, path.Base(path.Dir(importPath)))
+		}
+
+		for dir := range me.filesByDir {
+			if slices.Contains(bases, path.Base(dir)) && me.goPackage(dir) == importPath {
+				r = append(r, me.samePackageFiles(dir, ".go")...)
+			}
+		}
+	}
+
+	return append(r, me.samePackageFiles(path.Dir(file), ".go")...)
+}
+
+// filesBySuffix returns the files of which the path ends with the suffix, e.g. `com/foo/Bar.java`
+func (me DependencyGraph) filesBySuffix(suffix string) []string {
+	r := []string{}
+	for _, f := range me.filesByBase[path.Base(suffix)] {
+		if strings.HasSuffix(f, "/"+suffix) {
+			r = append(r, f)
+		}
+	}
+	return r
+}
+
+func (me DependencyGraph) javaDependencies(file string, code string) []string {
+	r := []string{}
+
+	for _, m := range javaImportPattern.FindAllStringSubmatch(code, -1) {
+		parts := strings.Split(m[2], ".")
+		wildcard := len(m[3]) > 0
+
+		// the imported name may be a nested class or a static member, so drops the last parts until a class file found
+		found := []string{}
+		for n := len(parts); n >= 1 && len(found) == 0; n-- {
+			found = me.filesBySuffix(strings.Join(parts[:n], "/") + ".java")
+		}
+
+		// `import com.foo.*;`
+		if len(found) == 0 && wildcard {
+			suffix := "/" + strings.Join(parts, "/")
+			for dir := range me.filesByDir {
+				if strings.HasSuffix(dir, suffix) {
+					found = append(found, me.samePackageFiles(dir, ".java")...)
+				}
+			}
+		}
+
+		r = append(r, found...)
+	}
+
+	return append(r, me.samePackageFiles(path.Dir(file), ".java")...)
+}
+
+// pythonModuleFiles returns the files of the module, either `foo/bar.py` or `foo/bar/__init__.py`
+func (me DependencyGraph) pythonModuleFiles(baseDir string, module string) []string {
+	modulePath := strings.ReplaceAll(module, ".", "/")
+
+	if len(baseDir) > 0 {
+		// relative import
+		r := []string{}
+		for _, candidate := range []string{path.Join(baseDir, modulePath+".py"), path.Join(baseDir, modulePath, "__init__.py")} {
+			if me.files[candidate] {
+				r = append(r, candidate)
+			}
+		}
+		return r
+	}
+
+	if len(modulePath) == 0 {
+		return nil
+	}
+	return append(me.filesBySuffix(modulePath+".py"), me.filesBySuffix(modulePath+"/__init__.py")...)
+}
+
+func pythonImportedNames(text string) []string {
+	text = strings.NewReplacer("(", " ", ")", " ", "\\", " ").Replace(text)
+
+	r := []string{}
+	for _, name := range strings.Split(text, ",") {
+		// `foo as bar`
+		if fields := strings.Fields(name); len(fields) > 0 && fields[0] != "*" {
+			r = append(r, fields[0])
+		}
+	}
+	return r
+}
+
+func (me DependencyGraph) pythonDependencies(file string, code string) []string {
+	r := []string{}
+
+	for _, m := range pythonImportPattern.FindAllStringSubmatch(code, -1) {
+		for _, module := range pythonImportedNames(m[1]) {
+			r = append(r, me.pythonModuleFiles("", module)...)
+		}
+	}
+
+	for _, m := range pythonFromImportPattern.FindAllStringSubmatch(code, -1) {
+		dots, module := m[1], m[2]
+
+		baseDir := ""
+		if len(dots) > 0 {
+			baseDir = path.Dir(file)
+			for i := 1; i < len(dots); i++ {
+				baseDir = path.Dir(baseDir)
+			}
+		}
+
+		for _, name := range pythonImportedNames(m[3]) {
+			// the imported name is either a sub module or a symbol of the module
+			subModule := name
+			if len(module) > 0 {
+				subModule = module + "." + name
+			}
+			found := me.pythonModuleFiles(baseDir, subModule)
+			if len(found) == 0 {
+				found = me.pythonModuleFiles(baseDir, module)
+			}
+			r = append(r, found...)
+		}
+	}
+
+	return r
+}
+
+// scriptDependencies resolves the relative imports only, the others are from the packages
+func (me DependencyGraph) scriptDependencies(file string, code string) []string {
+	r := []string{}
+
+	for _, pattern := range []*regexp.Regexp{scriptFromPattern, scriptImportPattern, scriptRequirePattern} {
+		for _, m := range pattern.FindAllStringSubmatch(code, -1) {
+			spec := m[1]
+			if !strings.HasPrefix(spec, "./") && !strings.HasPrefix(spec, "../") {
+				continue
+			}
+
+			target := path.Join(path.Dir(file), spec)
+			candidates := []string{target}
+			// `import './foo.js'` refers to `foo.ts` in ESM TypeScript
+			if ext := path.Ext(target); ext == ".js" || ext == ".mjs" || ext == ".cjs" {
+				candidates = append(candidates, strings.TrimSuffix(target, ext)+".ts", strings.TrimSuffix(target, ext)+".tsx")
+			}
+			for _, ext := range scriptExtensions {
+				candidates = append(candidates, target+ext)
+			}
+			for _, ext := range scriptExtensions {
+				candidates = append(candidates, target+"/index"+ext)
+			}
+
+			for _, candidate := range candidates {
+				if me.files[candidate] {
+					r = append(r, candidate)
+					break
+				}
+			}
+		}
+	}
+
+	return r
+}
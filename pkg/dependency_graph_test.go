@@ -0,0 +1,185 @@
+package batchai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+// writeTestFiles writes the files of the code by the paths, and returns the paths
+func writeTestFiles(x Kontext, files map[string]string) []string {
+	r := []string{}
+	for f, code := range files {
+		comm.WriteFileTextP(x.Fs, f, code)
+		r = append(r, f)
+	}
+	return r
+}
+
+func TestGoDependencies(t *testing.T) {
+	a := require.New(t)
+
+	x := newSymbolTestKontext()
+	files := writeTestFiles(x, map[string]string{
+		"/repo/go.mod":             "module example.com/demo\n",
+		"/repo/main.go":            "package main\n\nimport (\n\t\"fmt\"\n\tcalc \"example.com/demo/pkg/calc\"\n)\n",
+		"/repo/util.go":            "package main\n",
+		"/repo/main_test.go":       "package main\n",
+		"/repo/pkg/calc/calc.go":   "package calc\n",
+		"/repo/pkg/calc/op.go":     "package calc\n",
+		"/repo/pkg/calc/README.md": "",
+		"/repo/other/calc/calc.go": "package calc\n",
+	})
+	graph := NewDependencyGraph(x.Fs, "/repo", files)
+
+	a.ElementsMatch([]string{"/repo/pkg/calc/calc.go", "/repo/pkg/calc/op.go", "/repo/util.go"}, graph.Dependencies("/repo/main.go"))
+	a.Equal([]string{"/repo/pkg/calc/op.go"}, graph.Dependencies("/repo/pkg/calc/calc.go"))
+}
+
+func TestJavaDependencies(t *testing.T) {
+	a := require.New(t)
+
+	x := newSymbolTestKontext()
+	files := writeTestFiles(x, map[string]string{
+		"/repo/src/com/demo/App.java": `package com.demo;
+
+import java.util.List;
+import com.demo.calc.Calc;
+import com.demo.calc.Calc.Inner;
+import static com.demo.util.Strings.trim;
+import com.demo.model.*;
+`,
+		"/repo/src/com/demo/Config.java":       "",
+		"/repo/src/com/demo/calc/Calc.java":    "",
+		"/repo/src/com/demo/util/Strings.java": "",
+		"/repo/src/com/demo/model/User.java":   "",
+		"/repo/src/com/demo/model/Role.java":   "",
+	})
+	graph := NewDependencyGraph(x.Fs, "/repo", files)
+
+	deps := graph.Dependencies("/repo/src/com/demo/App.java")
+	a.Equal([]string{"/repo/src/com/demo/calc/Calc.java", "/repo/src/com/demo/util/Strings.java"}, deps[:2])
+	a.ElementsMatch([]string{
+		"/repo/src/com/demo/calc/Calc.java",
+		"/repo/src/com/demo/util/Strings.java",
+		"/repo/src/com/demo/model/User.java",
+		"/repo/src/com/demo/model/Role.java",
+		"/repo/src/com/demo/Config.java",
+	}, deps)
+}
+
+func TestPythonDependencies(t *testing.T) {
+	a := require.New(t)
+
+	x := newSymbolTestKontext()
+	files := writeTestFiles(x, map[string]string{
+		"/repo/app/main.py": `import os, app.config as config
+from app.models import User, Role
+from . import util
+from ..lib.calc import (add,
+    sub)
+`,
+		"/repo/app/config.py":          "",
+		"/repo/app/models/__init__.py": "",
+		"/repo/app/util.py":            "",
+		"/repo/lib/calc.py":            "",
+	})
+	graph := NewDependencyGraph(x.Fs, "/repo", files)
+
+	a.Equal([]string{
+		"/repo/app/config.py",
+		"/repo/app/models/__init__.py",
+		"/repo/app/util.py",
+		"/repo/lib/calc.py",
+	}, graph.Dependencies("/repo/app/main.py"))
+}
+
+func TestScriptDependencies(t *testing.T) {
+	a := require.New(t)
+
+	x := newSymbolTestKontext()
+	files := writeTestFiles(x, map[string]string{
+		"/repo/src/app.ts": `import React from 'react';
+import {
+  Shape,
+  Circle,
+} from './shape';
+import type { Point } from "../types/point.js";
+import './polyfill';
+export * from './widgets';
+const util = require('./util');
+`,
+		"/repo/src/shape.ts":          "",
+		"/repo/types/point.ts":        "",
+		"/repo/src/polyfill.js":       "",
+		"/repo/src/widgets/index.tsx": "",
+		"/repo/src/util.js":           "",
+	})
+	graph := NewDependencyGraph(x.Fs, "/repo", files)
+
+	a.Equal([]string{
+		"/repo/src/shape.ts",
+		"/repo/types/point.ts",
+		"/repo/src/widgets/index.tsx",
+		"/repo/src/polyfill.js",
+		"/repo/src/util.js",
+	}, graph.Dependencies("/repo/src/app.ts"))
+}
+
+func TestProvideSymbols(t *testing.T) {
+	a := require.New(t)
+
+	code := "package main\n\nimport \"example.com/demo/calc\"\n\nfunc main() {\n\tc := calc.NewCalc()\n\tc.Reset()\n\tc.Add(1)\n}\n"
+	x := newSymbolTestKontext()
+	files := writeTestFiles(x, map[string]string{
+		"/repo/go.mod":       "module example.com/demo\n",
+		"/repo/main.go":      code,
+		"/repo/calc/calc.go": "package calc\n",
+		"/repo/other/sub.go": "package other\n",
+	})
+	x.Args.Verbose = true
+
+	m := NewSymbolManager()
+	m.LoadAll(x, files)
+	m.register("/repo/calc/calc.go", []Symbol{
+		{Name: "example.com/demo/calc.NewCalc", Path: "/repo/calc/calc.go", LineBegin: 3, Signature: "func NewCalc() *Calc"},
+		{Name: "example.com/demo/calc.Calc.Add", Path: "/repo/calc/calc.go", LineBegin: 5, Signature: "func (me *Calc) Add(n int)"},
+		{Name: "example.com/demo/calc.Calc.Sub", Path: "/repo/calc/calc.go", LineBegin: 7, Signature: "func (me *Calc) Sub(n int)"},
+		{Name: "example.com/demo/calc.Calc.Reset", Path: "/repo/calc/calc.go", LineBegin: 9, Signature: "func (me *Calc) Reset()"},
+	})
+	m.register("/repo/other/sub.go", []Symbol{
+		{Name: "example.com/demo/other.Add", Path: "/repo/other/sub.go", Signature: "func Add(n int)"},
+	})
+
+	// the qualified names first, then the ones of the imported files
+	a.Equal([]string{
+		"/repo/calc/calc.go example.com/demo/calc.NewCalc",
+		"/repo/calc/calc.go example.com/demo/calc.Calc.Add",
+		"/repo/calc/calc.go example.com/demo/calc.Calc.Reset",
+		"/repo/other/sub.go example.com/demo/other.Add",
+	}, lookupNames(m.Lookup(x, referredSymbols(code), "/repo/main.go")))
+
+	model := newTestModelConfig(MODEL_PROVIDER_OPENAI, "http://localhost/")
+	agent := &SymbolAwareAgentT{
+		BaseAgentT:    newBaseAgent(NewModelService(&AppConfigT{Models: []ModelConfig{model}})),
+		symbolManager: m,
+	}
+
+	sysPrompt := agent.provideSymbols(x, comm.NewConsole(true), model.Id, "/repo/main.go", code, "check the code")
+	a.True(strings.HasPrefix(sysPrompt, "check the code\n\n"))
+	// neutral to all agents, e.g. the test agent has nothing to report
+	a.Contains(sysPrompt, "Use these definitions as references:\n")
+	a.NotContains(sysPrompt, "issue")
+	a.Contains(sysPrompt, "// example.com/demo/calc.NewCalc, in calc/calc.go:3\nfunc NewCalc() *Calc")
+	a.Contains(sysPrompt, "func (me *Calc) Add(n int)")
+	a.Less(strings.Index(sysPrompt, "NewCalc"), strings.Index(sysPrompt, "Reset"))
+	// neither referred nor imported
+	a.NotContains(sysPrompt, "Sub")
+	a.NotContains(sysPrompt, "other.Add")
+
+	// nothing to attach
+	a.Equal("check the code", agent.provideSymbols(x, comm.NewConsole(true), model.Id, "/repo/other/sub.go", "package other\n", "check the code"))
+}
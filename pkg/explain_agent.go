@@ -0,0 +1,145 @@
+package batchai
+
+import (
+	"strings"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+type ExplainResultT struct {
+	Report  ExplainReport
+	Skipped bool
+	Failed  bool
+	Inlined bool
+}
+
+type ExplainResult = *ExplainResultT
+
+type ExplainAgentT struct {
+	SymbolAwareAgentT
+
+	reportManager ExplainReportManager
+
+	file         string
+	relativeFile string
+}
+
+type ExplainAgent = *ExplainAgentT
+
+func NewExplainAgent(reportManager ExplainReportManager,
+	symbolManager SymbolManager,
+	modelService ModelService,
+	codeFile string,
+) ExplainAgent {
+	return &ExplainAgentT{
+		SymbolAwareAgentT: newSymbolAwareAgent(symbolManager, modelService),
+		reportManager:     reportManager,
+
+		file: codeFile,
+	}
+}
+
+func (me ExplainAgent) run(x Kontext, explainArgs ExplainArgs, resultChan chan<- ExplainResult, output func(text string)) {
+	c := comm.NewConsole(!x.Args.Concurrent).WithOutput(output)
+	me.relativeFile = me.file[len(x.Args.Repository)+1:]
+
+	c.Begin()
+	defer c.End()
+	c.Greenf("\n\n▹▹▹▹▹ processing: %s\n", me.relativeFile)
+
+	defer func() {
+		if e := recover(); e != nil {
+			c.NewLine().Red("failed: ").Defaultf("%v, %+v", me.relativeFile, e)
+			resultChan <- &ExplainResultT{Failed: true}
+		}
+	}()
+
+	result := me.explainFile(x, explainArgs, c)
+
+	resultChan <- result
+}
+
+func (me ExplainAgent) Run(x Kontext, explainArgs ExplainArgs, resultChan chan<- ExplainResult, pool comm.WorkerPool) {
+	if !x.Args.Concurrent {
+		me.run(x, explainArgs, resultChan, nil)
+		return
+	}
+
+	pool.Submit(func(output func(text string)) {
+		me.run(x, explainArgs, resultChan, output)
+	})
+}
+
+func (me ExplainAgent) explainFile(x Kontext, explainArgs ExplainArgs, c comm.Console) ExplainResult {
+	c.NewLine().Green("--------------------")
+	c.NewLine().Greenln(me.relativeFile)
+
+	newCode := comm.ReadFileCodeP(x.Fs, me.file)
+
+	lastReport := me.reportManager.LoadReport(x, me.file)
+	if lastReport != nil {
+		noCodeChanges := (newCode == lastReport.OriginalCode)
+		alreadyInlined := len(lastReport.InlinedCode) > 0 && (newCode == lastReport.InlinedCode)
+		if noCodeChanges || alreadyInlined {
+			if !x.Args.Force {
+				if !explainArgs.Inline || alreadyInlined {
+					c.NewLine().Default("✔ no code changes since last execution, skipped")
+					return &ExplainResultT{Report: lastReport, Skipped: true}
+				}
+			}
+		}
+	}
+
+	// the stale explanation inlined by last execution is not sent to the model
+	newReport := me.explainCode(x, c, RemoveInlinedExplanation(me.file, newCode))
+	newReport.OriginalCode = newCode
+	newReport.Print(c)
+
+	inlined := false
+	if explainArgs.Inline {
+		if inlinedCode, ok := newReport.Inline(newCode); ok {
+			newReport.InlinedCode = inlinedCode
+			comm.WriteFileTextP(x.Fs, me.file, inlinedCode)
+			inlined = true
+		} else {
+			c.NewLine().Yellow("unable to inline: ").Default("unknown comment syntax")
+		}
+	}
+
+	reportFile := me.reportManager.SaveReport(x, me.file, newReport)
+	c.NewLine().Blue("✔ report: ").Default(reportFile[len(x.Args.Repository)+1:])
+
+	return &ExplainResultT{Report: newReport, Skipped: false, Inlined: inlined}
+}
+
+func (me ExplainAgent) explainCode(x Kontext, c comm.Console, code string) ExplainReport {
+	verbose := x.Args.Verbose
+
+	sysPrompt := x.Config.Explain.RenderPrompt(code, me.relativeFile)
+	if x.Args.EnableSymbolReference {
+		sysPrompt = me.provideSymbols(x, c, x.Config.Explain.ModelId, me.file, code, sysPrompt)
+	}
+	mem := me.memory
+	mem.AddSystemMessage(sysPrompt)
+
+	if x.Args.EnableSymbolReference {
+		mem.AddUserMessage("explain the code, with provided symbols as references")
+	} else {
+		mem.AddUserMessage("explain the code")
+	}
+	if verbose {
+		c.NewLine().Gray("chat: ").Default("explain the code")
+	}
+
+	answer, metrics := me.modelService.Chat(x, c, x.Config.Explain.ModelId, true, mem, nil)
+	if verbose {
+		c.NewLine().Gray("answer: ").Default(mem.Format())
+	}
+
+	r := ExtractExplainReport(answer, strings.HasSuffix(me.relativeFile, ".go"))
+	r.ModelUsageMetrics = metrics
+	r.OriginalCode = code
+	r.Path = me.relativeFile
+
+	return r
+}
@@ -0,0 +1,51 @@
+package batchai
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+)
+
+type ExplainArgsT struct {
+	Inline bool
+}
+
+type ExplainArgs = *ExplainArgsT
+
+func (me ExplainArgs) WithCliContext(x Kontext, cliContext *cli.Context) error {
+	me.Inline = cliContext.Bool("inline")
+	return nil
+}
+
+func ExplainUrfaveCommand(x Kontext) *cli.Command {
+	return &cli.Command{
+		Name:  "explain",
+		Usage: fmt.Sprintf("Explains the code. Explanation is outputed to console and also saved to '%s'", os.Getenv("BATCHAI_CACHE_DIR")),
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "inline", DefaultText: "false", Usage: "Writes the explanation into the target files as doc comment"},
+		},
+		Action: ExplainFunc(x),
+	}
+}
+
+func ExplainFunc(x Kontext) func(*cli.Context) error {
+	return func(cliContext *cli.Context) error {
+		x.Config.Init("explain")
+
+		a := &AppArgsT{}
+		if err := a.WithCliContext(x, cliContext); err != nil {
+			return err
+		}
+		x.Args = a
+
+		ea := &ExplainArgsT{}
+		if err := ea.WithCliContext(x, cliContext); err != nil {
+			return err
+		}
+
+		NewExplainCommand(x).Explain(x, ea)
+
+		return nil
+	}
+}
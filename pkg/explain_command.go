@@ -0,0 +1,71 @@
+package batchai
+
+import (
+	"github.com/qiangyt/batchai/comm"
+)
+
+type ExplainCommandT struct {
+	BaseModelCommandT
+	reportManager ExplainReportManager
+}
+
+type ExplainCommand = *ExplainCommandT
+
+func NewExplainCommand(x Kontext) ExplainCommand {
+	return &ExplainCommandT{
+		BaseModelCommandT: *NewBaseModelCommand(x),
+		reportManager:     NewExplainReportManager(),
+	}
+}
+
+func (me ExplainCommand) launchExplainAgents(x Kontext, explainArgs ExplainArgs, targetFiles []string, metrics ExplainMetrics) {
+	// launch explain agents and wait for them
+	resultChan := make(chan ExplainResult, len(targetFiles))
+
+	for _, f := range targetFiles {
+		metrics.Processed++
+
+		agent := NewExplainAgent(me.reportManager, me.symbolManager, me.modelService, f)
+		agent.Run(x, explainArgs, resultChan, me.workerPool)
+	}
+
+	me.workerPool.Wait()
+	close(resultChan)
+
+	for r := range resultChan {
+		if r.Failed {
+			metrics.Failed++
+		} else if r.Skipped {
+			metrics.Skipped++
+		} else {
+			metrics.Succeeded++
+
+			report := r.Report
+			metrics.ModelUsageMetricsT.IncreaseUsage(report.ModelUsageMetrics)
+
+			if r.Inlined {
+				metrics.Inlined++
+			}
+		}
+	}
+}
+
+func (me ExplainCommand) Explain(x Kontext, explainArgs ExplainArgs) {
+	metrics := NewExplainMetrics()
+	c := comm.NewConsole(!x.Args.Concurrent)
+
+	c.NewLine().Default("explain command uses model ").Yellowf("'%s'\n\n", x.Config.Explain.ModelId)
+
+	targetFiles, ignored, failed, repoFiles := me.listCommand.CollectWorkingFiles(x, c)
+	metrics.WithWorkingFiles(targetFiles, ignored, failed)
+	if len(targetFiles) > 0 {
+		if x.Args.EnableSymbolReference {
+			me.launchSymbolAgents(x, repoFiles)
+		}
+		me.launchExplainAgents(x, explainArgs, targetFiles, metrics)
+	}
+
+	c.NewLine()
+	metrics.Print(c)
+	WriteMetricsJson(x, c, metrics)
+}
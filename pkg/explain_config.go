@@ -0,0 +1,37 @@
+package batchai
+
+import (
+	"fmt"
+)
+
+type ExplainConfigT struct {
+	AppConfig AppConfig
+	ModelId   string        `mapstructure:"model_id"`
+	Prompt    ExplainPrompt `mapstructure:"prompt"`
+	Includes  []string      `mapstructure:"includes"`
+}
+
+type ExplainConfig = *ExplainConfigT
+
+func (me ExplainConfig) Init(config AppConfig) {
+	me.AppConfig = config
+
+	model := config.LoadModel(me.ModelId)
+
+	if me.Prompt == nil {
+		if model.ExplainPrompt == nil {
+			panic(fmt.Errorf("missing code explain prompt for model: %s", me.ModelId))
+		}
+		me.Prompt = model.ExplainPrompt
+	} else {
+		me.Prompt.Init(config)
+	}
+}
+
+func (me ExplainConfig) RenderPrompt(codeToExplain string, codeFile string) string {
+	vars := NewExplainPromptVariables().
+		WithPath(codeFile).
+		WithLang(me.AppConfig.Lang).
+		WithCodeToExplain(codeToExplain)
+	return me.Prompt.Generate(vars)
+}
@@ -0,0 +1,32 @@
+package batchai
+
+import (
+	"github.com/qiangyt/batchai/comm"
+)
+
+type ExplainMetricsT struct {
+	BaseMetricsT
+
+	Inlined int `json:"inlined"`
+}
+
+type ExplainMetrics = *ExplainMetricsT
+
+func NewExplainMetrics() ExplainMetrics {
+	return &ExplainMetricsT{
+		BaseMetricsT: *NewBaseMetrics(),
+	}
+}
+
+func (me ExplainMetrics) Print(console comm.Console) {
+	me.PreparePrint(console)
+
+	console.NewLine().Greenf("Files: %d, Processed: %d, Ignored: %d, Failed: %d, Inlined: %d, Skipped: %d",
+		me.Files,
+		me.Processed,
+		me.Ignored,
+		me.Failed,
+		me.Inlined,
+		me.Skipped,
+	)
+}
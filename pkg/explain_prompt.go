@@ -0,0 +1,62 @@
+package batchai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+type ExplainPromptVariablesT struct {
+	Data map[string]any
+}
+
+type ExplainPromptVariables = *ExplainPromptVariablesT
+
+func NewExplainPromptVariables() ExplainPromptVariables {
+	return &ExplainPromptVariablesT{Data: map[string]any{
+		"explain_report_json_format": EXPLAIN_REPORT_JSON_FORMAT,
+	}}
+}
+
+func (me ExplainPromptVariables) WithCodeToExplain(codeToExplain string) ExplainPromptVariables {
+	me.Data["code_to_explain"] = codeToExplain
+	return me
+}
+
+func (me ExplainPromptVariables) WithLang(lang string) ExplainPromptVariables {
+	me.Data["lang"] = lang
+	return me
+}
+
+func (me ExplainPromptVariables) WithPath(path string) ExplainPromptVariables {
+	me.Data["path"] = path
+	return me
+}
+
+type ExplainPromptT struct {
+	Rules    []string `mapstructure:"rules"`
+	Template string   `mapstructure:"template"`
+}
+
+type ExplainPrompt = *ExplainPromptT
+
+func (me ExplainPrompt) Init(config AppConfig) {
+	me.Rules = comm.StringArrayTrimSpace(me.Rules)
+	for i, rule := range me.Rules {
+		me.Rules[i] = fmt.Sprintf("## %s\n", rule)
+	}
+
+	me.Template = strings.TrimSpace(me.Template)
+}
+
+func (me ExplainPrompt) Generate(vars ExplainPromptVariables) string {
+	data := vars.Data
+
+	rules := comm.RenderAsTemplateArrayP(me.Rules, data)
+	if len(rules) > 0 {
+		data["explain_rules"] = strings.Join(rules, "\n")
+	}
+
+	return comm.RenderAsTemplateP(me.Template, data)
+}
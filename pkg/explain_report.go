@@ -0,0 +1,123 @@
+package batchai
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/qiangyt/batchai/comm"
+)
+
+const EXPLAIN_REPORT_JSON_FORMAT = `
+{
+  "summary": "...",
+  "explanation": "..."
+}`
+
+// the inlined explanation is enclosed by these markers, so that it's replaced rather than stacked by next execution
+const (
+	EXPLAIN_BEGIN_MARKER = "batchai:explain-begin"
+	EXPLAIN_END_MARKER   = "batchai:explain-end"
+)
+
+type ExplainReportT struct {
+	Path              string            `json:"path"`
+	ModelUsageMetrics ModelUsageMetrics `json:"model_usage_metrics"`
+
+	Summary      string `json:"summary"`
+	Explanation  string `json:"explanation"`
+	OriginalCode string `json:"original_code"`
+	InlinedCode  string `json:"inlined_code"`
+}
+
+type ExplainReport = *ExplainReportT
+
+func (me ExplainReport) Print(console comm.Console) {
+	me.ModelUsageMetrics.Print(console, comm.DEFAULT_COLOR)
+
+	console.NewLine().Printf("Summary: %s", me.Summary)
+	console.NewLine().Print("Explanation:")
+
+	console2 := console.NewIndented()
+	for _, line := range strings.Split(strings.TrimSpace(me.Explanation), "\n") {
+		console2.NewLine().Default(line)
+	}
+}
+
+// Inline puts the explanation at the top of the code as doc comment, enclosed by the markers.
+// The explanation inlined by last execution is replaced in place.
+// Returns false if the language of the code has no known line comment syntax.
+func (me ExplainReport) Inline(code string) (string, bool) {
+	prefix := comm.LineCommentPrefix(me.Path)
+	if len(prefix) == 0 {
+		return code, false
+	}
+
+	doc := prefix + " " + EXPLAIN_BEGIN_MARKER + "\n" +
+		comm.ToLineComments(prefix, me.Summary+"\n\n"+me.Explanation) + "\n" +
+		prefix + " " + EXPLAIN_END_MARKER + "\n"
+
+	if begin, end, found := findInlinedExplanation(prefix, code); found {
+		return code[:begin] + doc + code[end:], true
+	}
+
+	// keep the shebang line as the first line
+	if strings.HasPrefix(code, "#!") {
+		if i := strings.Index(code, "\n"); i >= 0 {
+			return code[:i+1] + doc + code[i+1:], true
+		}
+		return code + "\n" + doc, true
+	}
+
+	return doc + code, true
+}
+
+// RemoveInlinedExplanation returns the code without the explanation inlined by last execution
+func RemoveInlinedExplanation(file string, code string) string {
+	prefix := comm.LineCommentPrefix(file)
+	if len(prefix) == 0 {
+		return code
+	}
+	if begin, end, found := findInlinedExplanation(prefix, code); found {
+		return code[:begin] + code[end:]
+	}
+	return code
+}
+
+// findInlinedExplanation returns the offsets of the lines from the begin marker to the end marker, including the line breaks
+func findInlinedExplanation(prefix string, code string) (int, int, bool) {
+	begin := -1
+	offset := 0
+	for _, line := range strings.SplitAfter(code, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if begin < 0 && trimmed == prefix+" "+EXPLAIN_BEGIN_MARKER {
+			begin = offset
+		} else if begin >= 0 && trimmed == prefix+" "+EXPLAIN_END_MARKER {
+			return begin, offset + len(line), true
+		}
+		offset += len(line)
+	}
+	return 0, 0, false
+}
+
+func ExtractExplainReport(answer string, isGolang bool) ExplainReport {
+	jsonStr, _ := comm.ExtractMarkdownJsonBlocksP(answer)
+
+	indexOfLeftBrace := strings.Index(jsonStr, "{")
+	if indexOfLeftBrace < 0 {
+		panic(errors.New("invalid json format - missing left brace"))
+	}
+	jsonStr = jsonStr[indexOfLeftBrace:]
+
+	indexOfRightBrace := strings.LastIndex(jsonStr, "}")
+	if indexOfRightBrace <= 0 {
+		panic(errors.New("invalid json format - missing right brace"))
+	}
+	jsonStr = jsonStr[:indexOfRightBrace+1]
+
+	report := &ExplainReportT{}
+	if err := comm.FromJson(jsonStr, false, report); err != nil {
+		jsonStr = comm.FixJson(jsonStr, isGolang)
+		comm.FromJsonP(jsonStr, false, report)
+	}
+	return report
+}
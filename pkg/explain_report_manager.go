@@ -0,0 +1,65 @@
+package batchai
+
+import (
+	"path"
+	"sync"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+type ExplainReportManagerT struct {
+	reportsMapByFile map[string]ExplainReport
+	lock             sync.Mutex
+}
+
+type ExplainReportManager = *ExplainReportManagerT
+
+func NewExplainReportManager() ExplainReportManager {
+	return &ExplainReportManagerT{
+		reportsMapByFile: map[string]ExplainReport{},
+	}
+}
+
+func (me ExplainReportManager) LoadReport(x Kontext, file string) ExplainReport {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+
+	r, has := me.reportsMapByFile[file]
+	if has {
+		return r
+	}
+
+	reportFile := ResolveExplainReportFile(x.Config.CacheDir, x.Args.Repository, file)
+
+	r = &ExplainReportT{}
+	if err := comm.FromJsonFile(x.Fs, reportFile, false, r); err != nil {
+		return nil
+	}
+
+	me.reportsMapByFile[file] = r
+
+	return r
+}
+
+func (me ExplainReportManager) SaveReport(x Kontext, file string, report ExplainReport) string {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+
+	me.reportsMapByFile[file] = report
+
+	reportText := comm.ToJsonP(report, true)
+
+	reportFile := ResolveExplainReportFile(x.Config.CacheDir, x.Args.Repository, file)
+
+	comm.Mkdir(x.Fs, path.Dir(reportFile))
+	comm.WriteFileText(x.Fs, reportFile, reportText)
+
+	return reportFile
+}
+
+func ResolveExplainReportFile(cacheDir string, repository string, file string) string {
+	// the file is relative to working directory, so take the relative path
+	relativePath := file[len(repository):]
+	repoName := path.Base(repository)
+	return path.Join(cacheDir, repoName, relativePath+".explain.batchai.json")
+}
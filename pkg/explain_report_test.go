@@ -0,0 +1,45 @@
+package batchai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainReportInline(t *testing.T) {
+	a := require.New(t)
+
+	r := &ExplainReportT{Path: "a/b.go", Summary: "summary", Explanation: "line1\n\nline2"}
+	code, ok := r.Inline("package b\n")
+	a.True(ok)
+	a.Equal("// batchai:explain-begin\n// summary\n//\n// line1\n//\n// line2\n// batchai:explain-end\npackage b\n", code)
+	a.Equal("package b\n", RemoveInlinedExplanation(r.Path, code))
+
+	// replaced rather than stacked
+	r2 := &ExplainReportT{Path: "a/b.go", Summary: "new summary", Explanation: "new"}
+	code, ok = r2.Inline(code + "\nfunc B() {}\n")
+	a.True(ok)
+	a.Equal("// batchai:explain-begin\n// new summary\n//\n// new\n// batchai:explain-end\npackage b\n\nfunc B() {}\n", code)
+
+	r.Path = "a/b.sh"
+	code, ok = r.Inline("#!/bin/sh\necho\n")
+	a.True(ok)
+	a.Equal("#!/bin/sh\n# batchai:explain-begin\n# summary\n#\n# line1\n#\n# line2\n# batchai:explain-end\necho\n", code)
+	code, _ = r.Inline(code)
+	a.Equal(1, strings.Count(code, "batchai:explain-begin"))
+
+	r.Path = "a/b.json"
+	code, ok = r.Inline("{}")
+	a.False(ok)
+	a.Equal("{}", code)
+}
+
+func TestExtractExplainReport(t *testing.T) {
+	a := require.New(t)
+
+	answer := "```json\n{\"summary\": \"s\", \"explanation\": \"e\"}\n```"
+	r := ExtractExplainReport(answer, false)
+	a.Equal("s", r.Summary)
+	a.Equal("e", r.Explanation)
+}
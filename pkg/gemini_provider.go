@@ -0,0 +1,199 @@
+package batchai
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/qiangyt/batchai/comm"
+)
+
+const GEMINI_DEFAULT_BASE_URL = "https://generativelanguage.googleapis.com/v1beta/"
+
+type geminiPartT struct {
+	Text string `json:"text"`
+}
+
+type geminiContentT struct {
+	Role  string        `json:"role,omitempty"`
+	Parts []geminiPartT `json:"parts"`
+}
+
+type geminiGenerationConfigT struct {
+	Temperature     float64 `json:"temperature"`
+	MaxOutputTokens int64   `json:"maxOutputTokens,omitempty"`
+}
+
+type geminiRequestT struct {
+	SystemInstruction *geminiContentT         `json:"systemInstruction,omitempty"`
+	Contents          []geminiContentT        `json:"contents"`
+	GenerationConfig  geminiGenerationConfigT `json:"generationConfig"`
+}
+
+type geminiResponseT struct {
+	Candidates []struct {
+		Content      geminiContentT `json:"content"`
+		FinishReason string         `json:"finishReason"`
+	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int64 `json:"promptTokenCount"`
+		CandidatesTokenCount int64 `json:"candidatesTokenCount"`
+		TotalTokenCount      int64 `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+func (me *geminiResponseT) text() string {
+	if len(me.Candidates) == 0 {
+		return ""
+	}
+
+	r := ""
+	for _, part := range me.Candidates[0].Content.Parts {
+		r += part.Text
+	}
+	return r
+}
+
+func (me *geminiResponseT) finishReason() string {
+	if len(me.Candidates) == 0 {
+		return ""
+	}
+	return me.Candidates[0].FinishReason
+}
+
+func (me *geminiResponseT) tokenUsage() TokenUsage {
+	return &TokenUsageT{
+		PromptTokens:     me.UsageMetadata.PromptTokenCount,
+		CompletionTokens: me.UsageMetadata.CandidatesTokenCount,
+		TotalTokens:      me.UsageMetadata.TotalTokenCount,
+	}
+}
+
+// GeminiProviderT talks to the Google Gemini generateContent API natively
+type GeminiProviderT struct {
+	config     ModelConfig
+	httpClient *http.Client
+}
+
+type GeminiProvider = *GeminiProviderT
+
+func NewGeminiProvider(config ModelConfig) GeminiProvider {
+	return &GeminiProviderT{
+		config:     config,
+		httpClient: buildHttpClient(config),
+	}
+}
+
+func (me GeminiProvider) url(method string, query string) string {
+	baseUrl := me.config.BaseUrl
+	if len(baseUrl) == 0 {
+		baseUrl = GEMINI_DEFAULT_BASE_URL
+	}
+
+	r := strings.TrimSuffix(baseUrl, "/") + "/models/" + url.PathEscape(me.config.Name) + ":" + method
+	if len(query) > 0 {
+		r += "?" + query
+	}
+	return r
+}
+
+func (me GeminiProvider) headers() map[string]string {
+	return map[string]string{
+		"x-goog-api-key": me.config.ApiKey,
+	}
+}
+
+func (me GeminiProvider) newRequest(memory ChatMemory, requestedMaxCompletionTokens int64) *geminiRequestT {
+	r := &geminiRequestT{
+		Contents: []geminiContentT{},
+		GenerationConfig: geminiGenerationConfigT{
+			Temperature:     me.config.Temperature,
+			MaxOutputTokens: requestedMaxCompletionTokens,
+		},
+	}
+
+	systemParts := []geminiPartT{}
+	for _, msg := range memory.Messages() {
+		switch msg.Role {
+		case "system":
+			systemParts = append(systemParts, geminiPartT{Text: msg.Content})
+		case "assistant":
+			r.Contents = append(r.Contents, geminiContentT{Role: "model", Parts: []geminiPartT{{Text: msg.Content}}})
+		default:
+			r.Contents = append(r.Contents, geminiContentT{Role: "user", Parts: []geminiPartT{{Text: msg.Content}}})
+		}
+	}
+	if len(systemParts) > 0 {
+		r.SystemInstruction = &geminiContentT{Parts: systemParts}
+	}
+
+	return r
+}
+
+func (me GeminiProvider) Chat(x Kontext, memory ChatMemory, requestedMaxCompletionTokens int64) ChatAnswer {
+	resp := postModelApi(x, me.httpClient, me.url("generateContent", ""), me.headers(), me.newRequest(memory, requestedMaxCompletionTokens))
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		panic(errors.Wrap(err, "failed to read response of generateContent API"))
+	}
+
+	r := &geminiResponseT{}
+	comm.FromJsonP(string(body), false, r)
+
+	if len(r.Candidates) == 0 {
+		panic(errors.New("no candidate in generateContent response"))
+	}
+
+	return &ChatAnswerT{
+		Content: r.text(),
+		Usage:   r.tokenUsage(),
+	}
+}
+
+func (me GeminiProvider) ChatStream(x Kontext, memory ChatMemory, output io.Writer, requestedMaxCompletionTokens int64) ChatAnswer {
+	resp := postModelApi(x, me.httpClient, me.url("streamGenerateContent", "alt=sse"), me.headers(), me.newRequest(memory, requestedMaxCompletionTokens))
+	defer resp.Body.Close()
+
+	w := NewStreamLineWriter(output)
+
+	var content strings.Builder
+	var usage TokenUsage
+	finished := false
+
+	err := comm.ReadServerSentEvents(resp.Body, func(event string, data string) error {
+		chunk := &geminiResponseT{}
+		if err := comm.FromJson(data, false, chunk); err != nil {
+			return err
+		}
+
+		text := chunk.text()
+		content.WriteString(text)
+		w.WriteChunk(text)
+
+		if chunk.UsageMetadata.TotalTokenCount > 0 {
+			usage = chunk.tokenUsage()
+		}
+		if len(chunk.finishReason()) > 0 {
+			finished = true
+		}
+		return nil
+	})
+
+	w.Flush()
+
+	if err != nil {
+		panic(errors.Wrap(err, "failed to stream generateContent API"))
+	}
+	if !finished {
+		panic(errors.Wrap(ErrTruncatedStream, "generateContent API"))
+	}
+
+	return &ChatAnswerT{
+		Content: content.String(),
+		Usage:   usage,
+	}
+}
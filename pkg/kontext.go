@@ -25,12 +25,12 @@ func NewKontext(fs afero.Fs) Kontext {
 	}
 }
 
-func (me Kontext) Timeouted(timeout time.Duration) Kontext {
-	timeoutCtx, _ := context.WithTimeout(me.Context, timeout)
+func (me Kontext) Timeouted(timeout time.Duration) (Kontext, context.CancelFunc) {
+	timeoutCtx, cancel := context.WithTimeout(me.Context, timeout)
 	return &KontextT{
 		Context: timeoutCtx,
 		Fs:      me.Fs,
 		Args:    me.Args,
 		Config:  me.Config,
-	}
+	}, cancel
 }
@@ -4,6 +4,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/qiangyt/batchai/comm"
 	"github.com/urfave/cli/v2"
 )
@@ -26,6 +27,12 @@ func (me ListCommand) List(x Kontext) {
 }
 
 func (me ListCommand) CollectWorkingFiles(x Kontext, c comm.Console) ([]string, int, int, []string) {
+	return me.CollectPrioritizedWorkingFiles(x, c, nil)
+}
+
+// CollectPrioritizedWorkingFiles is same as CollectWorkingFiles, but the target files are sorted or filtered by
+// the prioritize function before limited by --num
+func (me ListCommand) CollectPrioritizedWorkingFiles(x Kontext, c comm.Console, prioritize func([]string) []string) ([]string, int, int, []string) {
 	var targetFiles []string
 
 	repoFiles, ignored, failed := me.CollectFiles(x, c, x.Args.Repository)
@@ -36,6 +43,14 @@ func (me ListCommand) CollectWorkingFiles(x Kontext, c comm.Console) ([]string,
 		targetFiles = repoFiles
 	}
 
+	if x.Args.HasGitDiffFilter() {
+		targetFiles = me.FilterChangedFiles(x, c, targetFiles)
+	}
+
+	if prioritize != nil {
+		targetFiles = prioritize(targetFiles)
+	}
+
 	if x.Args.NumberOfFilesToProcess > 0 {
 		if x.Args.NumberOfFilesToProcess < len(targetFiles) {
 			targetFiles = targetFiles[:x.Args.NumberOfFilesToProcess]
@@ -49,6 +64,37 @@ func (me ListCommand) CollectWorkingFiles(x Kontext, c comm.Console) ([]string,
 	return targetFiles, ignored, failed, repoFiles
 }
 
+// FilterChangedFiles keeps only the files changed per --since, --staged or --changed
+func (me ListCommand) FilterChangedFiles(x Kontext, c comm.Console, files []string) []string {
+	changedFiles, err := comm.GetGitDiffFiles(x.Fs, x.Args.Repository, x.Args.GitDiffArgs()...)
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to list the changed files: git diff %s", strings.Join(x.Args.GitDiffArgs(), " ")))
+	}
+
+	if x.Args.Changed {
+		untrackedFiles, err := comm.GetUntrackedFiles(x.Fs, x.Args.Repository)
+		if err != nil {
+			panic(errors.Wrap(err, "failed to list the untracked files"))
+		}
+		changedFiles = append(changedFiles, untrackedFiles...)
+	}
+
+	changed := map[string]bool{}
+	for _, f := range changedFiles {
+		changed[f] = true
+	}
+
+	r := []string{}
+	for _, f := range files {
+		if changed[f] {
+			r = append(r, f)
+		} else if x.Args.Verbose {
+			c.NewLine().Gray("unchanged: ").Default(f)
+		}
+	}
+	return r
+}
+
 func (me ListCommand) CollectTargetFiles(x Kontext, c comm.Console) ([]string, int, int) {
 	ignored := 0
 	failed := 0
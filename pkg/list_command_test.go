@@ -0,0 +1,122 @@
+package batchai
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+func initTestGitRepo(t *testing.T, fs afero.Fs, files map[string]string) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	repo := t.TempDir()
+	for f, content := range files {
+		comm.MkdirP(fs, filepath.Dir(filepath.Join(repo, f)))
+		comm.WriteFileTextP(fs, filepath.Join(repo, f), content)
+	}
+
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		_, err := comm.ExecGit(fs, repo, args, true)
+		require.NoError(t, err)
+	}
+	return repo
+}
+
+func TestFilterChangedFiles(t *testing.T) {
+	a := require.New(t)
+
+	fs := afero.NewOsFs()
+	repo := initTestGitRepo(t, fs, map[string]string{
+		"a.go":     "package a\n",
+		"b.go":     "package b\n",
+		"sub/c.go": "package c\n",
+	})
+
+	comm.WriteFileTextP(fs, filepath.Join(repo, "a.go"), "package a\n\nvar A = 1\n")
+	comm.WriteFileTextP(fs, filepath.Join(repo, "sub/c.go"), "package c\n\nvar C = 1\n")
+	comm.WriteFileTextP(fs, filepath.Join(repo, "d.go"), "package d\n")
+	// quoted by git unless -z
+	comm.WriteFileTextP(fs, filepath.Join(repo, "café \"1\".go"), "package e\n")
+	_, err := comm.ExecGit(fs, repo, []string{"add", "sub/c.go"}, true)
+	a.NoError(err)
+
+	x := NewKontext(fs)
+	files := []string{
+		filepath.Join(repo, "a.go"),
+		filepath.Join(repo, "b.go"),
+		filepath.Join(repo, "café \"1\".go"),
+		filepath.Join(repo, "d.go"),
+		filepath.Join(repo, "sub/c.go"),
+	}
+	c := comm.NewConsole(true)
+
+	x.Args = &AppArgsT{Repository: repo, Staged: true}
+	a.Equal([]string{filepath.Join(repo, "sub/c.go")}, NewListCommand().FilterChangedFiles(x, c, files))
+
+	x.Args = &AppArgsT{Repository: repo, Changed: true}
+	a.Equal([]string{filepath.Join(repo, "a.go"), filepath.Join(repo, "café \"1\".go"), filepath.Join(repo, "d.go"), filepath.Join(repo, "sub/c.go")},
+		NewListCommand().FilterChangedFiles(x, c, files))
+
+	x.Args = &AppArgsT{Repository: repo, Since: "HEAD"}
+	a.Equal([]string{filepath.Join(repo, "a.go"), filepath.Join(repo, "sub/c.go")}, NewListCommand().FilterChangedFiles(x, c, files))
+
+	diff, err := comm.GetGitDiff(fs, repo, filepath.Join(repo, "a.go"), x.Args.GitDiffArgs()...)
+	a.NoError(err)
+	a.Contains(diff, "+var A = 1")
+
+	// the untracked file has no diff from git, so all lines are taken as added
+	x.Args = &AppArgsT{Repository: repo, Changed: true}
+	agent := &CheckAgentT{file: filepath.Join(repo, "d.go"), relativeFile: "d.go"}
+	a.Equal("--- /dev/null\n+++ b/d.go\n@@ -0,0 +1,1 @@\n+package d\n", agent.gitDiff(x, "package d\n"))
+
+	agent = &CheckAgentT{file: filepath.Join(repo, "b.go"), relativeFile: "b.go"}
+	a.Empty(agent.gitDiff(x, "package b\n"))
+}
+
+func TestFilterChangedFilesWithoutCommits(t *testing.T) {
+	a := require.New(t)
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	fs := afero.NewOsFs()
+	repo := t.TempDir()
+	_, err := comm.ExecGit(fs, repo, []string{"init", "-q"}, true)
+	a.NoError(err)
+	a.False(comm.HasGitCommit(fs, repo))
+
+	comm.WriteFileTextP(fs, filepath.Join(repo, "a.go"), "package a\n")
+	comm.WriteFileTextP(fs, filepath.Join(repo, "b.go"), "package b\n")
+	_, err = comm.ExecGit(fs, repo, []string{"add", "a.go"}, true)
+	a.NoError(err)
+
+	x := NewKontext(fs)
+	x.Args = &AppArgsT{Repository: repo, Changed: true, NoCommits: true}
+	files := []string{filepath.Join(repo, "a.go"), filepath.Join(repo, "b.go")}
+	a.Equal(files, NewListCommand().FilterChangedFiles(x, comm.NewConsole(true), files))
+}
+
+func TestGitDiffFilterArgs(t *testing.T) {
+	a := require.New(t)
+
+	a.Error((&AppArgsT{Since: "main", Staged: true}).validateGitDiffFilter())
+	a.NoError((&AppArgsT{Since: "main"}).validateGitDiffFilter())
+
+	a.False((&AppArgsT{}).HasGitDiffFilter())
+	a.Equal([]string{"main"}, (&AppArgsT{Since: "main"}).GitDiffArgs())
+	a.Equal([]string{"--cached"}, (&AppArgsT{Staged: true}).GitDiffArgs())
+	a.Equal([]string{"HEAD"}, (&AppArgsT{Changed: true}).GitDiffArgs())
+	a.Equal([]string{"--cached"}, (&AppArgsT{Changed: true, NoCommits: true}).GitDiffArgs())
+}
@@ -10,25 +10,59 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/openai/openai-go"
-	"github.com/openai/openai-go/option"
 	"github.com/pkg/errors"
 	"github.com/tiktoken-go/tokenizer"
 
 	"github.com/qiangyt/batchai/comm"
 )
 
-type ModelClientT struct {
-	config                ModelConfig
-	openAiClient          *openai.Client
-	openAiStreamingClient *openai.Client
-	semaphore             chan struct{}
-	codec                 tokenizer.Codec
+const (
+	MODEL_PROVIDER_OPENAI    = "openai"
+	MODEL_PROVIDER_ANTHROPIC = "anthropic"
+	MODEL_PROVIDER_GEMINI    = "gemini"
+)
+
+type ChatAnswerT struct {
+	Content     string
+	Usage       TokenUsage
+	Attempts    int
+	RetryErrors []string
+	InFlight    int
+	QueueWait   time.Duration
+}
+
+type ChatAnswer = *ChatAnswerT
+
+// ChatWriterFactory creates the writer of the streamed output, called for each attempt so that
+// the next attempt doesn't inherit the state of a failed one. nil means no streaming
+type ChatWriterFactory func() io.Writer
+
+// ModelClient is what ModelService depends on to chat with a model
+type ModelClient interface {
+	Config() ModelConfig
+	EvaluatedTokens(prompt string) int
+	Chat(x Kontext, c comm.Console, saveIntoMemory bool, memory ChatMemory, newWriter ChatWriterFactory) (ChatAnswer, time.Duration)
+}
+
+// ModelProvider talks to the API of a specific kind of model provider
+type ModelProvider interface {
+	Chat(x Kontext, memory ChatMemory, requestedMaxCompletionTokens int64) ChatAnswer
+	ChatStream(x Kontext, memory ChatMemory, output io.Writer, requestedMaxCompletionTokens int64) ChatAnswer
+}
+
+type DefaultModelClientT struct {
+	config      ModelConfig
+	provider    ModelProvider
+	semaphore   chan struct{}
+	inFlight    atomic.Int32
+	rateLimiter ModelRateLimiter
+	codec       tokenizer.Codec
 }
 
-type ModelClient = *ModelClientT
+type DefaultModelClient = *DefaultModelClientT
 
 func NewModelClient(config ModelConfig) ModelClient {
 	codec, err := tokenizer.Get(tokenizer.Cl100kBase)
@@ -36,12 +70,17 @@ func NewModelClient(config ModelConfig) ModelClient {
 		panic(errors.Wrap(err, "failed to initialize Cl100kBase tokenizer codec"))
 	}
 
-	return &ModelClientT{
-		config:                config,
-		openAiClient:          buildOpenAiClient(config, false),
-		openAiStreamingClient: buildOpenAiClient(config, true),
-		semaphore:             make(chan struct{}, 1),
-		codec:                 codec,
+	maxConcurrentRequests := config.MaxConcurrentRequests
+	if maxConcurrentRequests <= 0 {
+		maxConcurrentRequests = 1
+	}
+
+	return &DefaultModelClientT{
+		config:      config,
+		provider:    buildModelProvider(config),
+		semaphore:   make(chan struct{}, maxConcurrentRequests),
+		rateLimiter: NewModelRateLimiter(config.RequestsPerMinute, config.TokensPerMinute),
+		codec:       codec,
 	}
 }
 
@@ -50,15 +89,42 @@ func buildModelClient(config AppConfig, modelId string) ModelClient {
 	return NewModelClient(model)
 }
 
-func (me ModelClient) acquire() {
-	me.semaphore <- struct{}{}
+func buildModelProvider(config ModelConfig) ModelProvider {
+	switch config.Provider {
+	case "", MODEL_PROVIDER_OPENAI:
+		return NewOpenAiProvider(config)
+	case MODEL_PROVIDER_ANTHROPIC:
+		return NewAnthropicProvider(config)
+	case MODEL_PROVIDER_GEMINI:
+		return NewGeminiProvider(config)
+	}
+	panic(fmt.Errorf("unsupported provider '%s' of model '%s'", config.Provider, config.Id))
+}
+
+func (me DefaultModelClient) Config() ModelConfig {
+	return me.config
 }
 
-func (me ModelClient) release() {
+// acquire waits for a free request slot, and returns the number of in-flight requests including this one
+func (me DefaultModelClient) acquire(x Kontext) int {
+	select {
+	case me.semaphore <- struct{}{}:
+	case <-x.Context.Done():
+		panic(errors.Wrapf(x.Context.Err(), "canceled while waiting for a request slot of %s", me.config.Id))
+	}
+	return int(me.inFlight.Add(1))
+}
+
+func (me DefaultModelClient) release() {
+	me.inFlight.Add(-1)
 	<-me.semaphore
 }
 
-func (me ModelClient) Encode(msg string) ([]uint, []string) {
+func (me DefaultModelClient) InFlight() int {
+	return int(me.inFlight.Load())
+}
+
+func (me DefaultModelClient) Encode(msg string) ([]uint, []string) {
 	tokens, texts, err := me.codec.Encode(msg)
 	if err != nil {
 		panic(errors.Wrap(err, "failed to encode text"))
@@ -66,7 +132,7 @@ func (me ModelClient) Encode(msg string) ([]uint, []string) {
 	return tokens, texts
 }
 
-func (me ModelClient) Decode(tokens []uint) string {
+func (me DefaultModelClient) Decode(tokens []uint) string {
 	text, err := me.codec.Decode(tokens)
 	if err != nil {
 		panic(errors.Wrap(err, "failed to decode tokens"))
@@ -74,207 +140,216 @@ func (me ModelClient) Decode(tokens []uint) string {
 	return text
 }
 
-func (me ModelClient) EvaluatedTokens(prompt string) int {
+func (me DefaultModelClient) EvaluatedTokens(prompt string) int {
 	tokens, _ := me.Encode(prompt)
 	return len(tokens)
 }
 
-func (me ModelClient) Chat(x Kontext, c comm.Console, saveIntoMemory bool, memory ChatMemory, writer io.Writer) (*openai.ChatCompletion, time.Duration) {
-	me.acquire()
-	defer me.release()
-
+func (me DefaultModelClient) Chat(x Kontext, c comm.Console, saveIntoMemory bool, memory ChatMemory, newWriter ChatWriterFactory) (ChatAnswer, time.Duration) {
 	startTime := time.Now()
-	x = x.Timeouted(me.config.Timeout)
+	queueWait := time.Duration(0)
+	inFlight := 0
 
 	cfg := me.config
 
-	requestedMaxCompletionTokens := cfg.MaxCompletionTokens
-	if requestedMaxCompletionTokens > 0 {
+	lenOfPromptTokens := int64(0)
+	if cfg.MaxCompletionTokens > 0 || me.rateLimiter.Enabled() {
 		promptTokens, _ := me.Encode(memory.Format())
-		lenOfPromptTokens := int64(len(promptTokens) + 16)
+		lenOfPromptTokens = int64(len(promptTokens) + 16)
+	}
 
+	requestedMaxCompletionTokens := cfg.MaxCompletionTokens
+	if requestedMaxCompletionTokens > 0 {
 		contextWindow := cfg.ContextWindow
 		allowedMaxCompletionTokens := contextWindow - lenOfPromptTokens
 
 		if requestedMaxCompletionTokens >= allowedMaxCompletionTokens {
 			c.Yellowf("pre-check warning: %s maximum context length is %d tokens. However, you requested %d tokens (%d in the messages, %d in the completion). Force to reduce the length of the messages or completion to be %d.",
 				cfg.Id, contextWindow, requestedMaxCompletionTokens+lenOfPromptTokens, lenOfPromptTokens, requestedMaxCompletionTokens, allowedMaxCompletionTokens)
-		}
 
-		requestedMaxCompletionTokens = allowedMaxCompletionTokens
+			requestedMaxCompletionTokens = allowedMaxCompletionTokens
+		}
 	}
 
-	var r *openai.ChatCompletion
-	if writer == nil {
-		r = me.chat(x, memory, requestedMaxCompletionTokens)
-	} else {
-		r = me.chatStream(x, memory, writer, requestedMaxCompletionTokens)
+	retry := cfg.Retry
+	maxAttempts := retry.GetMaxAttempts()
+	retryErrors := []string{}
+
+	var r ChatAnswer
+	for attempt := 1; ; attempt++ {
+		var writer io.Writer
+		if newWriter != nil {
+			writer = newWriter()
+		}
+
+		// the request slot is held by an attempt only, not during the delay before next attempt,
+		// so that a throttled request doesn't block the others
+		var err error
+		r, err = func() (ChatAnswer, error) {
+			acquireStartTime := time.Now()
+			inFlight = me.acquire(x)
+			defer me.release()
+
+			var rateRecord modelRateRecord
+			if me.rateLimiter.Enabled() {
+				rateRecord = me.rateLimiter.Wait(x, lenOfPromptTokens+requestedMaxCompletionTokens)
+			}
+			queueWait += time.Since(acquireStartTime)
+
+			answer, err := me.chatOnce(x, memory, writer, requestedMaxCompletionTokens)
+			if err == nil && answer.Usage != nil {
+				me.rateLimiter.Update(rateRecord, answer.Usage.TotalTokens)
+			}
+			return answer, err
+		}()
+		if err == nil {
+			break
+		}
+
+		modelErr := ClassifyModelError(err)
+		if !modelErr.Retryable || attempt >= maxAttempts {
+			panic(modelErr)
+		}
+		retryErrors = append(retryErrors, err.Error())
+
+		delay := retry.Delay(attempt, modelErr.RetryAfter)
+		c.NewLine().Yellowf("attempt %d/%d of %s failed, retry in %v: %v", attempt, maxAttempts, cfg.Id, delay, err)
+		if writer != nil {
+			c.NewLine().Yellowln("retrying, partial output discarded")
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-x.Context.Done():
+			panic(errors.Wrapf(x.Context.Err(), "canceled while waiting to retry %s", cfg.Id))
+		}
 	}
+	r.Attempts = len(retryErrors) + 1
+	r.InFlight = inFlight
+	r.QueueWait = queueWait
+	r.RetryErrors = retryErrors
 
 	if saveIntoMemory {
-		content := r.Choices[0].Message.Content
-		memory.AddAssistantMessage(content)
+		memory.AddAssistantMessage(r.Content)
 	}
 
 	return r, time.Since(startTime)
 }
 
-func (me ModelClient) chat(x Kontext, memory ChatMemory, requestedMaxCompletionTokens int64) *openai.ChatCompletion {
-	params := openai.ChatCompletionNewParams{
-		Messages:    openai.F(memory.ToChatCompletionMessageParamUnion()),
-		Temperature: openai.F(me.config.Temperature),
-		Seed:        openai.Int(1),
-		Model:       openai.F(me.config.Name),
-	}
-	if requestedMaxCompletionTokens > 0 {
-		params.MaxCompletionTokens = openai.Int(requestedMaxCompletionTokens)
-	}
+// chatOnce makes a single attempt, turning the panic of the provider into an error
+func (me DefaultModelClient) chatOnce(x Kontext, memory ChatMemory, writer io.Writer, requestedMaxCompletionTokens int64) (r ChatAnswer, err error) {
+	x, cancel := x.Timeouted(me.config.Timeout)
+	defer cancel()
+
+	defer func() {
+		if e := recover(); e != nil {
+			if ee, ok := e.(error); ok {
+				err = ee
+			} else {
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
 
-	r, err := me.openAiClient.Chat.Completions.New(x.Context, params)
-	if err != nil {
-		panic(errors.Wrap(err, "failed to call chat completions API"))
+	if writer == nil {
+		r = me.provider.Chat(x, memory, requestedMaxCompletionTokens)
+	} else {
+		r = me.provider.ChatStream(x, memory, writer, requestedMaxCompletionTokens)
 	}
-	return r
+	return r, nil
 }
 
-func (me ModelClient) chatStream(x Kontext, memory ChatMemory, output io.Writer, requestedMaxCompletionTokens int64) *openai.ChatCompletion {
-	params := openai.ChatCompletionNewParams{
-		Messages:    openai.F(memory.ToChatCompletionMessageParamUnion()),
-		Temperature: openai.F(me.config.Temperature),
-		Seed:        openai.Int(0),
-		Model:       openai.F(me.config.Name),
-	}
-	if requestedMaxCompletionTokens > 0 {
-		params.MaxCompletionTokens = openai.Int(requestedMaxCompletionTokens)
-	}
-
-	stream := me.openAiStreamingClient.Chat.Completions.NewStreaming(x.Context, params)
-
-	// optionally, an accumulator helper can be used
-	acc := &openai.ChatCompletionAccumulator{}
-
-	var buffer string // Buffer to hold partial lines
-
-	for stream.Next() {
-		chunk := stream.Current()
-		acc.AddChunk(chunk)
-
-		// if content, ok := acc.JustFinishedContent(); ok {
-		// 	println("Content stream finished:", content)
-		// }
+// StreamLineWriterT accumulates the streamed chunks and writes them to the output line by line
+type StreamLineWriterT struct {
+	output io.Writer
+	buffer string // Buffer to hold partial lines
+}
 
-		// if using tool calls
-		// if tool, ok := acc.JustFinishedToolCall(); ok {
-		// 	println("Tool call stream finished:", tool.Index, tool.Name, tool.Arguments)
-		// }
+type StreamLineWriter = *StreamLineWriterT
 
-		if refusal, ok := acc.JustFinishedRefusal(); ok {
-			panic(errors.New("Refusalstream finished:" + refusal))
-		}
+func NewStreamLineWriter(output io.Writer) StreamLineWriter {
+	return &StreamLineWriterT{output: output}
+}
 
-		// it's best to use chunks after handling JustFinished events
-		// Process chunk content
-		if len(chunk.Choices) > 0 {
-			buffer += chunk.Choices[0].Delta.Content
+func (me StreamLineWriter) WriteChunk(chunk string) {
+	me.buffer += chunk
 
-			// Split the buffer by lines
-			lines := comm.SplitBufferByLines(&buffer)
-			for _, line := range lines {
-				output.Write([]byte(line + "\n"))
-			}
-		}
+	// Split the buffer by lines
+	lines := comm.SplitBufferByLines(&me.buffer)
+	for _, line := range lines {
+		me.output.Write([]byte(line + "\n"))
 	}
+}
 
+func (me StreamLineWriter) Flush() {
 	// Output the last incomplete part in the buffer
-	if len(buffer) > 0 {
-		output.Write([]byte(buffer + "\n"))
+	if len(me.buffer) > 0 {
+		me.output.Write([]byte(me.buffer + "\n"))
+		me.buffer = ""
 	}
-
-	if err := stream.Err(); err != nil {
-		panic(err)
-	}
-
-	// After the stream is finished, acc can be used like a ChatCompletion
-	return &acc.ChatCompletion
 }
 
-func buildOpenAiClient(model ModelConfig, streaming bool) *openai.Client {
-	options := []option.RequestOption{}
-	if len(model.ApiKey) > 0 {
-		options = append(options, option.WithAPIKey(model.ApiKey))
-	}
-	if len(model.BaseUrl) > 0 {
-		options = append(options, option.WithBaseURL(model.BaseUrl))
+func buildHttpClient(model ModelConfig) *http.Client {
+	if len(model.ProxyUrl) == 0 {
+		return nil
 	}
 
-	if !streaming {
-		if model.Timeout.Seconds() > 0 {
-			options = append(options, option.WithRequestTimeout(model.Timeout))
-		}
+	proxyURL, err := url.Parse(model.ProxyUrl)
+	if err != nil {
+		panic(fmt.Errorf("error parsing proxy URL: %+v", err))
 	}
 
-	if len(model.ProxyUrl) > 0 {
-		proxyURL, err := url.Parse(model.ProxyUrl)
-		if err != nil {
-			panic(fmt.Errorf("error parsing proxy URL: %+v", err))
+	var transport *http.Transport = nil
+	if len(model.ProxyUser) == 0 {
+		transport = &http.Transport{
+			Proxy:           http.ProxyURL(proxyURL),
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: model.ProxyInsecureSkipVerify},
 		}
+	} else {
+		dialContext := func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialer := &net.Dialer{}
+			conn, err := dialer.DialContext(ctx, network, proxyURL.Host)
+			if err != nil {
+				return nil, err
+			}
 
-		var transport *http.Transport = nil
-		if len(model.ProxyUser) == 0 {
-			transport = &http.Transport{
-				Proxy:           http.ProxyURL(proxyURL),
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: model.ProxyInsecureSkipVerify},
+			auth := model.ProxyUser + ":" + model.ProxyPass
+			authBase64 := base64.StdEncoding.EncodeToString([]byte(auth))
+
+			_, err = conn.Write([]byte("CONNECT " + addr + " HTTP/1.0\r\n"))
+			if err != nil {
+				return nil, err
+			}
+			_, err = conn.Write([]byte("Proxy-Authorization: Basic " + authBase64 + "\r\n"))
+			if err != nil {
+				return nil, err
 			}
-		} else {
-			dialContext := func(ctx context.Context, network, addr string) (net.Conn, error) {
-				dialer := &net.Dialer{}
-				conn, err := dialer.DialContext(ctx, network, proxyURL.Host)
-				if err != nil {
-					return nil, err
-				}
-
-				auth := model.ProxyUser + ":" + model.ProxyPass
-				authBase64 := base64.StdEncoding.EncodeToString([]byte(auth))
-
-				_, err = conn.Write([]byte("CONNECT " + addr + " HTTP/1.0\r\n"))
-				if err != nil {
-					return nil, err
-				}
-				_, err = conn.Write([]byte("Proxy-Authorization: Basic " + authBase64 + "\r\n"))
-				if err != nil {
-					return nil, err
-				}
-				_, err = conn.Write([]byte("\r\n"))
-				if err != nil {
-					return nil, err
-				}
-
-				buffer := make([]byte, 1024)
-				n, err := conn.Read(buffer)
-				if err != nil {
-					return nil, err
-				}
-				response := string(buffer[:n])
-				if !strings.Contains(response, "200 Connection established") {
-					return nil, fmt.Errorf("proxy connection failed: %s", response)
-				}
-
-				return conn, nil
+			_, err = conn.Write([]byte("\r\n"))
+			if err != nil {
+				return nil, err
 			}
 
-			transport = &http.Transport{
-				Proxy:           http.ProxyURL(proxyURL),
-				DialContext:     dialContext,
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: model.ProxyInsecureSkipVerify},
+			buffer := make([]byte, 1024)
+			n, err := conn.Read(buffer)
+			if err != nil {
+				return nil, err
+			}
+			response := string(buffer[:n])
+			if !strings.Contains(response, "200 Connection established") {
+				return nil, fmt.Errorf("proxy connection failed: %s", response)
 			}
-		}
 
-		httpClient := &http.Client{
-			Transport: transport,
+			return conn, nil
 		}
 
-		options = append(options, option.WithHTTPClient(httpClient))
+		transport = &http.Transport{
+			Proxy:           http.ProxyURL(proxyURL),
+			DialContext:     dialContext,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: model.ProxyInsecureSkipVerify},
+		}
 	}
 
-	return openai.NewClient(options...)
+	return &http.Client{
+		Transport: transport,
+	}
 }
@@ -0,0 +1,205 @@
+package batchai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+func newTestModelConfig(provider string, baseUrl string) ModelConfig {
+	return &ModelConfigT{
+		Id:                  provider + "/test-model",
+		Name:                "test-model",
+		Provider:            provider,
+		ApiKey:              "test-key",
+		BaseUrl:             baseUrl,
+		ContextWindow:       8192,
+		MaxCompletionTokens: 1024,
+		Timeout:             5 * time.Second,
+	}
+}
+
+func newTestChatMemory() ChatMemory {
+	return NewChatMemory().AddSystemMessage("be nice").AddUserMessage("hello")
+}
+
+func readTestRequest(t *testing.T, r *http.Request) map[string]any {
+	body, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+
+	m := map[string]any{}
+	require.NoError(t, json.Unmarshal(body, &m))
+	return m
+}
+
+func writeTestEvents(w http.ResponseWriter, events ...string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, e := range events {
+		fmt.Fprint(w, e+"\n\n")
+	}
+}
+
+func chatWithTestModel(t *testing.T, config ModelConfig, stream bool) (ChatAnswer, string, ChatMemory) {
+	memory := newTestChatMemory()
+	var output *bytes.Buffer
+	var newWriter ChatWriterFactory
+	if stream {
+		output = &bytes.Buffer{}
+		newWriter = func() io.Writer { return output }
+	}
+
+	answer, _ := NewModelClient(config).Chat(NewKontext(afero.NewMemMapFs()), comm.NewConsole(true), true, memory, newWriter)
+	if output == nil {
+		return answer, "", memory
+	}
+	return answer, output.String(), memory
+}
+
+func TestOpenAiProvider(t *testing.T) {
+	a := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/chat/completions", r.URL.Path)
+		a.Equal("Bearer test-key", r.Header.Get("Authorization"))
+
+		req := readTestRequest(t, r)
+		a.Equal("test-model", req["model"])
+		a.Len(req["messages"], 2)
+
+		if req["stream"] == true {
+			writeTestEvents(w,
+				`data: {"id":"1","object":"chat.completion.chunk","created":1,"model":"test-model","choices":[{"index":0,"delta":{"role":"assistant","content":"hi\nthe"}}]}`,
+				`data: {"id":"1","object":"chat.completion.chunk","created":1,"model":"test-model","choices":[{"index":0,"delta":{"content":"re"},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":3,"total_tokens":8}}`,
+				`data: [DONE]`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"1","object":"chat.completion","created":1,"model":"test-model","choices":[{"index":0,"message":{"role":"assistant","content":"hi there"},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`)
+	}))
+	defer server.Close()
+
+	config := newTestModelConfig(MODEL_PROVIDER_OPENAI, server.URL+"/")
+
+	answer, _, memory := chatWithTestModel(t, config, false)
+	a.Equal("hi there", answer.Content)
+	a.Equal(int64(7), answer.Usage.TotalTokens)
+	a.Len(memory.Messages(), 3)
+
+	answer, output, _ := chatWithTestModel(t, config, true)
+	a.Equal("hi\nthere", answer.Content)
+	a.Equal("hi\nthere\n", output)
+	a.Equal(int64(8), answer.Usage.TotalTokens)
+}
+
+func TestAnthropicProvider(t *testing.T) {
+	a := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/messages", r.URL.Path)
+		a.Equal("test-key", r.Header.Get("x-api-key"))
+		a.Equal(ANTHROPIC_API_VERSION, r.Header.Get("anthropic-version"))
+
+		req := readTestRequest(t, r)
+		a.Equal("test-model", req["model"])
+		a.Equal("be nice", req["system"])
+		a.Len(req["messages"], 1)
+
+		if req["stream"] == true {
+			writeTestEvents(w,
+				"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":5,\"output_tokens\":1}}}",
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"hi\\nthe\"}}",
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"re\"}}",
+				"event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":3}}",
+				"event: message_stop\ndata: {\"type\":\"message_stop\"}")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"hi there"}],"stop_reason":"end_turn","usage":{"input_tokens":5,"output_tokens":2}}`)
+	}))
+	defer server.Close()
+
+	config := newTestModelConfig(MODEL_PROVIDER_ANTHROPIC, server.URL+"/")
+
+	answer, _, memory := chatWithTestModel(t, config, false)
+	a.Equal("hi there", answer.Content)
+	a.Equal(int64(7), answer.Usage.TotalTokens)
+	a.Len(memory.Messages(), 3)
+
+	answer, output, _ := chatWithTestModel(t, config, true)
+	a.Equal("hi\nthere", answer.Content)
+	a.Equal("hi\nthere\n", output)
+	a.Equal(int64(8), answer.Usage.TotalTokens)
+}
+
+func TestGeminiProvider(t *testing.T) {
+	a := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("test-key", r.Header.Get("x-goog-api-key"))
+
+		req := readTestRequest(t, r)
+		a.NotNil(req["systemInstruction"])
+		a.Len(req["contents"], 1)
+
+		if r.URL.Path == "/models/test-model:streamGenerateContent" {
+			a.Equal("sse", r.URL.Query().Get("alt"))
+			writeTestEvents(w,
+				`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"hi\nthe"}]}}]}`,
+				`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"re"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":5,"candidatesTokenCount":3,"totalTokenCount":8}}`)
+			return
+		}
+
+		a.Equal("/models/test-model:generateContent", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"hi there"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":5,"candidatesTokenCount":2,"totalTokenCount":7}}`)
+	}))
+	defer server.Close()
+
+	config := newTestModelConfig(MODEL_PROVIDER_GEMINI, server.URL+"/")
+
+	answer, _, memory := chatWithTestModel(t, config, false)
+	a.Equal("hi there", answer.Content)
+	a.Equal(int64(7), answer.Usage.TotalTokens)
+	a.Len(memory.Messages(), 3)
+
+	answer, output, _ := chatWithTestModel(t, config, true)
+	a.Equal("hi\nthere", answer.Content)
+	a.Equal("hi\nthere\n", output)
+	a.Equal(int64(8), answer.Usage.TotalTokens)
+}
+
+func TestModelApiError(t *testing.T) {
+	a := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":"invalid key"}`)
+	}))
+	defer server.Close()
+
+	config := newTestModelConfig(MODEL_PROVIDER_ANTHROPIC, server.URL+"/")
+
+	defer func() {
+		err, ok := recover().(ModelError)
+		a.True(ok)
+		a.False(err.Retryable)
+
+		var apiErr ModelApiError
+		a.True(errors.As(err, &apiErr))
+		a.Equal(http.StatusUnauthorized, apiErr.StatusCode)
+	}()
+	chatWithTestModel(t, config, false)
+}
@@ -5,20 +5,29 @@ import (
 )
 
 type ModelConfigT struct {
-	Id                      string        `mapstructure:"id,omitempty"`
-	Name                    string        `mapstructure:"name,omitempty"`
-	Temperature             float64       `mapstructure:"temperature,omitempty"`
-	MaxCompletionTokens     int64         `mapstructure:"max_completion_tokens,omitempty"`
-	ContextWindow           int64         `mapstructure:"context_window"`
-	ApiKey                  string        `mapstructure:"api_key"`
-	BaseUrl                 string        `mapstructure:"base_url"`
-	Timeout                 time.Duration `mapstructure:"timeout,omitempty" default:"10s"`
-	ProxyUrl                string        `mapstructure:"proxy_url,omitempty"`
-	ProxyUser               string        `mapstructure:"proxy_user,omitempty"`
-	ProxyPass               string        `mapstructure:"proxy_pass,omitempty"`
-	ProxyInsecureSkipVerify bool          `mapstructure:"proxy_insecure_skip_verify" default:"false"`
-	CheckPrompt             CheckPrompt   `mapstructure:"check_prompt"`
-	TestPrompt              TestPrompt    `mapstructure:"test_prompt"`
+	Id                      string           `mapstructure:"id,omitempty"`
+	Name                    string           `mapstructure:"name,omitempty"`
+	Provider                string           `mapstructure:"provider,omitempty"`
+	Temperature             float64          `mapstructure:"temperature,omitempty"`
+	MaxCompletionTokens     int64            `mapstructure:"max_completion_tokens,omitempty"`
+	ContextWindow           int64            `mapstructure:"context_window"`
+	ApiKey                  string           `mapstructure:"api_key"`
+	BaseUrl                 string           `mapstructure:"base_url"`
+	Timeout                 time.Duration    `mapstructure:"timeout,omitempty" default:"10s"`
+	ProxyUrl                string           `mapstructure:"proxy_url,omitempty"`
+	ProxyUser               string           `mapstructure:"proxy_user,omitempty"`
+	ProxyPass               string           `mapstructure:"proxy_pass,omitempty"`
+	ProxyInsecureSkipVerify bool             `mapstructure:"proxy_insecure_skip_verify" default:"false"`
+	MaxConcurrentRequests   int              `mapstructure:"max_concurrent_requests,omitempty"`
+	RequestsPerMinute       int              `mapstructure:"requests_per_minute,omitempty"`
+	TokensPerMinute         int64            `mapstructure:"tokens_per_minute,omitempty"`
+	Retry                   ModelRetryConfig `mapstructure:"retry,omitempty"`
+	CheckPrompt             CheckPrompt      `mapstructure:"check_prompt"`
+	TestPrompt              TestPrompt       `mapstructure:"test_prompt"`
+	ExplainPrompt           ExplainPrompt    `mapstructure:"explain_prompt"`
+	CommentPrompt           CommentPrompt    `mapstructure:"comment_prompt"`
+	RefactorPrompt          RefactorPrompt   `mapstructure:"refactor_prompt"`
+	ReviewPrompt            ReviewPrompt     `mapstructure:"review_prompt"`
 }
 
 type ModelConfig = *ModelConfigT
@@ -27,4 +36,16 @@ func (me ModelConfig) Init(config AppConfig) {
 	if me.CheckPrompt != nil {
 		me.CheckPrompt.Init(config)
 	}
+	if me.ExplainPrompt != nil {
+		me.ExplainPrompt.Init(config)
+	}
+	if me.CommentPrompt != nil {
+		me.CommentPrompt.Init(config)
+	}
+	if me.RefactorPrompt != nil {
+		me.RefactorPrompt.Init(config)
+	}
+	if me.ReviewPrompt != nil {
+		me.ReviewPrompt.Init(config)
+	}
 }
@@ -0,0 +1,56 @@
+package batchai
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/qiangyt/batchai/comm"
+)
+
+// ModelApiErrorT is the error responded by the model API
+type ModelApiErrorT struct {
+	StatusCode int
+	Header     http.Header
+	Body       string
+}
+
+type ModelApiError = *ModelApiErrorT
+
+func (me ModelApiError) Error() string {
+	return fmt.Sprintf("model API responded %d %s: %s", me.StatusCode, http.StatusText(me.StatusCode), me.Body)
+}
+
+// postModelApi posts the request body as json to the model API, and returns the response if succeeded.
+// The caller is responsible to close the response body.
+func postModelApi(x Kontext, httpClient *http.Client, url string, headers map[string]string, requestBody any) *http.Response {
+	body := comm.ToJsonP(requestBody, false)
+
+	req, err := http.NewRequestWithContext(x.Context, http.MethodPost, url, bytes.NewBufferString(body))
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to create request: %s", url))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to call model API: %s", url))
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		panic(&ModelApiErrorT{StatusCode: resp.StatusCode, Header: resp.Header, Body: string(respBody)})
+	}
+
+	return resp
+}
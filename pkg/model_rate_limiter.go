@@ -0,0 +1,108 @@
+package batchai
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type modelRateRecordT struct {
+	at     time.Time
+	tokens int64
+}
+
+type modelRateRecord = *modelRateRecordT
+
+// ModelRateLimiterT limits the requests-per-minute and tokens-per-minute sent to a model,
+// with a sliding window of the requests made recently
+type ModelRateLimiterT struct {
+	requestsPerMinute int
+	tokensPerMinute   int64
+	window            time.Duration
+
+	mutex   sync.Mutex
+	records []modelRateRecord
+}
+
+type ModelRateLimiter = *ModelRateLimiterT
+
+func NewModelRateLimiter(requestsPerMinute int, tokensPerMinute int64) ModelRateLimiter {
+	return &ModelRateLimiterT{
+		requestsPerMinute: requestsPerMinute,
+		tokensPerMinute:   tokensPerMinute,
+		window:            time.Minute,
+	}
+}
+
+func (me ModelRateLimiter) Enabled() bool {
+	return me.requestsPerMinute > 0 || me.tokensPerMinute > 0
+}
+
+// Wait blocks until a request of the estimated tokens is allowed, and returns the record
+// of it so that the actual tokens could be updated once the model answered.
+func (me ModelRateLimiter) Wait(x Kontext, estimatedTokens int64) modelRateRecord {
+	for {
+		r, delay := me.tryAcquire(estimatedTokens)
+		if r != nil {
+			return r
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-x.Context.Done():
+			panic(errors.Wrap(x.Context.Err(), "canceled while waiting for the rate limit"))
+		}
+	}
+}
+
+func (me ModelRateLimiter) tryAcquire(estimatedTokens int64) (modelRateRecord, time.Duration) {
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+
+	now := time.Now()
+
+	// forget the records out of the window
+	i := 0
+	for ; i < len(me.records); i++ {
+		if now.Sub(me.records[i].at) < me.window {
+			break
+		}
+	}
+	me.records = me.records[i:]
+
+	allowed := true
+	if me.requestsPerMinute > 0 && len(me.records) >= me.requestsPerMinute {
+		allowed = false
+	}
+	if me.tokensPerMinute > 0 && len(me.records) > 0 {
+		// a single request larger than the limit is still allowed once the window is empty
+		usedTokens := int64(0)
+		for _, record := range me.records {
+			usedTokens += record.tokens
+		}
+		if usedTokens+estimatedTokens > me.tokensPerMinute {
+			allowed = false
+		}
+	}
+
+	if !allowed {
+		return nil, me.records[0].at.Add(me.window).Sub(now)
+	}
+
+	r := &modelRateRecordT{at: now, tokens: estimatedTokens}
+	me.records = append(me.records, r)
+	return r, 0
+}
+
+// Update corrects the tokens of the request with the actual usage
+func (me ModelRateLimiter) Update(record modelRateRecord, actualTokens int64) {
+	if record == nil || actualTokens <= 0 {
+		return
+	}
+
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+
+	record.tokens = actualTokens
+}
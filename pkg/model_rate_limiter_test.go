@@ -0,0 +1,98 @@
+package batchai
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelRateLimiterRequestsPerMinute(t *testing.T) {
+	a := require.New(t)
+
+	limiter := NewModelRateLimiter(2, 0)
+	limiter.window = 50 * time.Millisecond
+	x := NewKontext(afero.NewMemMapFs())
+
+	startTime := time.Now()
+	limiter.Wait(x, 0)
+	limiter.Wait(x, 0)
+	a.Less(time.Since(startTime), 50*time.Millisecond)
+
+	limiter.Wait(x, 0)
+	a.GreaterOrEqual(time.Since(startTime), 50*time.Millisecond)
+}
+
+func TestModelRateLimiterTokensPerMinute(t *testing.T) {
+	a := require.New(t)
+
+	limiter := NewModelRateLimiter(0, 100)
+	limiter.window = 50 * time.Millisecond
+	x := NewKontext(afero.NewMemMapFs())
+
+	// a single request larger than the limit is allowed when nothing else is in the window
+	startTime := time.Now()
+	record := limiter.Wait(x, 150)
+	a.Less(time.Since(startTime), 50*time.Millisecond)
+
+	// the actual usage is less than estimated
+	limiter.Update(record, 60)
+	limiter.Wait(x, 40)
+	a.Less(time.Since(startTime), 50*time.Millisecond)
+
+	limiter.Wait(x, 1)
+	a.GreaterOrEqual(time.Since(startTime), 50*time.Millisecond)
+}
+
+func TestModelClientMaxConcurrentRequests(t *testing.T) {
+	a := require.New(t)
+
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"hi"}],"usage":{"input_tokens":1,"output_tokens":1}}`)
+	}))
+	defer server.Close()
+
+	config := newTestModelConfig(MODEL_PROVIDER_ANTHROPIC, server.URL+"/")
+	config.MaxConcurrentRequests = 2
+	client := NewModelClient(config)
+
+	var wg sync.WaitGroup
+	answers := make([]ChatAnswer, 6)
+	for i := range answers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			answers[i], _ = client.Chat(NewKontext(afero.NewMemMapFs()), nil, false, newTestChatMemory(), nil)
+		}(i)
+	}
+	wg.Wait()
+
+	a.Equal(int32(2), maxInFlight)
+
+	queued := 0
+	for _, answer := range answers {
+		a.LessOrEqual(answer.InFlight, 2)
+		if answer.QueueWait > 10*time.Millisecond {
+			queued++
+		}
+	}
+	a.Greater(queued, 0)
+}
@@ -0,0 +1,128 @@
+package batchai
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/pkg/errors"
+)
+
+// ModelRetryConfigT is the retry policy applied when chatting with a model
+type ModelRetryConfigT struct {
+	MaxAttempts int           `mapstructure:"max_attempts"`
+	BaseDelay   time.Duration `mapstructure:"base_delay"`
+	MaxDelay    time.Duration `mapstructure:"max_delay"`
+	Jitter      float64       `mapstructure:"jitter"`
+}
+
+type ModelRetryConfig = *ModelRetryConfigT
+
+func (me ModelRetryConfig) GetMaxAttempts() int {
+	if me == nil || me.MaxAttempts <= 0 {
+		return 1
+	}
+	return me.MaxAttempts
+}
+
+// Delay returns how long to wait before the next attempt, with exponential backoff plus jitter.
+// The Retry-After hint of the server wins if it asks to wait longer.
+func (me ModelRetryConfig) Delay(attempt int, retryAfter time.Duration) time.Duration {
+	r := time.Duration(0)
+	if me != nil && me.BaseDelay > 0 {
+		r = time.Duration(float64(me.BaseDelay) * math.Pow(2, float64(attempt-1)))
+		if me.MaxDelay > 0 && r > me.MaxDelay {
+			r = me.MaxDelay
+		}
+		if me.Jitter > 0 {
+			r += time.Duration(rand.Float64() * me.Jitter * float64(r))
+		}
+	}
+
+	if retryAfter > r {
+		r = retryAfter
+	}
+	return r
+}
+
+// ErrTruncatedStream means the streamed answer ended before the model finished it
+var ErrTruncatedStream = errors.New("model API stream terminated unexpectedly")
+
+// ModelErrorT classifies an error of chatting with a model
+type ModelErrorT struct {
+	Cause      error
+	Retryable  bool
+	RetryAfter time.Duration
+}
+
+type ModelError = *ModelErrorT
+
+func (me ModelError) Error() string {
+	return me.Cause.Error()
+}
+
+func (me ModelError) Unwrap() error {
+	return me.Cause
+}
+
+// ClassifyModelError tells whether the error is transient (rate limit, 5xx, timeout, truncated stream)
+// and so worth retrying, or fatal (auth, bad request, etc.)
+func ClassifyModelError(err error) ModelError {
+	r := &ModelErrorT{Cause: err}
+
+	var apiErr ModelApiError
+	var openAiErr *openai.Error
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &apiErr):
+		r.Retryable = isRetryableStatusCode(apiErr.StatusCode)
+		r.RetryAfter = parseRetryAfter(apiErr.Header)
+	case errors.As(err, &openAiErr):
+		r.Retryable = isRetryableStatusCode(openAiErr.StatusCode)
+		if openAiErr.Response != nil {
+			r.RetryAfter = parseRetryAfter(openAiErr.Response.Header)
+		}
+	case errors.Is(err, ErrTruncatedStream), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		r.Retryable = true
+	case errors.Is(err, context.DeadlineExceeded):
+		r.Retryable = true
+	case errors.As(err, &netErr):
+		r.Retryable = netErr.Timeout()
+	}
+
+	return r
+}
+
+func isRetryableStatusCode(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests ||
+		statusCode == http.StatusRequestTimeout ||
+		statusCode >= 500
+}
+
+func parseRetryAfter(header http.Header) time.Duration {
+	if header == nil {
+		return 0
+	}
+
+	v := header.Get("Retry-After")
+	if len(v) == 0 {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(v); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,187 @@
+package batchai
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+func TestClassifyModelError(t *testing.T) {
+	a := require.New(t)
+
+	a.True(ClassifyModelError(&ModelApiErrorT{StatusCode: http.StatusTooManyRequests}).Retryable)
+	a.True(ClassifyModelError(&ModelApiErrorT{StatusCode: http.StatusBadGateway}).Retryable)
+	a.True(ClassifyModelError(errors.Wrap(&ModelApiErrorT{StatusCode: 529}, "wrapped")).Retryable)
+	a.False(ClassifyModelError(&ModelApiErrorT{StatusCode: http.StatusUnauthorized}).Retryable)
+	a.False(ClassifyModelError(&ModelApiErrorT{StatusCode: http.StatusBadRequest}).Retryable)
+
+	a.True(ClassifyModelError(errors.Wrap(ErrTruncatedStream, "messages API")).Retryable)
+	a.True(ClassifyModelError(io.ErrUnexpectedEOF).Retryable)
+	a.False(ClassifyModelError(errors.New("something else")).Retryable)
+
+	header := http.Header{}
+	header.Set("Retry-After", "7")
+	a.Equal(7*time.Second, ClassifyModelError(&ModelApiErrorT{StatusCode: http.StatusTooManyRequests, Header: header}).RetryAfter)
+}
+
+func TestModelRetryConfigDelay(t *testing.T) {
+	a := require.New(t)
+
+	var nilRetry ModelRetryConfig
+	a.Equal(1, nilRetry.GetMaxAttempts())
+	a.Equal(time.Duration(0), nilRetry.Delay(1, 0))
+
+	retry := &ModelRetryConfigT{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	a.Equal(time.Second, retry.Delay(1, 0))
+	a.Equal(2*time.Second, retry.Delay(2, 0))
+	a.Equal(4*time.Second, retry.Delay(3, 0))
+	a.Equal(5*time.Second, retry.Delay(4, 0))
+	a.Equal(10*time.Second, retry.Delay(1, 10*time.Second))
+
+	retry.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		d := retry.Delay(2, 0)
+		a.GreaterOrEqual(d, 2*time.Second)
+		a.LessOrEqual(d, 3*time.Second)
+	}
+}
+
+func TestModelClientRetry(t *testing.T) {
+	a := require.New(t)
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			fmt.Fprint(w, `{"content":[{"type":"text","text":"hi"}],"usage":{"input_tokens":1,"output_tokens":1}}`)
+		}
+	}))
+	defer server.Close()
+
+	config := newTestModelConfig(MODEL_PROVIDER_ANTHROPIC, server.URL+"/")
+	config.Retry = &ModelRetryConfigT{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	answer, _, _ := chatWithTestModel(t, config, false)
+	a.Equal("hi", answer.Content)
+	a.Equal(3, answer.Attempts)
+	a.Len(answer.RetryErrors, 2)
+	a.Equal(int32(3), requests)
+}
+
+func TestModelClientNoRetryOnFatalError(t *testing.T) {
+	a := require.New(t)
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	config := newTestModelConfig(MODEL_PROVIDER_GEMINI, server.URL+"/")
+	config.Retry = &ModelRetryConfigT{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	defer func() {
+		err, ok := recover().(ModelError)
+		a.True(ok)
+		a.False(err.Retryable)
+		a.Equal(int32(1), requests)
+	}()
+	chatWithTestModel(t, config, false)
+}
+
+func TestModelClientRetryStream(t *testing.T) {
+	a := require.New(t)
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events := []string{
+			"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":5,\"output_tokens\":1}}}",
+			"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"partial\\n\"}}",
+		}
+		// the first stream is truncated
+		if atomic.AddInt32(&requests, 1) > 1 {
+			events = append(events[:1],
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"done\"}}",
+				"event: message_stop\ndata: {\"type\":\"message_stop\"}")
+		}
+		writeTestEvents(w, events...)
+	}))
+	defer server.Close()
+
+	config := newTestModelConfig(MODEL_PROVIDER_ANTHROPIC, server.URL+"/")
+	config.Retry = &ModelRetryConfigT{MaxAttempts: 2, BaseDelay: time.Millisecond}
+
+	outputs := []*bytes.Buffer{}
+	newWriter := func() io.Writer {
+		outputs = append(outputs, &bytes.Buffer{})
+		return outputs[len(outputs)-1]
+	}
+
+	answer, _ := NewModelClient(config).Chat(NewKontext(afero.NewMemMapFs()), comm.NewConsole(true), true, newTestChatMemory(), newWriter)
+	a.Equal(2, answer.Attempts)
+	a.Equal("done", answer.Content)
+
+	// streamed as is, and the retry writes to a fresh writer
+	a.Len(outputs, 2)
+	a.Equal("partial\n", outputs[0].String())
+	a.Equal("done\n", outputs[1].String())
+}
+
+func TestModelClientReleaseSlotWhileRetrying(t *testing.T) {
+	a := require.New(t)
+
+	var lock sync.Mutex
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		message := readTestRequest(t, r)["messages"].([]any)[0].(map[string]any)["content"].(string)
+
+		lock.Lock()
+		requests = append(requests, message)
+		throttled := len(requests) == 1
+		lock.Unlock()
+
+		if throttled {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"hi"}],"usage":{"input_tokens":1,"output_tokens":1}}`)
+	}))
+	defer server.Close()
+
+	config := newTestModelConfig(MODEL_PROVIDER_ANTHROPIC, server.URL+"/")
+	config.MaxConcurrentRequests = 1
+	config.Retry = &ModelRetryConfigT{MaxAttempts: 2, BaseDelay: 300 * time.Millisecond}
+	client := NewModelClient(config)
+	x := NewKontext(afero.NewMemMapFs())
+
+	var wg sync.WaitGroup
+	for _, message := range []string{"throttled", "other"} {
+		wg.Add(1)
+		go func(message string) {
+			defer wg.Done()
+			client.Chat(x, comm.NewConsole(true), false, NewChatMemory().AddUserMessage(message), nil)
+		}(message)
+		time.Sleep(50 * time.Millisecond)
+	}
+	wg.Wait()
+
+	// the other request is sent while the throttled one is waiting to retry
+	a.Equal([]string{"throttled", "other", "throttled"}, requests)
+}
@@ -2,7 +2,6 @@ package batchai
 
 import (
 	"fmt"
-	"io"
 
 	"github.com/qiangyt/batchai/comm"
 )
@@ -34,18 +33,47 @@ func (me ModelService) loadClient(modelId string) ModelClient {
 
 func (me ModelService) GetContextWindowSize(modelId string) int {
 	modelClient := me.loadClient(modelId)
-	return int(modelClient.config.ContextWindow)
+	return int(modelClient.Config().ContextWindow)
 }
 
-func (me ModelService) Chat(x Kontext, c comm.Console, modelId string, saveIntoMemory bool, memory ChatMemory, writer io.Writer) (string, ModelUsageMetrics) {
+// RemainingPromptTokens returns how many tokens can still be added into the memory without overflowing the context
+// window, with the completion tokens reserved. It's -1 if the context window is not configured.
+func (me ModelService) RemainingPromptTokens(modelId string, memory ChatMemory) int {
+	modelClient := me.loadClient(modelId)
+
+	cfg := modelClient.Config()
+	if cfg.ContextWindow <= 0 {
+		return -1
+	}
+
+	reserved := cfg.MaxCompletionTokens
+	if reserved <= 0 {
+		reserved = cfg.ContextWindow / 4
+	}
+
+	r := int(cfg.ContextWindow-reserved) - modelClient.EvaluatedTokens(memory.Format())
+	return max(r, 0)
+}
+
+func (me ModelService) EvaluatedTokens(modelId string, text string) int {
+	return me.loadClient(modelId).EvaluatedTokens(text)
+}
+
+func (me ModelService) Chat(x Kontext, c comm.Console, modelId string, saveIntoMemory bool, memory ChatMemory, newWriter ChatWriterFactory) (string, ModelUsageMetrics) {
 	metrics := NewModelUsageMetrics()
 
 	modelClient := me.loadClient(modelId)
 
-	chatCompletion, duration := modelClient.Chat(x, c, saveIntoMemory, memory, writer)
+	answer, duration := modelClient.Chat(x, c, saveIntoMemory, memory, newWriter)
 
 	metrics.Duration = duration
-	metrics.OpenAiUsage = &chatCompletion.Usage
+	metrics.Attempts = answer.Attempts
+	metrics.RetryErrors = answer.RetryErrors
+	metrics.QueueWait = answer.QueueWait
+	metrics.MaxInFlight = answer.InFlight
+	if answer.Usage != nil {
+		metrics.Usage = answer.Usage
+	}
 
-	return chatCompletion.Choices[0].Message.Content, metrics
+	return answer.Content, metrics
 }
@@ -3,21 +3,35 @@ package batchai
 import (
 	"time"
 
-	"github.com/openai/openai-go"
 	"github.com/qiangyt/batchai/comm"
 )
 
+type TokenUsageT struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+type TokenUsage = *TokenUsageT
+
 type ModelUsageMetricsT struct {
-	Duration time.Duration
+	Duration time.Duration `json:"duration"`
 	// EvaluatedPromptTokens int
-	OpenAiUsage *openai.CompletionUsage
+	Usage    TokenUsage `json:"usage"`
+	Attempts int        `json:"attempts"`
+	// errors of the failed attempts that were retried
+	RetryErrors []string `json:"retry_errors,omitempty"`
+	// time spent waiting for a request slot or the rate limit
+	QueueWait time.Duration `json:"queue_wait"`
+	// the most requests in flight to the model observed when a request was made
+	MaxInFlight int `json:"max_in_flight"`
 }
 
 type ModelUsageMetrics = *ModelUsageMetricsT
 
 func NewModelUsageMetrics() ModelUsageMetrics {
 	return &ModelUsageMetricsT{
-		OpenAiUsage: &openai.CompletionUsage{},
+		Usage: &TokenUsageT{},
 	}
 }
 
@@ -26,24 +40,47 @@ func (me ModelUsageMetrics) IncreaseUsage(usage ModelUsageMetrics) {
 		return
 	}
 
-	totalUsage := me.OpenAiUsage
-	newUsage := usage.OpenAiUsage
+	totalUsage := me.Usage
+	newUsage := usage.Usage
 
-	totalUsage.CompletionTokens += newUsage.CompletionTokens
-	totalUsage.PromptTokens += newUsage.PromptTokens
-	totalUsage.TotalTokens += newUsage.TotalTokens
+	if newUsage != nil {
+		totalUsage.CompletionTokens += newUsage.CompletionTokens
+		totalUsage.PromptTokens += newUsage.PromptTokens
+		totalUsage.TotalTokens += newUsage.TotalTokens
+	}
 
 	// me.EvaluatedPromptTokens += usage.EvaluatedPromptTokens
 	me.Duration += usage.Duration
+	me.Attempts += usage.Attempts
+	me.RetryErrors = append(me.RetryErrors, usage.RetryErrors...)
+	me.QueueWait += usage.QueueWait
+	if usage.MaxInFlight > me.MaxInFlight {
+		me.MaxInFlight = usage.MaxInFlight
+	}
+}
+
+func (me ModelUsageMetrics) Retries() int {
+	return len(me.RetryErrors)
 }
 
 func (me ModelUsageMetrics) Print(console comm.Console, color comm.Color) {
 	console.NewLine().Colorf(color, "Duration: %v", comm.FormatDurationForConsole(me.Duration))
 	// console.NewLine().Colorf(color, "Evaluated prompt tokens: %v", me.EvaluatedPromptTokens)
 
-	if usage := me.OpenAiUsage; usage != nil {
+	if usage := me.Usage; usage != nil {
 		console.NewLine().Colorf(color, "Prompt tokens: %v", usage.PromptTokens)
 		console.NewLine().Colorf(color, "Completion tokens: %v", usage.CompletionTokens)
 		console.NewLine().Colorf(color, "Total tokens: %v", usage.TotalTokens)
 	}
+
+	if me.QueueWait > 0 {
+		console.NewLine().Colorf(color, "Queue wait: %v", comm.FormatDurationForConsole(me.QueueWait))
+	}
+	if me.MaxInFlight > 1 {
+		console.NewLine().Colorf(color, "Max in-flight requests: %v", me.MaxInFlight)
+	}
+
+	if retries := me.Retries(); retries > 0 {
+		console.NewLine().Colorf(color, "Retries: %v (in %v attempts)", retries, me.Attempts)
+	}
 }
@@ -0,0 +1,133 @@
+package batchai
+
+import (
+	"io"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/pkg/errors"
+)
+
+type OpenAiProviderT struct {
+	config          ModelConfig
+	client          *openai.Client
+	streamingClient *openai.Client
+}
+
+type OpenAiProvider = *OpenAiProviderT
+
+func NewOpenAiProvider(config ModelConfig) OpenAiProvider {
+	return &OpenAiProviderT{
+		config:          config,
+		client:          buildOpenAiClient(config, false),
+		streamingClient: buildOpenAiClient(config, true),
+	}
+}
+
+func (me OpenAiProvider) newParams(memory ChatMemory, seed int64, requestedMaxCompletionTokens int64) openai.ChatCompletionNewParams {
+	params := openai.ChatCompletionNewParams{
+		Messages:    openai.F(memory.ToChatCompletionMessageParamUnion()),
+		Temperature: openai.F(me.config.Temperature),
+		Seed:        openai.Int(seed),
+		Model:       openai.F(me.config.Name),
+	}
+	if requestedMaxCompletionTokens > 0 {
+		params.MaxCompletionTokens = openai.Int(requestedMaxCompletionTokens)
+	}
+	return params
+}
+
+func (me OpenAiProvider) Chat(x Kontext, memory ChatMemory, requestedMaxCompletionTokens int64) ChatAnswer {
+	params := me.newParams(memory, 1, requestedMaxCompletionTokens)
+
+	r, err := me.client.Chat.Completions.New(x.Context, params)
+	if err != nil {
+		panic(errors.Wrap(err, "failed to call chat completions API"))
+	}
+	return openAiChatAnswer(r)
+}
+
+func (me OpenAiProvider) ChatStream(x Kontext, memory ChatMemory, output io.Writer, requestedMaxCompletionTokens int64) ChatAnswer {
+	params := me.newParams(memory, 0, requestedMaxCompletionTokens)
+
+	stream := me.streamingClient.Chat.Completions.NewStreaming(x.Context, params)
+
+	// optionally, an accumulator helper can be used
+	acc := &openai.ChatCompletionAccumulator{}
+
+	w := NewStreamLineWriter(output)
+
+	for stream.Next() {
+		chunk := stream.Current()
+		acc.AddChunk(chunk)
+
+		// if content, ok := acc.JustFinishedContent(); ok {
+		// 	println("Content stream finished:", content)
+		// }
+
+		// if using tool calls
+		// if tool, ok := acc.JustFinishedToolCall(); ok {
+		// 	println("Tool call stream finished:", tool.Index, tool.Name, tool.Arguments)
+		// }
+
+		if refusal, ok := acc.JustFinishedRefusal(); ok {
+			panic(errors.New("Refusalstream finished:" + refusal))
+		}
+
+		// it's best to use chunks after handling JustFinished events
+		// Process chunk content
+		if len(chunk.Choices) > 0 {
+			w.WriteChunk(chunk.Choices[0].Delta.Content)
+		}
+	}
+
+	w.Flush()
+
+	if err := stream.Err(); err != nil {
+		panic(err)
+	}
+	if len(acc.Choices) == 0 || len(acc.Choices[0].FinishReason) == 0 {
+		panic(errors.Wrap(ErrTruncatedStream, "chat completions API"))
+	}
+
+	// After the stream is finished, acc can be used like a ChatCompletion
+	return openAiChatAnswer(&acc.ChatCompletion)
+}
+
+func openAiChatAnswer(completion *openai.ChatCompletion) ChatAnswer {
+	if len(completion.Choices) == 0 {
+		panic(errors.New("no choice in chat completion"))
+	}
+
+	return &ChatAnswerT{
+		Content: completion.Choices[0].Message.Content,
+		Usage: &TokenUsageT{
+			PromptTokens:     completion.Usage.PromptTokens,
+			CompletionTokens: completion.Usage.CompletionTokens,
+			TotalTokens:      completion.Usage.TotalTokens,
+		},
+	}
+}
+
+func buildOpenAiClient(model ModelConfig, streaming bool) *openai.Client {
+	// retries are handled by DefaultModelClient according to the retry policy of the model
+	options := []option.RequestOption{option.WithMaxRetries(0)}
+	if len(model.ApiKey) > 0 {
+		options = append(options, option.WithAPIKey(model.ApiKey))
+	}
+	if len(model.BaseUrl) > 0 {
+		options = append(options, option.WithBaseURL(model.BaseUrl))
+	}
+
+	if !streaming {
+		if model.Timeout.Seconds() > 0 {
+			options = append(options, option.WithRequestTimeout(model.Timeout))
+		}
+	}
+
+	if httpClient := buildHttpClient(model); httpClient != nil {
+		options = append(options, option.WithHTTPClient(httpClient))
+	}
+
+	return openai.NewClient(options...)
+}
@@ -0,0 +1,179 @@
+package batchai
+
+import (
+	"io"
+	"strings"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+type RefactorResultT struct {
+	Report  RefactorReport
+	Skipped bool
+	Failed  bool
+}
+
+type RefactorResult = *RefactorResultT
+
+type RefactorAgentT struct {
+	SymbolAwareAgentT
+
+	reportManager RefactorReportManager
+
+	file         string
+	relativeFile string
+}
+
+type RefactorAgent = *RefactorAgentT
+
+func NewRefactorAgent(reportManager RefactorReportManager,
+	symbolManager SymbolManager,
+	modelService ModelService,
+	codeFile string,
+) RefactorAgent {
+	return &RefactorAgentT{
+		SymbolAwareAgentT: newSymbolAwareAgent(symbolManager, modelService),
+		reportManager:     reportManager,
+
+		file: codeFile,
+	}
+}
+
+func (me RefactorAgent) run(x Kontext, refactorArgs RefactorArgs, resultChan chan<- RefactorResult, output func(text string)) {
+	c := comm.NewConsole(!x.Args.Concurrent).WithOutput(output)
+	me.relativeFile = me.file[len(x.Args.Repository)+1:]
+
+	c.Begin()
+	defer c.End()
+	c.Greenf("\n\n▹▹▹▹▹ processing: %s\n", me.relativeFile)
+
+	defer func() {
+		if e := recover(); e != nil {
+			c.NewLine().Red("failed: ").Defaultf("%v, %+v", me.relativeFile, e)
+			resultChan <- &RefactorResultT{Failed: true}
+		}
+	}()
+
+	result := me.refactorFile(x, refactorArgs, c)
+
+	resultChan <- result
+}
+
+func (me RefactorAgent) Run(x Kontext, refactorArgs RefactorArgs, resultChan chan<- RefactorResult, pool comm.WorkerPool) {
+	if !x.Args.Concurrent {
+		me.run(x, refactorArgs, resultChan, nil)
+		return
+	}
+
+	pool.Submit(func(output func(text string)) {
+		me.run(x, refactorArgs, resultChan, output)
+	})
+}
+
+func (me RefactorAgent) refactorFile(x Kontext, refactorArgs RefactorArgs, c comm.Console) RefactorResult {
+	c.NewLine().Green("--------------------")
+	c.NewLine().Greenln(me.relativeFile)
+
+	newCode := comm.ReadFileCodeP(x.Fs, me.file)
+
+	lastReport := me.reportManager.LoadReport(x, me.file)
+	if lastReport != nil && lastReport.SameGoals(refactorArgs.Goals) {
+		noCodeChanges := (newCode == lastReport.OriginalCode)
+		alreadyRefactored := lastReport.HasChange && (newCode == lastReport.RefactoredCode)
+		if noCodeChanges || alreadyRefactored {
+			if !x.Args.Force {
+				c.NewLine().Default("✔ no code changes since last execution, skipped")
+				return &RefactorResultT{Report: lastReport, Skipped: true}
+			}
+		}
+	}
+
+	newReport := me.refactorCode(x, c, refactorArgs, newCode)
+	newReport.Print(c)
+
+	if newReport.HasChange {
+		// replace the original code file with refactored code
+		comm.WriteFileTextP(x.Fs, me.file, newReport.RefactoredCode)
+	}
+
+	reportFile := me.reportManager.SaveReport(x, me.file, newReport)
+	c.NewLine().Blue("✔ report: ").Default(reportFile[len(x.Args.Repository)+1:])
+
+	return &RefactorResultT{Report: newReport, Skipped: false}
+}
+
+type RefactorCodeWriterT struct {
+	console        comm.Console
+	inRefactorCode bool
+}
+
+type RefactorCodeWriter = *RefactorCodeWriterT
+
+func NewRefactorCodeWriter(console comm.Console) RefactorCodeWriter {
+	return &RefactorCodeWriterT{
+		console:        console,
+		inRefactorCode: false,
+	}
+}
+
+// Write method to implement io.Writer
+func (me RefactorCodeWriter) Write(p []byte) (n int, err error) {
+	s := string(p)
+
+	if me.inRefactorCode {
+		if strings.Contains(s, REFACTOR_END) {
+			me.inRefactorCode = false
+		} else {
+			me.console.Default(s)
+		}
+	} else {
+		if strings.Contains(s, REFACTOR_BEGIN) {
+			me.inRefactorCode = true
+		}
+	}
+
+	return len(p), nil
+}
+
+func (me RefactorAgent) refactorCode(x Kontext, c comm.Console, refactorArgs RefactorArgs, code string) RefactorReport {
+	verbose := x.Args.Verbose
+
+	sysPrompt := x.Config.Refactor.RenderPrompt(refactorArgs.Goals, code, me.relativeFile)
+	if x.Args.EnableSymbolReference {
+		sysPrompt = me.provideSymbols(x, c, x.Config.Refactor.ModelId, me.file, code, sysPrompt)
+	}
+	mem := me.memory
+	mem.AddSystemMessage(sysPrompt)
+
+	if x.Args.EnableSymbolReference {
+		mem.AddUserMessage("refactor the code, with provided symbols as references")
+	} else {
+		mem.AddUserMessage("refactor the code")
+	}
+	if verbose {
+		c.NewLine().Gray("chat: ").Default("refactor the code")
+	}
+
+	answer, metrics := me.modelService.Chat(x, c, x.Config.Refactor.ModelId, true, mem, func() io.Writer { return NewRefactorCodeWriter(c) })
+	if verbose {
+		c.NewLine().Gray("answer: ").Default(mem.Format())
+	}
+
+	refactoredCode, remainedAnswer := ExtractRefactoredCode(answer)
+	refactoredCode = comm.NormalizeCode(refactoredCode)
+
+	r := ExtractRefactorReport(remainedAnswer, strings.HasSuffix(me.relativeFile, ".go"))
+	r.ModelUsageMetrics = metrics
+	r.Goals = refactorArgs.Goals
+	r.RefactoredCode = refactoredCode
+	r.OriginalCode = code
+	r.Path = me.relativeFile
+
+	if !r.HasChange || refactoredCode == code || len(refactoredCode) == 0 {
+		r.HasChange = false
+		r.Changes = []RefactorChange{}
+		r.RefactoredCode = code
+	}
+
+	return r
+}
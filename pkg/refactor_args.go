@@ -0,0 +1,79 @@
+package batchai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+type RefactorArgsT struct {
+	Goals []string
+}
+
+type RefactorArgs = *RefactorArgsT
+
+func (me RefactorArgs) WithCliContext(x Kontext, cliContext *cli.Context) error {
+	me.Goals = []string{}
+	for _, goal := range cliContext.StringSlice("goal") {
+		goal = strings.TrimSpace(goal)
+		if len(goal) > 0 {
+			me.Goals = append(me.Goals, goal)
+		}
+	}
+
+	if len(me.Goals) == 0 {
+		return errors.New("please specifies the refactoring goal")
+	}
+	return nil
+}
+
+func RefactorUrfaveCommand(x Kontext) *cli.Command {
+	goalNames := []string{}
+	if x.Config.Refactor != nil {
+		for name := range x.Config.Refactor.Goals {
+			goalNames = append(goalNames, name)
+		}
+		sort.Strings(goalNames)
+	}
+
+	return &cli.Command{
+		Name:  "refactor",
+		Usage: "Refactors the code towards the specified goals",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "goal",
+				Aliases: []string{"g"},
+				Usage:   fmt.Sprintf("Refactoring goal, either free text or one of the built-in goals: %s", strings.Join(goalNames, ", ")),
+			},
+		},
+		Action: RefactorFunc(x),
+	}
+}
+
+func RefactorFunc(x Kontext) func(*cli.Context) error {
+	return func(cliContext *cli.Context) error {
+		x.Config.Init("refactor")
+
+		a := &AppArgsT{}
+		if err := a.WithCliContext(x, cliContext); err != nil {
+			return err
+		}
+		x.Args = a
+
+		ra := &RefactorArgsT{}
+		if err := ra.WithCliContext(x, cliContext); err != nil {
+			return err
+		}
+
+		if err := a.EnsureNoUnstagedFiles(x, "refactor"); err != nil {
+			return err
+		}
+
+		NewRefactorCommand(x).Refactor(x, ra)
+
+		return nil
+	}
+}
@@ -0,0 +1,72 @@
+package batchai
+
+import (
+	"github.com/qiangyt/batchai/comm"
+)
+
+type RefactorCommandT struct {
+	BaseModelCommandT
+	reportManager RefactorReportManager
+}
+
+type RefactorCommand = *RefactorCommandT
+
+func NewRefactorCommand(x Kontext) RefactorCommand {
+	return &RefactorCommandT{
+		BaseModelCommandT: *NewBaseModelCommand(x),
+		reportManager:     NewRefactorReportManager(),
+	}
+}
+
+func (me RefactorCommand) launchRefactorAgents(x Kontext, refactorArgs RefactorArgs, targetFiles []string, metrics RefactorMetrics) {
+	// launch refactor agents and wait for them
+	resultChan := make(chan RefactorResult, len(targetFiles))
+
+	for _, f := range targetFiles {
+		metrics.Processed++
+
+		agent := NewRefactorAgent(me.reportManager, me.symbolManager, me.modelService, f)
+		agent.Run(x, refactorArgs, resultChan, me.workerPool)
+	}
+
+	me.workerPool.Wait()
+	close(resultChan)
+
+	for r := range resultChan {
+		if r.Failed {
+			metrics.Failed++
+		} else if r.Skipped {
+			metrics.Skipped++
+		} else {
+			metrics.Succeeded++
+
+			report := r.Report
+			metrics.ModelUsageMetricsT.IncreaseUsage(report.ModelUsageMetrics)
+
+			if report.HasChange {
+				metrics.Refactored++
+				metrics.TotalChanges += len(report.Changes)
+			}
+		}
+	}
+}
+
+func (me RefactorCommand) Refactor(x Kontext, refactorArgs RefactorArgs) {
+	metrics := NewRefactorMetrics()
+	c := comm.NewConsole(!x.Args.Concurrent)
+
+	c.NewLine().Default("refactor command uses model ").Yellowf("'%s'\n\n", x.Config.Refactor.ModelId)
+
+	targetFiles, ignored, failed, repoFiles := me.listCommand.CollectWorkingFiles(x, c)
+	metrics.WithWorkingFiles(targetFiles, ignored, failed)
+	if len(targetFiles) > 0 {
+		if x.Args.EnableSymbolReference {
+			me.launchSymbolAgents(x, repoFiles)
+		}
+		me.launchRefactorAgents(x, refactorArgs, targetFiles, metrics)
+	}
+
+	c.NewLine()
+	metrics.Print(c)
+	WriteMetricsJson(x, c, metrics)
+}
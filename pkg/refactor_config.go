@@ -0,0 +1,57 @@
+package batchai
+
+import (
+	"fmt"
+)
+
+type RefactorConfigT struct {
+	AppConfig AppConfig
+	ModelId   string            `mapstructure:"model_id"`
+	Goals     map[string]string `mapstructure:"goals"`
+	Prompt    RefactorPrompt    `mapstructure:"prompt"`
+	Includes  []string          `mapstructure:"includes"`
+}
+
+type RefactorConfig = *RefactorConfigT
+
+func (me RefactorConfig) Init(config AppConfig) {
+	me.AppConfig = config
+
+	model := config.LoadModel(me.ModelId)
+
+	if me.Prompt == nil {
+		if model.RefactorPrompt == nil {
+			panic(fmt.Errorf("missing code refactor prompt for model: %s", me.ModelId))
+		}
+		me.Prompt = model.RefactorPrompt
+	} else {
+		me.Prompt.Init(config)
+	}
+
+	if me.Goals == nil {
+		me.Goals = map[string]string{}
+	}
+}
+
+// ResolveGoals translates the built-in goal names to be their descriptions,
+// while the others are taken as free text goals
+func (me RefactorConfig) ResolveGoals(goals []string) []string {
+	r := make([]string, 0, len(goals))
+	for _, goal := range goals {
+		if description, has := me.Goals[goal]; has && len(description) > 0 {
+			r = append(r, fmt.Sprintf("%s: %s", goal, description))
+		} else {
+			r = append(r, goal)
+		}
+	}
+	return r
+}
+
+func (me RefactorConfig) RenderPrompt(goals []string, codeToRefactor string, codeFile string) string {
+	vars := NewRefactorPromptVariables().
+		WithGoals(me.ResolveGoals(goals)).
+		WithPath(codeFile).
+		WithLang(me.AppConfig.Lang).
+		WithCodeToRefactor(codeToRefactor)
+	return me.Prompt.Generate(vars)
+}
@@ -0,0 +1,27 @@
+package batchai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefactorConfigResolveGoals(t *testing.T) {
+	a := require.New(t)
+
+	cfg := &RefactorConfigT{Goals: map[string]string{"extract-method": "extract methods"}}
+
+	goals := cfg.ResolveGoals([]string{"extract-method", "make it readable"})
+	a.Equal([]string{"extract-method: extract methods", "make it readable"}, goals)
+}
+
+func TestExtractRefactoredCode(t *testing.T) {
+	a := require.New(t)
+
+	answer := "```json\n{\"has_change\": true}\n```\n!!!!refactor_begin!!!!\n```go\nabc\n```\n!!!!refactor_end!!!!"
+	code, remaining := ExtractRefactoredCode(answer)
+	a.Equal("abc\n", code)
+
+	r := ExtractRefactorReport(remaining, true)
+	a.True(r.HasChange)
+}
@@ -0,0 +1,34 @@
+package batchai
+
+import (
+	"github.com/qiangyt/batchai/comm"
+)
+
+type RefactorMetricsT struct {
+	BaseMetricsT
+
+	Refactored   int `json:"refactored"`
+	TotalChanges int `json:"total_changes"`
+}
+
+type RefactorMetrics = *RefactorMetricsT
+
+func NewRefactorMetrics() RefactorMetrics {
+	return &RefactorMetricsT{
+		BaseMetricsT: *NewBaseMetrics(),
+	}
+}
+
+func (me RefactorMetrics) Print(console comm.Console) {
+	me.PreparePrint(console)
+
+	console.NewLine().Greenf("Files: %d, Processed: %d, Ignored: %d, Failed: %d, Refactored: %d, Total Changes: %d, Skipped: %d",
+		me.Files,
+		me.Processed,
+		me.Ignored,
+		me.Failed,
+		me.Refactored,
+		me.TotalChanges,
+		me.Skipped,
+	)
+}
@@ -0,0 +1,80 @@
+package batchai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+type RefactorPromptVariablesT struct {
+	Data map[string]any
+}
+
+type RefactorPromptVariables = *RefactorPromptVariablesT
+
+const (
+	REFACTOR_BEGIN      = "!!!!refactor_begin!!!!"
+	REFACTOR_BEGIN_LINE = REFACTOR_BEGIN + "\n"
+	REFACTOR_END        = "!!!!refactor_end!!!!"
+	REFACTOR_END_LINE   = REFACTOR_END
+)
+
+func NewRefactorPromptVariables() RefactorPromptVariables {
+	return &RefactorPromptVariablesT{Data: map[string]any{
+		"refactor_begin":              REFACTOR_BEGIN,
+		"refactor_end":                REFACTOR_END,
+		"refactor_report_json_format": REFACTOR_REPORT_JSON_FORMAT,
+	}}
+}
+
+func (me RefactorPromptVariables) WithGoals(goals []string) RefactorPromptVariables {
+	lines := make([]string, len(goals))
+	for i, goal := range goals {
+		lines[i] = fmt.Sprintf("%d) %s", i+1, goal)
+	}
+	me.Data["goals"] = strings.Join(lines, "\n")
+	return me
+}
+
+func (me RefactorPromptVariables) WithCodeToRefactor(codeToRefactor string) RefactorPromptVariables {
+	me.Data["code_to_refactor"] = codeToRefactor
+	return me
+}
+
+func (me RefactorPromptVariables) WithLang(lang string) RefactorPromptVariables {
+	me.Data["lang"] = lang
+	return me
+}
+
+func (me RefactorPromptVariables) WithPath(path string) RefactorPromptVariables {
+	me.Data["path"] = path
+	return me
+}
+
+type RefactorPromptT struct {
+	Rules    []string `mapstructure:"rules"`
+	Template string   `mapstructure:"template"`
+}
+
+type RefactorPrompt = *RefactorPromptT
+
+func (me RefactorPrompt) Init(config AppConfig) {
+	me.Rules = comm.StringArrayTrimSpace(me.Rules)
+	for i, rule := range me.Rules {
+		me.Rules[i] = fmt.Sprintf("## %s\n", rule)
+	}
+
+	me.Template = strings.TrimSpace(me.Template)
+}
+
+func (me RefactorPrompt) Generate(vars RefactorPromptVariables) string {
+	data := vars.Data
+
+	rules := comm.RenderAsTemplateArrayP(me.Rules, data)
+	if len(rules) > 0 {
+		data["refactor_rules"] = strings.Join(rules, "\n")
+	}
+
+	return comm.RenderAsTemplateP(me.Template, data)
+}
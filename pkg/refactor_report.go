@@ -0,0 +1,109 @@
+package batchai
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+const REFACTOR_REPORT_JSON_FORMAT = `
+{
+  "has_change": true or false,
+  "changes": [
+    {
+	   "short_description": "...",
+	   "reason": "...",
+	   "change_line_begin": 9,
+	   "change_line_end": 12
+    },
+	{
+	   "short_description": "...",
+	   "reason": "...",
+	   "change_line_begin": 24,
+	   "change_line_end": 29
+    }
+  ]
+}`
+
+type RefactorChangeT struct {
+	ShortDescription string `json:"short_description"`
+	Reason           string `json:"reason"`
+	ChangeLineBegin  int    `json:"change_line_begin"`
+	ChangeLineEnd    int    `json:"change_line_end"`
+}
+
+type RefactorChange = *RefactorChangeT
+
+func (me RefactorChange) Print(console comm.Console) {
+	console.NewLine().Printf("Short Description: %s", me.ShortDescription)
+	console.NewLine().Printf("Reason: %s", me.Reason)
+	console.NewLine().Printf("ChangeLineBegin: %d", me.ChangeLineBegin)
+	console.NewLine().Printf("ChangeLineEnd: %d", me.ChangeLineEnd)
+}
+
+type RefactorReportT struct {
+	HasChange         bool              `json:"has_change"`
+	Goals             []string          `json:"goals"`
+	Changes           []RefactorChange  `json:"changes"`
+	RefactoredCode    string            `json:"refactored_code"`
+	OriginalCode      string            `json:"original_code"`
+	Path              string            `json:"path"`
+	ModelUsageMetrics ModelUsageMetrics `json:"model_usage_metrics"`
+}
+
+type RefactorReport = *RefactorReportT
+
+func ExtractRefactoredCode(input string) (string, string) {
+	return ExtractMarkedCode(input, REFACTOR_BEGIN_LINE, REFACTOR_END_LINE)
+}
+
+func ExtractRefactorReport(answer string, isGolang bool) RefactorReport {
+	jsonStr, _ := comm.ExtractMarkdownJsonBlocksP(answer)
+
+	indexOfLeftBrace := strings.Index(jsonStr, "{")
+	if indexOfLeftBrace < 0 {
+		panic(errors.New("invalid json format - missing left brace"))
+	}
+	jsonStr = jsonStr[indexOfLeftBrace:]
+
+	indexOfRightBrace := strings.LastIndex(jsonStr, "}")
+	if indexOfRightBrace <= 0 {
+		panic(errors.New("invalid json format - missing right brace"))
+	}
+	jsonStr = jsonStr[:indexOfRightBrace+1]
+
+	report := &RefactorReportT{}
+	if err := comm.FromJson(jsonStr, false, report); err != nil {
+		jsonStr = comm.FixJson(jsonStr, isGolang)
+		comm.FromJsonP(jsonStr, false, report)
+	}
+	return report
+}
+
+// SameGoals tells whether or not the report was made for the same goals
+func (me RefactorReport) SameGoals(goals []string) bool {
+	return comm.SliceEquals(me.Goals, goals)
+}
+
+func (me RefactorReport) Print(console comm.Console) {
+	if !me.HasChange {
+		console.NewLine().Print("no change")
+		return
+	}
+
+	console2 := console.NewIndented()
+
+	me.ModelUsageMetrics.Print(console, comm.DEFAULT_COLOR)
+
+	console.NewLine().Print("Total ").Yellowf("%d", len(me.Changes)).Default(" changes")
+	for i, change := range me.Changes {
+		console2.Printf("\n#%d", i+1)
+		change.Print(console2)
+	}
+
+	console.NewLine().Print("Refactor:")
+	console.NewLine()
+
+	PrintCodeDiff(console2, "original", "refactored", me.OriginalCode, me.RefactoredCode)
+}
@@ -0,0 +1,65 @@
+package batchai
+
+import (
+	"path"
+	"sync"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+type RefactorReportManagerT struct {
+	reportsMapByFile map[string]RefactorReport
+	lock             sync.Mutex
+}
+
+type RefactorReportManager = *RefactorReportManagerT
+
+func NewRefactorReportManager() RefactorReportManager {
+	return &RefactorReportManagerT{
+		reportsMapByFile: map[string]RefactorReport{},
+	}
+}
+
+func (me RefactorReportManager) LoadReport(x Kontext, file string) RefactorReport {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+
+	r, has := me.reportsMapByFile[file]
+	if has {
+		return r
+	}
+
+	reportFile := ResolveRefactorReportFile(x.Config.CacheDir, x.Args.Repository, file)
+
+	r = &RefactorReportT{}
+	if err := comm.FromJsonFile(x.Fs, reportFile, false, r); err != nil {
+		return nil
+	}
+
+	me.reportsMapByFile[file] = r
+
+	return r
+}
+
+func (me RefactorReportManager) SaveReport(x Kontext, file string, report RefactorReport) string {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+
+	me.reportsMapByFile[file] = report
+
+	reportText := comm.ToJsonP(report, true)
+
+	reportFile := ResolveRefactorReportFile(x.Config.CacheDir, x.Args.Repository, file)
+
+	comm.Mkdir(x.Fs, path.Dir(reportFile))
+	comm.WriteFileText(x.Fs, reportFile, reportText)
+
+	return reportFile
+}
+
+func ResolveRefactorReportFile(cacheDir string, repository string, file string) string {
+	// the file is relative to working directory, so take the relative path
+	relativePath := file[len(repository):]
+	repoName := path.Base(repository)
+	return path.Join(cacheDir, repoName, relativePath+".refactor.batchai.json")
+}
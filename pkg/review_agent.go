@@ -0,0 +1,150 @@
+package batchai
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/qiangyt/batchai/comm"
+)
+
+type ReviewResultT struct {
+	Report  ReviewReport
+	Skipped bool
+	Failed  bool
+	// issues which can't be anchored onto the diff
+	Dropped int
+}
+
+type ReviewResult = *ReviewResultT
+
+type ReviewAgentT struct {
+	SymbolAwareAgentT
+
+	reportManager ReviewReportManager
+
+	file         string
+	relativeFile string
+}
+
+type ReviewAgent = *ReviewAgentT
+
+func NewReviewAgent(reportManager ReviewReportManager,
+	symbolManager SymbolManager,
+	modelService ModelService,
+	codeFile string,
+) ReviewAgent {
+	return &ReviewAgentT{
+		SymbolAwareAgentT: newSymbolAwareAgent(symbolManager, modelService),
+		reportManager:     reportManager,
+
+		file: codeFile,
+	}
+}
+
+func (me ReviewAgent) run(x Kontext, reviewArgs ReviewArgs, revisions ReviewRevisions, resultChan chan<- ReviewResult, output func(text string)) {
+	c := comm.NewConsole(!x.Args.Concurrent).WithOutput(output)
+	me.relativeFile = me.file[len(x.Args.Repository)+1:]
+
+	c.Begin()
+	defer c.End()
+	c.Greenf("\n\n▹▹▹▹▹ processing: %s\n", me.relativeFile)
+
+	defer func() {
+		if e := recover(); e != nil {
+			c.NewLine().Red("failed: ").Defaultf("%v, %+v", me.relativeFile, e)
+			resultChan <- &ReviewResultT{Failed: true}
+		}
+	}()
+
+	result := me.reviewFile(x, reviewArgs, revisions, c)
+
+	resultChan <- result
+}
+
+func (me ReviewAgent) Run(x Kontext, reviewArgs ReviewArgs, revisions ReviewRevisions, resultChan chan<- ReviewResult, pool comm.WorkerPool) {
+	if !x.Args.Concurrent {
+		me.run(x, reviewArgs, revisions, resultChan, nil)
+		return
+	}
+
+	pool.Submit(func(output func(text string)) {
+		me.run(x, reviewArgs, revisions, resultChan, output)
+	})
+}
+
+func (me ReviewAgent) reviewFile(x Kontext, reviewArgs ReviewArgs, revisions ReviewRevisions, c comm.Console) ReviewResult {
+	c.NewLine().Green("--------------------")
+	c.NewLine().Greenln(me.relativeFile)
+
+	diffText, err := comm.GetGitDiff(x.Fs, x.Args.Repository, me.file, reviewArgs.DiffArgs()...)
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to get the diff of %s", me.relativeFile))
+	}
+	diff := ParseReviewDiff(diffText)
+
+	lastReport := me.reportManager.LoadReport(x, me.file)
+	if lastReport != nil {
+		noDiffChanges := (diffText == lastReport.Diff)
+		if noDiffChanges && !x.Args.Force {
+			c.NewLine().Default("✔ no diff changes since last execution, skipped")
+			return &ReviewResultT{Report: lastReport, Skipped: true}
+		}
+	}
+
+	newReport := me.reviewDiff(x, c, diff)
+	// the review APIs expect slash-separated path relative to the top-level directory of git repository
+	newReport.Path = RelativeRepositoryPath(reviewArgs.Root, me.file)
+	newReport.HeadSha = revisions.HeadSha
+	newReport.Diff = diffText
+	newReport.BuildComments(me.anchorDiff(x, reviewArgs, diff))
+	newReport.Print(c)
+
+	reportFile := me.reportManager.SaveReport(x, me.file, newReport)
+	c.NewLine().Blue("✔ report: ").Default(reportFile[len(x.Args.Repository)+1:])
+
+	return &ReviewResultT{Report: newReport, Skipped: false, Dropped: len(newReport.Issues) - len(newReport.Comments)}
+}
+
+// anchorDiff returns the diff of the review APIs, of which the context lines may be less than the diff sent to the model
+func (me ReviewAgent) anchorDiff(x Kontext, reviewArgs ReviewArgs, diff ReviewDiff) ReviewDiff {
+	if reviewArgs.ContextLines == REVIEW_API_CONTEXT_LINES {
+		return diff
+	}
+
+	diffText, err := comm.GetGitDiff(x.Fs, x.Args.Repository, me.file, reviewArgs.AnchorDiffArgs()...)
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to get the diff of %s", me.relativeFile))
+	}
+	return ParseReviewDiff(diffText)
+}
+
+func (me ReviewAgent) reviewDiff(x Kontext, c comm.Console, diff ReviewDiff) ReviewReport {
+	verbose := x.Args.Verbose
+
+	annotated := diff.Annotate()
+	sysPrompt := x.Config.Review.RenderPrompt(annotated, me.relativeFile)
+	if x.Args.EnableSymbolReference {
+		sysPrompt = me.provideSymbols(x, c, x.Config.Review.ModelId, me.file, annotated, sysPrompt)
+	}
+	mem := me.memory
+	mem.AddSystemMessage(sysPrompt)
+
+	if x.Args.EnableSymbolReference {
+		mem.AddUserMessage("review the changes, with provided symbols as references")
+	} else {
+		mem.AddUserMessage("review the changes")
+	}
+	if verbose {
+		c.NewLine().Gray("chat: ").Default("review the changes")
+	}
+
+	answer, metrics := me.modelService.Chat(x, c, x.Config.Review.ModelId, true, mem, nil)
+	if verbose {
+		c.NewLine().Gray("answer: ").Default(mem.Format())
+	}
+
+	r := ExtractReviewReport(answer, strings.HasSuffix(me.relativeFile, ".go"))
+	r.ModelUsageMetrics = metrics
+
+	return r
+}
@@ -0,0 +1,136 @@
+package batchai
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/qiangyt/batchai/comm"
+	"github.com/urfave/cli/v2"
+)
+
+type ReviewArgsT struct {
+	Base         string
+	Head         string
+	ContextLines int
+	Format       string
+	Output       string
+	// top-level directory of the git repository, which the paths of review comments are relative to
+	Root string
+}
+
+type ReviewArgs = *ReviewArgsT
+
+func (me ReviewArgs) WithCliContext(x Kontext, cliContext *cli.Context) error {
+	me.Base = cliContext.String("base")
+	if len(me.Base) == 0 {
+		return errors.New("please specifies the base ref via --base, e.g. origin/main")
+	}
+
+	me.Head = cliContext.String("head")
+	if len(me.Head) == 0 {
+		me.Head = "HEAD"
+	}
+
+	me.ContextLines = cliContext.Int("context")
+	if me.ContextLines < 0 {
+		return fmt.Errorf("invalid --context: %d", me.ContextLines)
+	}
+
+	me.Format = cliContext.String("format")
+	switch me.Format {
+	case "":
+		me.Format = REVIEW_FORMAT_GITHUB
+	case REVIEW_FORMAT_GITHUB, REVIEW_FORMAT_GITLAB:
+	default:
+		return fmt.Errorf("unsupported format: %s, must be either %s or %s", me.Format, REVIEW_FORMAT_GITHUB, REVIEW_FORMAT_GITLAB)
+	}
+
+	output := cliContext.String("output")
+	if len(output) == 0 {
+		return errors.New("please specifies the output file of review comments via --output")
+	}
+	me.Output = comm.AbsPathWithP(output, comm.WorkingDirectoryP())
+
+	return nil
+}
+
+// DiffRange is the `git diff` argument for the changes introduced by head since it diverged from base, same as a pull request
+func (me ReviewArgs) DiffRange() string {
+	return me.Base + "..." + me.Head
+}
+
+// DiffArgs are the `git diff` arguments for the diff hunks of a file
+func (me ReviewArgs) DiffArgs() []string {
+	return []string{fmt.Sprintf("-U%d", me.ContextLines), me.DiffRange()}
+}
+
+// AnchorDiffArgs are the `git diff` arguments for the diff hunks that the review comments are anchored to
+func (me ReviewArgs) AnchorDiffArgs() []string {
+	return []string{fmt.Sprintf("-U%d", REVIEW_API_CONTEXT_LINES), me.DiffRange()}
+}
+
+// ResolveRevisions resolves the SHAs required by the review APIs
+func (me ReviewArgs) ResolveRevisions(x Kontext) (ReviewRevisions, error) {
+	r := &ReviewRevisionsT{}
+	var err error
+
+	if r.StartSha, err = comm.GetGitRevision(x.Fs, x.Args.Repository, me.Base); err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve base ref: %s", me.Base)
+	}
+	if r.HeadSha, err = comm.GetGitRevision(x.Fs, x.Args.Repository, me.Head); err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve head ref: %s", me.Head)
+	}
+	if r.BaseSha, err = comm.GetGitMergeBase(x.Fs, x.Args.Repository, r.StartSha, r.HeadSha); err != nil {
+		return nil, errors.Wrapf(err, "failed to find the merge base of %s and %s", me.Base, me.Head)
+	}
+	return r, nil
+}
+
+func ReviewUrfaveCommand(x Kontext) *cli.Command {
+	return &cli.Command{
+		Name:  "review",
+		Usage: fmt.Sprintf("Reviews the changes between the base and head refs like a pull request. Line-anchored review comments are written to the output json file, and reports are also saved to '%s'", os.Getenv("BATCHAI_CACHE_DIR")),
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "base", Usage: "Base ref of the review, e.g. origin/main"},
+			&cli.StringFlag{Name: "head", Value: "HEAD", Usage: "Head ref of the review. The files are listed from the working tree, so it's expected to be checked out"},
+			&cli.IntFlag{Name: "context", Value: 10, Usage: "Number of unchanged lines around the changes to send to the model"},
+			&cli.StringFlag{Name: "format", Value: REVIEW_FORMAT_GITHUB, Usage: "Shape of the output, either 'github' (request body of the pull request review API) or 'gitlab' (array of merge request discussions)"},
+			&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "Output file of the review comments, e.g. review.json"},
+		},
+		Action: ReviewFunc(x),
+	}
+}
+
+func ReviewFunc(x Kontext) func(*cli.Context) error {
+	return func(cliContext *cli.Context) error {
+		x.Config.Init("review")
+
+		a := &AppArgsT{}
+		if err := a.WithCliContext(x, cliContext); err != nil {
+			return err
+		}
+		if a.HasGitDiffFilter() {
+			return errors.New("--since, --staged and --changed are not applicable to review, use --base and --head instead")
+		}
+		x.Args = a
+
+		ra := &ReviewArgsT{}
+		if err := ra.WithCliContext(x, cliContext); err != nil {
+			return err
+		}
+
+		// only the files changed between base and head are reviewed
+		a.Since = ra.DiffRange()
+		ra.Root = comm.GitDirectory(x.Fs, a.Repository)
+
+		revisions, err := ra.ResolveRevisions(x)
+		if err != nil {
+			return err
+		}
+
+		NewReviewCommand(x).Review(x, ra, revisions)
+
+		return nil
+	}
+}
@@ -0,0 +1,86 @@
+package batchai
+
+import (
+	"github.com/qiangyt/batchai/comm"
+)
+
+type ReviewCommandT struct {
+	BaseModelCommandT
+	reportManager ReviewReportManager
+}
+
+type ReviewCommand = *ReviewCommandT
+
+func NewReviewCommand(x Kontext) ReviewCommand {
+	return &ReviewCommandT{
+		BaseModelCommandT: *NewBaseModelCommand(x),
+		reportManager:     NewReviewReportManager(),
+	}
+}
+
+func (me ReviewCommand) launchReviewAgents(x Kontext, reviewArgs ReviewArgs, revisions ReviewRevisions, targetFiles []string, metrics ReviewMetrics) []ReviewComment {
+	// launch review agents and wait for them
+	resultChan := make(chan ReviewResult, len(targetFiles))
+
+	for _, f := range targetFiles {
+		metrics.Processed++
+
+		agent := NewReviewAgent(me.reportManager, me.symbolManager, me.modelService, f)
+		agent.Run(x, reviewArgs, revisions, resultChan, me.workerPool)
+	}
+
+	me.workerPool.Wait()
+	close(resultChan)
+
+	comments := []ReviewComment{}
+	for r := range resultChan {
+		if r.Report != nil {
+			comments = append(comments, r.Report.Comments...)
+		}
+
+		if r.Failed {
+			metrics.Failed++
+		} else if r.Skipped {
+			metrics.Skipped++
+		} else {
+			metrics.Succeeded++
+
+			report := r.Report
+			metrics.ModelUsageMetricsT.IncreaseUsage(report.ModelUsageMetrics)
+
+			if len(report.Comments) > 0 {
+				metrics.Commented++
+				metrics.TotalComments += len(report.Comments)
+			}
+			metrics.Dropped += r.Dropped
+		}
+	}
+
+	return comments
+}
+
+func (me ReviewCommand) Review(x Kontext, reviewArgs ReviewArgs, revisions ReviewRevisions) {
+	metrics := NewReviewMetrics()
+	c := comm.NewConsole(!x.Args.Concurrent)
+
+	c.NewLine().Default("review command uses model ").Yellowf("'%s'", x.Config.Review.ModelId)
+	c.NewLine().Default("reviewing ").Yellowf("%s..%s", comm.ShortenGitSha(revisions.BaseSha), comm.ShortenGitSha(revisions.HeadSha)).Default("\n\n")
+
+	targetFiles, ignored, failed, repoFiles := me.listCommand.CollectWorkingFiles(x, c)
+	metrics.WithWorkingFiles(targetFiles, ignored, failed)
+
+	comments := []ReviewComment{}
+	if len(targetFiles) > 0 {
+		if x.Args.EnableSymbolReference {
+			me.launchSymbolAgents(x, repoFiles)
+		}
+		comments = me.launchReviewAgents(x, reviewArgs, revisions, targetFiles, metrics)
+	}
+
+	WriteReviewOutput(x, reviewArgs.Format, reviewArgs.Output, revisions, comments)
+	c.NewLine().Defaultf("%d review comments saved to ", len(comments)).Yellow(reviewArgs.Output)
+
+	c.NewLine()
+	metrics.Print(c)
+	WriteMetricsJson(x, c, metrics)
+}
@@ -0,0 +1,221 @@
+package batchai
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+const testReviewBaseCode = `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("a")
+	fmt.Println("b")
+	fmt.Println("c")
+	fmt.Println("d")
+	fmt.Println("e")
+	fmt.Println("f")
+	fmt.Println("g")
+	fmt.Println("h")
+}
+`
+
+const testReviewAnswer = "```json\n" + `{
+  "issues": [
+    {"short_description": "duplicated output", "detailed_explaination": "new1 and new2 are printed", "suggestion": "merge them", "issue_line_begin": 13, "issue_line_end": 14, "issue_reference_urls": ["https://go.dev/doc"], "severity": "major", "severity_reason": "noise"},
+    {"short_description": "upper case", "issue_line_begin": 6, "issue_line_end": 6, "severity": "minor"},
+    {"short_description": "unchanged lines", "issue_line_begin": 9, "issue_line_end": 10, "severity": "minor"}
+  ]
+}` + "\n```"
+
+func newTestReviewKontext(t *testing.T, fs afero.Fs, repo string, baseUrl string) Kontext {
+	model := newTestModelConfig(MODEL_PROVIDER_OPENAI, baseUrl)
+
+	config := &AppConfigT{
+		CacheDir: t.TempDir(),
+		Models:   []ModelConfig{model},
+		include:  comm.CompileMatchLines(nil, "*.go"),
+		exclude:  comm.CompileMatchLines(nil, ".git"),
+	}
+	config.Review = &ReviewConfigT{
+		AppConfig: config,
+		ModelId:   model.Id,
+		Prompt:    &ReviewPromptT{Template: "{{.path}}\n{{.diff}}"},
+	}
+
+	x := NewKontext(fs)
+	x.Config = config
+	x.Args = &AppArgsT{Repository: repo}
+	return x
+}
+
+func TestReviewCommand(t *testing.T) {
+	a := require.New(t)
+
+	fs := afero.NewOsFs()
+	repo := initTestGitRepo(t, fs, map[string]string{
+		"main.go":   testReviewBaseCode,
+		"b.go":      "package main\n",
+		"README.md": "readme\n",
+	})
+
+	headCode := strings.Replace(testReviewBaseCode, `"a"`, `"A"`, 1)
+	headCode = strings.Replace(headCode, "\tfmt.Println(\"g\")\n", "\tfmt.Println(\"g\")\n\tfmt.Println(\"new1\")\n\tfmt.Println(\"new2\")\n", 1)
+	comm.WriteFileTextP(fs, filepath.Join(repo, "main.go"), headCode)
+	comm.WriteFileTextP(fs, filepath.Join(repo, "README.md"), "changed\n")
+	for _, args := range [][]string{
+		{"branch", "base"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "head"},
+		// the base moves past the fork point
+		{"checkout", "-q", "base"},
+	} {
+		_, err := comm.ExecGit(fs, repo, args, true)
+		a.NoError(err)
+	}
+	comm.WriteFileTextP(fs, filepath.Join(repo, "base.txt"), "base\n")
+	for _, args := range [][]string{
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "base"},
+		{"checkout", "-q", "-"},
+	} {
+		_, err := comm.ExecGit(fs, repo, args, true)
+		a.NoError(err)
+	}
+	forkSha, err := comm.GetGitRevision(fs, repo, "HEAD~1")
+	a.NoError(err)
+	baseSha, err := comm.GetGitRevision(fs, repo, "base")
+	a.NoError(err)
+
+	prompts := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := readTestRequest(t, r)
+		// the system message is sent as an array of text parts
+		sysMessage := req["messages"].([]any)[0].(map[string]any)
+		prompts = append(prompts, sysMessage["content"].([]any)[0].(map[string]any)["text"].(string))
+
+		content, _ := json.Marshal(testReviewAnswer)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"1","object":"chat.completion","created":1,"model":"test-model","choices":[{"index":0,"message":{"role":"assistant","content":%s},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`, content)
+	}))
+	defer server.Close()
+
+	x := newTestReviewKontext(t, fs, repo, server.URL+"/")
+
+	ra := &ReviewArgsT{Base: "base", Head: "HEAD", ContextLines: 1, Format: REVIEW_FORMAT_GITLAB, Output: filepath.Join(t.TempDir(), "review.json"), Root: repo}
+	x.Args.Since = ra.DiffRange()
+	revisions, err := ra.ResolveRevisions(x)
+	a.NoError(err)
+	a.Len(revisions.HeadSha, 40)
+	// named as the diff_refs of GitLab
+	a.Equal(forkSha, revisions.BaseSha)
+	a.Equal(baseSha, revisions.StartSha)
+	a.NotEqual(revisions.BaseSha, revisions.StartSha)
+
+	NewReviewCommand(x).Review(x, ra, revisions)
+
+	// only the changed go file is reviewed, with the line numbers of head revision
+	a.Len(prompts, 1)
+	a.True(strings.HasPrefix(prompts[0], "main.go\n@@ -5,3 +5,3 @@"))
+	a.Contains(prompts[0], "    14 +\tfmt.Println(\"new2\")")
+
+	discussions := []GitlabDiscussionT{}
+	comm.FromJsonP(comm.ReadFileTextP(fs, ra.Output), false, &discussions)
+	a.Len(discussions, 3)
+
+	a.Equal(6, discussions[0].Position.NewLine)
+	a.Equal(0, discussions[0].Position.OldLine)
+	a.Equal("main.go", discussions[0].Position.NewPath)
+	a.Equal(revisions.HeadSha, discussions[0].Position.HeadSha)
+	a.Equal(forkSha, discussions[0].Position.BaseSha)
+	a.Equal(baseSha, discussions[0].Position.StartSha)
+
+	// the unchanged lines out of the diff sent to the model are still in the diff of review APIs
+	a.Equal(10, discussions[1].Position.NewLine)
+	a.Equal(10, discussions[1].Position.OldLine)
+
+	a.Equal(14, discussions[2].Position.NewLine)
+	a.Contains(discussions[2].Body, "**[major]** duplicated output")
+	a.Contains(discussions[2].Body, "- https://go.dev/doc")
+
+	// the unchanged diff is skipped, and the comments are taken from the saved report
+	ra.Format = REVIEW_FORMAT_GITHUB
+	NewReviewCommand(x).Review(x, ra, revisions)
+	a.Len(prompts, 1)
+
+	review := &GithubReviewT{}
+	comm.FromJsonP(comm.ReadFileTextP(fs, ra.Output), false, review)
+	a.Equal(revisions.HeadSha, review.CommitId)
+	a.Equal("COMMENT", review.Event)
+	a.Len(review.Comments, 3)
+	a.Equal(13, review.Comments[2].StartLine)
+	a.Equal(14, review.Comments[2].Line)
+	a.Equal("RIGHT", review.Comments[2].Side)
+	a.Equal(0, review.Comments[0].StartLine)
+	a.Empty(review.Comments[0].StartSide)
+}
+
+func TestReviewCommandAnchorOnApiDiff(t *testing.T) {
+	a := require.New(t)
+
+	baseCode := "package main\n\nimport \"fmt\"\n\nfunc main() {\n"
+	for i := 1; i <= 20; i++ {
+		baseCode += fmt.Sprintf("\tfmt.Println(%d)\n", i)
+	}
+	baseCode += "}\n"
+
+	fs := afero.NewOsFs()
+	repo := initTestGitRepo(t, fs, map[string]string{"main.go": baseCode})
+	// line 15 of head revision is changed
+	comm.WriteFileTextP(fs, filepath.Join(repo, "main.go"), strings.Replace(baseCode, "(10)", "(100)", 1))
+	for _, args := range [][]string{
+		{"branch", "base"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "head"},
+	} {
+		_, err := comm.ExecGit(fs, repo, args, true)
+		a.NoError(err)
+	}
+
+	prompts := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := readTestRequest(t, r)
+		sysMessage := req["messages"].([]any)[0].(map[string]any)
+		prompts = append(prompts, sysMessage["content"].([]any)[0].(map[string]any)["text"].(string))
+
+		// the far context line 7 is in the diff sent to the model, but not in the diff of review APIs
+		content, _ := json.Marshal("```json\n" + `{"issues": [{"short_description": "far", "issue_line_begin": 7, "issue_line_end": 7, "severity": "minor"}]}` + "\n```")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"1","object":"chat.completion","created":1,"model":"test-model","choices":[{"index":0,"message":{"role":"assistant","content":%s},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`, content)
+	}))
+	defer server.Close()
+
+	x := newTestReviewKontext(t, fs, repo, server.URL+"/")
+
+	ra := &ReviewArgsT{Base: "base", Head: "HEAD", ContextLines: 10, Format: REVIEW_FORMAT_GITHUB, Output: filepath.Join(t.TempDir(), "review.json"), Root: repo}
+	x.Args.Since = ra.DiffRange()
+	revisions, err := ra.ResolveRevisions(x)
+	a.NoError(err)
+
+	NewReviewCommand(x).Review(x, ra, revisions)
+
+	a.Len(prompts, 1)
+	a.Contains(prompts[0], "     7  \tfmt.Println(2)")
+
+	review := &GithubReviewT{}
+	comm.FromJsonP(comm.ReadFileTextP(fs, ra.Output), false, review)
+	a.Len(review.Comments, 1)
+	// moved to the first line of `@@ -12,7 +12,7 @@`
+	a.Equal(12, review.Comments[0].Line)
+}
@@ -0,0 +1,161 @@
+package batchai
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type ReviewDiffLineT struct {
+	// ' ' for context line, '+' for added line, '-' for removed line
+	Kind    byte
+	Text    string
+	OldLine int
+	NewLine int
+}
+
+type ReviewDiffLine = *ReviewDiffLineT
+
+type ReviewDiffHunkT struct {
+	Header   string
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []ReviewDiffLine
+}
+
+type ReviewDiffHunk = *ReviewDiffHunkT
+
+// ReviewDiffT is the parsed unified diff of a single file
+type ReviewDiffT struct {
+	Hunks []ReviewDiffHunk
+}
+
+type ReviewDiff = *ReviewDiffT
+
+var reviewHunkHeaderRegexp = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+func parseHunkRangeNumber(text string, defaultValue int) int {
+	if len(text) == 0 {
+		return defaultValue
+	}
+	r, _ := strconv.Atoi(text)
+	return r
+}
+
+// ParseReviewDiff parses the output of `git diff` of a single file
+func ParseReviewDiff(diff string) ReviewDiff {
+	r := &ReviewDiffT{Hunks: []ReviewDiffHunk{}}
+
+	var hunk ReviewDiffHunk
+	oldLine, newLine := 0, 0
+
+	for _, line := range strings.Split(diff, "\n") {
+		if m := reviewHunkHeaderRegexp.FindStringSubmatch(line); m != nil {
+			hunk = &ReviewDiffHunkT{
+				Header:   line,
+				OldStart: parseHunkRangeNumber(m[1], 0),
+				OldLines: parseHunkRangeNumber(m[2], 1),
+				NewStart: parseHunkRangeNumber(m[3], 0),
+				NewLines: parseHunkRangeNumber(m[4], 1),
+				Lines:    []ReviewDiffLine{},
+			}
+			r.Hunks = append(r.Hunks, hunk)
+			oldLine, newLine = hunk.OldStart, hunk.NewStart
+			continue
+		}
+
+		// skips the file headers, and the '\ No newline at end of file' marker
+		if hunk == nil || len(line) == 0 {
+			continue
+		}
+
+		switch line[0] {
+		case ' ':
+			hunk.Lines = append(hunk.Lines, &ReviewDiffLineT{Kind: ' ', Text: line[1:], OldLine: oldLine, NewLine: newLine})
+			oldLine++
+			newLine++
+		case '+':
+			hunk.Lines = append(hunk.Lines, &ReviewDiffLineT{Kind: '+', Text: line[1:], NewLine: newLine})
+			newLine++
+		case '-':
+			hunk.Lines = append(hunk.Lines, &ReviewDiffLineT{Kind: '-', Text: line[1:], OldLine: oldLine})
+			oldLine++
+		}
+	}
+
+	return r
+}
+
+// Annotate renders the diff with the line numbers of the head revision, so that the model could
+// report the issue lines per the head revision. The removed lines have no line number.
+func (me ReviewDiff) Annotate() string {
+	b := strings.Builder{}
+	for _, hunk := range me.Hunks {
+		b.WriteString(hunk.Header)
+		b.WriteString("\n")
+		for _, line := range hunk.Lines {
+			if line.Kind == '-' {
+				b.WriteString(fmt.Sprintf("%6s %c%s\n", "", line.Kind, line.Text))
+			} else {
+				b.WriteString(fmt.Sprintf("%6d %c%s\n", line.NewLine, line.Kind, line.Text))
+			}
+		}
+	}
+	return b.String()
+}
+
+// FindLine returns the context or added line of the head revision
+func (me ReviewDiff) FindLine(newLine int) ReviewDiffLine {
+	for _, hunk := range me.Hunks {
+		for _, line := range hunk.Lines {
+			if line.Kind != '-' && line.NewLine == newLine {
+				return line
+			}
+		}
+	}
+	return nil
+}
+
+// MapLines maps the line range onto the lines of head revision that the review APIs accept comments on,
+// that is, the context and added lines of a single hunk. A range out of any hunk is moved to the nearest hunk.
+// Returns false if there is no line to comment on, e.g., the file is deleted.
+func (me ReviewDiff) MapLines(begin int, end int) (int, int, bool) {
+	if end < begin {
+		end = begin
+	}
+
+	var nearest ReviewDiffHunk
+	nearestDistance := -1
+
+	for _, hunk := range me.Hunks {
+		if hunk.NewLines <= 0 {
+			continue
+		}
+
+		first := hunk.NewStart
+		last := hunk.NewStart + hunk.NewLines - 1
+
+		distance := 0
+		if end < first {
+			distance = first - end
+		} else if begin > last {
+			distance = begin - last
+		}
+
+		if nearestDistance < 0 || distance < nearestDistance {
+			nearest = hunk
+			nearestDistance = distance
+		}
+	}
+
+	if nearest == nil {
+		return 0, 0, false
+	}
+
+	first := nearest.NewStart
+	last := nearest.NewStart + nearest.NewLines - 1
+	return max(first, min(begin, last)), max(first, min(end, last)), true
+}
@@ -0,0 +1,86 @@
+package batchai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testReviewDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -5,3 +5,3 @@ package main
+ func main() {
+-	fmt.Println("a")
++	fmt.Println("A")
+ 	fmt.Println("b")
+@@ -12,2 +12,4 @@ func main() {
+ 	fmt.Println("g")
++	fmt.Println("new1")
++	fmt.Println("new2")
+ 	fmt.Println("h")
+\ No newline at end of file
+`
+
+func TestParseReviewDiff(t *testing.T) {
+	a := require.New(t)
+
+	diff := ParseReviewDiff(testReviewDiff)
+	a.Len(diff.Hunks, 2)
+
+	h := diff.Hunks[0]
+	a.Equal(5, h.OldStart)
+	a.Equal(3, h.OldLines)
+	a.Equal(5, h.NewStart)
+	a.Equal(3, h.NewLines)
+	a.Len(h.Lines, 4)
+	a.Equal(byte('-'), h.Lines[1].Kind)
+	a.Equal(6, h.Lines[1].OldLine)
+	a.Equal(0, h.Lines[1].NewLine)
+	a.Equal(byte('+'), h.Lines[2].Kind)
+	a.Equal(6, h.Lines[2].NewLine)
+
+	h = diff.Hunks[1]
+	a.Len(h.Lines, 4)
+	a.Equal(15, h.Lines[3].NewLine)
+	a.Equal(13, h.Lines[3].OldLine)
+
+	annotated := diff.Annotate()
+	a.Contains(annotated, "     6 +\tfmt.Println(\"A\")\n")
+	a.Contains(annotated, "       -\tfmt.Println(\"a\")\n")
+	a.Contains(annotated, "    14 +\tfmt.Println(\"new2\")\n")
+	a.NotContains(annotated, "No newline")
+}
+
+func TestReviewDiffMapLines(t *testing.T) {
+	a := require.New(t)
+
+	diff := ParseReviewDiff(testReviewDiff)
+
+	begin, end, ok := diff.MapLines(13, 14)
+	a.True(ok)
+	a.Equal(13, begin)
+	a.Equal(14, end)
+
+	// the range crossing 2 hunks is clamped into the first one
+	begin, end, ok = diff.MapLines(6, 13)
+	a.True(ok)
+	a.Equal(6, begin)
+	a.Equal(7, end)
+
+	// the range out of any hunk is moved to the nearest hunk
+	begin, end, ok = diff.MapLines(20, 22)
+	a.True(ok)
+	a.Equal(15, begin)
+	a.Equal(15, end)
+
+	begin, end, ok = diff.MapLines(1, 0)
+	a.True(ok)
+	a.Equal(5, begin)
+	a.Equal(5, end)
+
+	// nothing to comment on for a deleted file
+	_, _, ok = ParseReviewDiff("@@ -1,2 +0,0 @@\n-a\n-b\n").MapLines(1, 1)
+	a.False(ok)
+}
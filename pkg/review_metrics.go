@@ -0,0 +1,37 @@
+package batchai
+
+import (
+	"github.com/qiangyt/batchai/comm"
+)
+
+type ReviewMetricsT struct {
+	BaseMetricsT
+
+	Commented     int `json:"commented"`
+	TotalComments int `json:"total_comments"`
+	// issues which can't be anchored onto the diff
+	Dropped int `json:"dropped"`
+}
+
+type ReviewMetrics = *ReviewMetricsT
+
+func NewReviewMetrics() ReviewMetrics {
+	return &ReviewMetricsT{
+		BaseMetricsT: *NewBaseMetrics(),
+	}
+}
+
+func (me ReviewMetrics) Print(console comm.Console) {
+	me.PreparePrint(console)
+
+	console.NewLine().Greenf("Files: %d, Processed: %d, Ignored: %d, Failed: %d, Commented: %d, Total Comments: %d, Dropped: %d, Skipped: %d",
+		me.Files,
+		me.Processed,
+		me.Ignored,
+		me.Failed,
+		me.Commented,
+		me.TotalComments,
+		me.Dropped,
+		me.Skipped,
+	)
+}
@@ -0,0 +1,148 @@
+package batchai
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+const (
+	REVIEW_FORMAT_GITHUB = "github"
+	REVIEW_FORMAT_GITLAB = "gitlab"
+)
+
+// REVIEW_API_CONTEXT_LINES is the number of context lines of the diff shown by GitHub and GitLab,
+// the review comments are accepted only on the lines of that diff
+const REVIEW_API_CONTEXT_LINES = 3
+
+// GithubReviewCommentT is a draft review comment of the GitHub 'create a review for a pull request' API
+type GithubReviewCommentT struct {
+	Path      string `json:"path"`
+	Body      string `json:"body"`
+	Line      int    `json:"line"`
+	Side      string `json:"side"`
+	StartLine int    `json:"start_line,omitempty"`
+	StartSide string `json:"start_side,omitempty"`
+}
+
+type GithubReviewComment = *GithubReviewCommentT
+
+// GithubReviewT is the request body of POST /repos/{owner}/{repo}/pulls/{pull_number}/reviews
+type GithubReviewT struct {
+	CommitId string                `json:"commit_id"`
+	Body     string                `json:"body"`
+	Event    string                `json:"event"`
+	Comments []GithubReviewComment `json:"comments"`
+}
+
+type GithubReview = *GithubReviewT
+
+// GitlabPositionT is the position of a GitLab merge request diff discussion
+type GitlabPositionT struct {
+	PositionType string `json:"position_type"`
+	BaseSha      string `json:"base_sha"`
+	StartSha     string `json:"start_sha"`
+	HeadSha      string `json:"head_sha"`
+	OldPath      string `json:"old_path"`
+	NewPath      string `json:"new_path"`
+	NewLine      int    `json:"new_line"`
+	OldLine      int    `json:"old_line,omitempty"`
+}
+
+type GitlabPosition = *GitlabPositionT
+
+// GitlabDiscussionT is the request body of POST /projects/:id/merge_requests/:merge_request_iid/discussions
+type GitlabDiscussionT struct {
+	Body     string         `json:"body"`
+	Position GitlabPosition `json:"position"`
+}
+
+type GitlabDiscussion = *GitlabDiscussionT
+
+// ReviewRevisionsT is the resolved SHAs of the reviewed revisions
+// which are named as the diff_refs of GitLab merge request
+type ReviewRevisionsT struct {
+	// merge base of base and head
+	BaseSha string
+	// tip of the base ref, moves away from the merge base once the target branch gets new commits
+	StartSha string
+	HeadSha  string
+}
+
+type ReviewRevisions = *ReviewRevisionsT
+
+// SortReviewComments sorts the comments by path and line, so that the output is stable
+func SortReviewComments(comments []ReviewComment) {
+	sort.SliceStable(comments, func(i, j int) bool {
+		if comments[i].Path != comments[j].Path {
+			return comments[i].Path < comments[j].Path
+		}
+		return comments[i].Line < comments[j].Line
+	})
+}
+
+func NewGithubReview(revisions ReviewRevisions, comments []ReviewComment) GithubReview {
+	r := &GithubReviewT{
+		CommitId: revisions.HeadSha,
+		Body:     fmt.Sprintf("batchai reviewed the changes and left %d comments.", len(comments)),
+		Event:    "COMMENT",
+		Comments: []GithubReviewComment{},
+	}
+
+	for _, comment := range comments {
+		c := &GithubReviewCommentT{
+			Path: comment.Path,
+			Body: comment.Body,
+			Line: comment.Line,
+			Side: "RIGHT",
+		}
+		if comment.StartLine > 0 {
+			c.StartLine = comment.StartLine
+			c.StartSide = "RIGHT"
+		}
+		r.Comments = append(r.Comments, c)
+	}
+
+	return r
+}
+
+// NewGitlabDiscussions builds the discussions anchored on the line of head revision.
+// GitLab expects the old line as well if the commented line is an unchanged line.
+func NewGitlabDiscussions(revisions ReviewRevisions, comments []ReviewComment) []GitlabDiscussion {
+	r := []GitlabDiscussion{}
+
+	for _, comment := range comments {
+		r = append(r, &GitlabDiscussionT{
+			Body: comment.Body,
+			Position: &GitlabPositionT{
+				PositionType: "text",
+				BaseSha:      revisions.BaseSha,
+				StartSha:     revisions.StartSha,
+				HeadSha:      revisions.HeadSha,
+				OldPath:      comment.Path,
+				NewPath:      comment.Path,
+				NewLine:      comment.Line,
+				OldLine:      comment.OldLine,
+			},
+		})
+	}
+
+	return r
+}
+
+// WriteReviewOutput writes the comments to the output file in the shape of the review API of the format
+func WriteReviewOutput(x Kontext, format string, outputFile string, revisions ReviewRevisions, comments []ReviewComment) {
+	SortReviewComments(comments)
+
+	var output any
+	if format == REVIEW_FORMAT_GITLAB {
+		output = NewGitlabDiscussions(revisions, comments)
+	} else {
+		output = NewGithubReview(revisions, comments)
+	}
+
+	comm.MkdirP(x.Fs, path.Dir(outputFile))
+	comm.WriteFileTextP(x.Fs, outputFile, comm.ToJsonP(output, true))
+}
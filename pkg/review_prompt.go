@@ -0,0 +1,71 @@
+package batchai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+type ReviewPromptVariablesT struct {
+	Data map[string]any
+}
+
+type ReviewPromptVariables = *ReviewPromptVariablesT
+
+func NewReviewPromptVariables() ReviewPromptVariables {
+	return &ReviewPromptVariablesT{Data: map[string]any{
+		"review_report_json_format": REVIEW_REPORT_JSON_FORMAT,
+	}}
+}
+
+func (me ReviewPromptVariables) WithSeverity(severity string) ReviewPromptVariables {
+	if len(severity) == 0 {
+		severity = "minor"
+	}
+	me.Data["severity"] = severity
+	return me
+}
+
+// WithDiff provides the diff hunks annotated with the line numbers of the head revision
+func (me ReviewPromptVariables) WithDiff(diff string) ReviewPromptVariables {
+	me.Data["diff"] = strings.TrimRight(diff, "\n")
+	return me
+}
+
+func (me ReviewPromptVariables) WithLang(lang string) ReviewPromptVariables {
+	me.Data["lang"] = lang
+	return me
+}
+
+func (me ReviewPromptVariables) WithPath(path string) ReviewPromptVariables {
+	me.Data["path"] = path
+	return me
+}
+
+type ReviewPromptT struct {
+	Rules    []string `mapstructure:"rules"`
+	Template string   `mapstructure:"template"`
+}
+
+type ReviewPrompt = *ReviewPromptT
+
+func (me ReviewPrompt) Init(config AppConfig) {
+	me.Rules = comm.StringArrayTrimSpace(me.Rules)
+	for i, rule := range me.Rules {
+		me.Rules[i] = fmt.Sprintf("## %s\n", rule)
+	}
+
+	me.Template = strings.TrimSpace(me.Template)
+}
+
+func (me ReviewPrompt) Generate(vars ReviewPromptVariables) string {
+	data := vars.Data
+
+	rules := comm.RenderAsTemplateArrayP(me.Rules, data)
+	if len(rules) > 0 {
+		data["review_rules"] = strings.Join(rules, "\n")
+	}
+
+	return comm.RenderAsTemplateP(me.Template, data)
+}
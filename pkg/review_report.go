@@ -0,0 +1,149 @@
+package batchai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/qiangyt/batchai/comm"
+)
+
+const REVIEW_REPORT_JSON_FORMAT = `
+{
+  "issues": [
+    {
+	   "short_description": "...",
+	   "detailed_explaination": "...",
+	   "suggestion": "...",
+	   "issue_line_begin": 9,
+	   "issue_line_end": 12,
+	   "issue_reference_urls": ["..."],
+       "severity": "trivial" or "minor" or "major" or "critical",
+       "severity_reason": "..."
+    }
+  ]
+}`
+
+// ReviewCommentT is a review comment anchored on the lines of the head revision
+type ReviewCommentT struct {
+	Path string `json:"path"`
+	// first line of a multi-line comment, 0 for single line comment
+	StartLine int `json:"start_line,omitempty"`
+	Line      int `json:"line"`
+	// line of the base revision if the commented line is an unchanged context line, otherwise 0
+	OldLine  int    `json:"old_line,omitempty"`
+	Severity string `json:"severity"`
+	Body     string `json:"body"`
+}
+
+type ReviewComment = *ReviewCommentT
+
+type ReviewReportT struct {
+	Path              string            `json:"path"`
+	ModelUsageMetrics ModelUsageMetrics `json:"model_usage_metrics"`
+
+	HeadSha  string          `json:"head_sha"`
+	Diff     string          `json:"diff"`
+	Issues   []CheckIssue    `json:"issues"`
+	Comments []ReviewComment `json:"comments"`
+}
+
+type ReviewReport = *ReviewReportT
+
+func ExtractReviewReport(answer string, isGolang bool) ReviewReport {
+	jsonStr, _ := comm.ExtractMarkdownJsonBlocksP(answer)
+
+	indexOfLeftBrace := strings.Index(jsonStr, "{")
+	if indexOfLeftBrace < 0 {
+		panic(errors.New("invalid json format - missing left brace"))
+	}
+	jsonStr = jsonStr[indexOfLeftBrace:]
+
+	indexOfRightBrace := strings.LastIndex(jsonStr, "}")
+	if indexOfRightBrace <= 0 {
+		panic(errors.New("invalid json format - missing right brace"))
+	}
+	jsonStr = jsonStr[:indexOfRightBrace+1]
+
+	report := &ReviewReportT{}
+	if err := comm.FromJson(jsonStr, false, report); err != nil {
+		jsonStr = comm.FixJson(jsonStr, isGolang)
+		comm.FromJsonP(jsonStr, false, report)
+	}
+	if report.Issues == nil {
+		report.Issues = []CheckIssue{}
+	}
+	return report
+}
+
+// BuildComments anchors the issues onto the lines of head revision covered by the diff.
+// Issues which can't be anchored, e.g. of a deleted file, are dropped.
+func (me ReviewReport) BuildComments(diff ReviewDiff) {
+	me.Comments = []ReviewComment{}
+
+	for _, issue := range me.Issues {
+		begin, end, ok := diff.MapLines(issue.IssueLineBegin, issue.IssueLineEnd)
+		if !ok {
+			continue
+		}
+
+		comment := &ReviewCommentT{
+			Path:     me.Path,
+			Line:     end,
+			Severity: issue.Severity,
+			Body:     FormatReviewCommentBody(issue),
+		}
+		if begin < end {
+			comment.StartLine = begin
+		}
+		if line := diff.FindLine(end); line != nil && line.Kind == ' ' {
+			comment.OldLine = line.OldLine
+		}
+
+		me.Comments = append(me.Comments, comment)
+	}
+}
+
+// FormatReviewCommentBody renders the issue as markdown
+func FormatReviewCommentBody(issue CheckIssue) string {
+	b := strings.Builder{}
+
+	b.WriteString(fmt.Sprintf("**[%s]** %s", issue.Severity, issue.ShortDescription))
+	if len(issue.DetailedExplaination) > 0 {
+		b.WriteString("\n\n" + issue.DetailedExplaination)
+	}
+	if len(issue.Suggestion) > 0 {
+		b.WriteString("\n\n**Suggestion:** " + issue.Suggestion)
+	}
+	if len(issue.IssueReferenceUrls) > 0 {
+		b.WriteString("\n\n**References:**")
+		for _, url := range issue.IssueReferenceUrls {
+			b.WriteString("\n- " + url)
+		}
+	}
+
+	return b.String()
+}
+
+func (me ReviewReport) Print(console comm.Console) {
+	me.ModelUsageMetrics.Print(console, comm.DEFAULT_COLOR)
+
+	if len(me.Comments) == 0 {
+		console.NewLine().Print("no comment")
+		return
+	}
+
+	console.NewLine().Print("Total ").Yellowf("%d", len(me.Comments)).Default(" comments")
+
+	console2 := console.NewIndented()
+	for _, comment := range me.Comments {
+		if comment.StartLine > 0 {
+			console2.NewLine().Yellowf("#L%d-L%d", comment.StartLine, comment.Line)
+		} else {
+			console2.NewLine().Yellowf("#L%d", comment.Line)
+		}
+		for _, line := range strings.Split(comment.Body, "\n") {
+			console2.NewLine().Default(line)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package batchai
 
 import (
 	"strings"
-	"sync"
 
 	"github.com/qiangyt/batchai/comm"
 )
@@ -10,37 +9,34 @@ import (
 type SymbolAgentT struct {
 	BaseAgentT
 
-	file            string
-	symbolManager   SymbolManager
-	codeFileManager CodeFileManager
+	file          string
+	symbolManager SymbolManager
+	// nil if the model has to extract
+	extractor SymbolExtractor
 }
 
 type SymbolAgent = *SymbolAgentT
 
 func NewSymbolAgent(symbolManager SymbolManager,
-	codeFileManager CodeFileManager,
 	modelService ModelService,
+	extractor SymbolExtractor,
 	file string,
 ) SymbolAgent {
 	return &SymbolAgentT{
-		BaseAgentT:      newBaseAgent(modelService),
-		file:            file,
-		symbolManager:   symbolManager,
-		codeFileManager: codeFileManager,
+		BaseAgentT:    newBaseAgent(modelService),
+		file:          file,
+		symbolManager: symbolManager,
+		extractor:     extractor,
 	}
 }
 
-func (me SymbolAgent) Run(x Kontext, resultChan chan<- []Symbol, wg *sync.WaitGroup) {
-	wg.Add(1)
+func (me SymbolAgent) Run(x Kontext, resultChan chan<- []Symbol, pool comm.WorkerPool) {
+	pool.Submit(func(output func(text string)) {
+		c := comm.NewConsole(!x.Args.Concurrent).WithOutput(output)
 
-	go func() {
-		defer wg.Done()
-
-		c := comm.NewConsole(!x.Args.Concurrent)
-
-		c.Defaultf("\n\n▹▹▹▹▹ processing symbols: %s\n", me.file)
 		c.Begin()
 		defer c.End()
+		c.Defaultf("\n\n▹▹▹▹▹ processing symbols: %s\n", me.file)
 
 		defer func() {
 			if e := recover(); e != nil {
@@ -52,27 +48,39 @@ func (me SymbolAgent) Run(x Kontext, resultChan chan<- []Symbol, wg *sync.WaitGr
 		symbols := me.collectSymbols(x, c)
 
 		resultChan <- symbols
-	}()
+	})
 }
 
 func (me SymbolAgent) collectSymbols(x Kontext, c comm.Console) []Symbol {
 	verbose := x.Args.Verbose
 	f := me.file
 
-	code := me.codeFileManager.Load(x, f)
-	if !code.IsChanged() {
-		cachedSymbols := me.symbolManager.Load(x, f)
-		if cachedSymbols != nil {
-			if !x.Args.Force {
-				return cachedSymbols
-			}
+	cachedSymbols := me.symbolManager.Load(x, f)
+	if cachedSymbols != nil && me.isExtractedByCurrentExtractor(cachedSymbols) {
+		if !x.Args.Force {
+			return cachedSymbols
+		}
+	}
+
+	code := comm.ReadFileTextP(x.Fs, f)
+
+	if me.extractor != nil {
+		r := me.extractor.Extract(x, f, code)
+		for _, s := range r {
+			s.Extractor = me.extractor.Name()
+		}
+		if verbose {
+			c.NewLine().Gray("extracted: ").Defaultf("%d symbols by %s", len(r), me.extractor.Name())
 		}
+
+		me.symbolManager.Save(x, f, code, r)
+		return r
 	}
 
 	sysPrompt := []string{
 		"Working on below code",
 		"path: " + f,
-		code.Latest,
+		code,
 	}
 
 	mem := me.memory
@@ -95,10 +103,24 @@ func (me SymbolAgent) collectSymbols(x Kontext, c comm.Console) []Symbol {
 	for _, s := range r {
 		s.Path = f
 		s.Lines = strings.TrimSpace(s.Lines)
+		s.Extractor = SYMBOL_EXTRACTOR_MODEL
 	}
 
-	me.symbolManager.Save(x, f, r)
-	me.codeFileManager.Save(x, f, code.Latest)
+	me.symbolManager.Save(x, f, code, r)
 
 	return r
 }
+
+// isExtractedByCurrentExtractor tells if the cached symbols are reusable. The symbols extracted by the model are
+// re-extracted once a native extractor is available, because that's cheap and more precise.
+func (me SymbolAgent) isExtractedByCurrentExtractor(symbols []Symbol) bool {
+	if me.extractor == nil {
+		return true
+	}
+	for _, s := range symbols {
+		if s.Extractor != me.extractor.Name() {
+			return false
+		}
+	}
+	return true
+}
@@ -2,6 +2,7 @@ package batchai
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/qiangyt/batchai/comm"
@@ -24,59 +25,91 @@ func newSymbolAwareAgent(symbolManager SymbolManager,
 	}
 }
 
-func (me SymbolAwareAgent) provideSymbols(x Kontext, c comm.Console, file string) ModelUsageMetrics {
-	verbose := x.Args.Verbose
-	mem := me.memory
-
-	msg1 := `
-1) list all referred non-standard symbols and all symbols which is missing and not defined or initialized in current file, includes: type, class,traits, enum, const, constant, literal, variable, interface, property, field, attributes, method, function;
-2) return a json array of simple name of symbols, e.g. ["symbol1", "symbol2"]; 
-3) output full-qualified symbol name excluding package name or module name
-4) exclude any other words excepts the json array
-5) if no symbol to check, return an empty array []
-`
-	mem.AddUserMessage(msg1)
-	if verbose {
-		c.NewLine().Gray("chat: ").Default(msg1)
+// an identifier optionally qualified by others, e.g. `calc.NewCalc` or `this.cache.get`
+var referencePattern = regexp.MustCompile(`[A-Za-z_$][\w$]*(?:\.[A-Za-z_$][\w$]*)*`)
+
+// referredSymbols returns the queries of the names appearing in the code, including the keywords which never match a symbol.
+// The qualifiers may be variables rather than types or packages, so Lookup falls back to the simple names if not matched.
+func referredSymbols(code string) []SymbolQuery {
+	r := []SymbolQuery{}
+	found := map[string]bool{}
+	for _, name := range referencePattern.FindAllString(code, -1) {
+		if !found[name] {
+			found[name] = true
+			r = append(r, &SymbolQueryT{Name: name})
+		}
 	}
+	return r
+}
 
-	modelId := x.Config.Check.ModelId
-	answer, metrics := me.modelService.Chat(x, c, modelId, true, mem, nil)
+// provideSymbols appends the definitions of the symbols referred by the code to the system prompt, as many as the context window allows.
+// The symbols are from the files directly imported by the file, so no chat is needed to find them; they're ranked by Lookup,
+// so the least relevant ones are omitted first.
+func (me SymbolAwareAgent) provideSymbols(x Kontext, c comm.Console, modelId string, file string, code string, sysPrompt string) string {
+	verbose := x.Args.Verbose
+
+	dependencies := me.symbolManager.Dependencies(file)
 	if verbose {
-		c.NewLine().Gray("answer: ").Default(answer)
+		c.NewLine().Gray("dependencies: ").Defaultf("%v", dependencies)
+	}
+	if len(dependencies) == 0 {
+		return sysPrompt
 	}
-	jsonAnswer, _ := comm.ExtractMarkdownJsonBlocksP(answer)
 
-	symbolNames := []string{}
-	comm.FromJsonP(jsonAnswer, false, &symbolNames)
-	if len(symbolNames) == 0 {
-		return metrics
+	imported := map[string]bool{}
+	for _, dependency := range dependencies {
+		imported[dependency] = true
 	}
-	symbolNames = comm.RemovePackageNames(symbolNames)
 
-	symbols := me.symbolManager.Lookup(x, symbolNames, file)
-	if verbose {
-		c.NewLine().Default("search symbols: ").Defaultf("%v", symbols)
+	symbols := []Symbol{}
+	for _, s := range me.symbolManager.Lookup(x, referredSymbols(code), file) {
+		if imported[s.Path] {
+			symbols = append(symbols, s)
+		}
 	}
 	if len(symbols) == 0 {
-		return metrics
+		return sysPrompt
 	}
 
-	symbolDetails := []string{}
-	for _, s := range symbols {
-		symbolDetails = append(symbolDetails, fmt.Sprintf("The symbol %s is defined and initialized in other files, %s. Must use this definition while checking and do not report anything related to it as an issue. See: %s", s.Name, s.Path, s.Lines))
+	mem := NewChatMemory().AddSystemMessage(sysPrompt)
+	references, omitted := me.symbolReferences(x, modelId, mem, symbols)
+	if omitted > 0 {
+		c.NewLine().Yellowf("%d of %d symbols are omitted to fit in the context window", omitted, len(symbols))
 	}
-	msg2 := strings.Join(symbolDetails, "\n")
-	mem.AddUserMessage(msg2)
-	if verbose {
-		c.NewLine().Gray("chat: ").Default(msg2)
+	if len(references) == 0 {
+		return sysPrompt
 	}
 
-	// TODO：merge metrics
-	answer, metrics = me.modelService.Chat(x, c, modelId, true, mem, nil)
+	symbolContext := "\n\nThe symbols below are referred by the code and defined in other files of the repository. Use these definitions as references:\n" +
+		strings.Join(references, "\n")
 	if verbose {
-		c.NewLine().Gray("answer: ").Default(answer)
+		c.NewLine().Gray("symbol context: ").Default(symbolContext)
 	}
+	return sysPrompt + symbolContext
+}
 
-	return metrics
+// symbolReferences formats the symbols in the order of relevance, as many as the context window allows.
+// Returns the formatted ones and the number of omitted ones.
+func (me SymbolAwareAgent) symbolReferences(x Kontext, modelId string, mem ChatMemory, symbols []Symbol) ([]string, int) {
+	budget := me.modelService.RemainingPromptTokens(modelId, mem)
+
+	r := []string{}
+	for i, s := range symbols {
+		location := strings.TrimPrefix(strings.TrimPrefix(s.Path, x.Args.Repository), "/")
+		if s.LineBegin > 0 {
+			location = fmt.Sprintf("%s:%d", location, s.LineBegin)
+		}
+		detail := fmt.Sprintf("// %s, in %s\n%s", s.Name, location, s.Definition())
+
+		if budget >= 0 {
+			// plus the line break
+			tokens := me.modelService.EvaluatedTokens(modelId, detail) + 1
+			if tokens > budget {
+				return r, len(symbols) - i
+			}
+			budget -= tokens
+		}
+		r = append(r, detail)
+	}
+	return r, 0
 }
@@ -0,0 +1,163 @@
+package batchai
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// the ctags kinds named differently from SYMBOL_KIND_*
+var ctagsKinds = map[string]string{
+	"func":     SYMBOL_KIND_FUNCTION,
+	"member":   SYMBOL_KIND_FIELD,
+	"property": SYMBOL_KIND_FIELD,
+	"typedef":  SYMBOL_KIND_TYPE,
+	"const":    SYMBOL_KIND_CONSTANT,
+	"var":      SYMBOL_KIND_VARIABLE,
+}
+
+type ctagsTagT struct {
+	Type      string `json:"_type"`
+	Name      string `json:"name"`
+	Pattern   string `json:"pattern"`
+	Kind      string `json:"kind"`
+	Scope     string `json:"scope"`
+	Signature string `json:"signature"`
+	Line      int    `json:"line"`
+	End       int    `json:"end"`
+}
+
+// ParseCtagsJson parses the output of `ctags --output-format=json --fields=+nKSe`, the tags are taken as the symbols of file
+func ParseCtagsJson(output string, file string) []Symbol {
+	r := []Symbol{}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+
+		var tag ctagsTagT
+		if err := json.Unmarshal([]byte(line), &tag); err != nil {
+			panic(errors.Wrapf(err, "failed to parse ctags output: %s", line))
+		}
+		// skips the pseudo tags
+		if tag.Type != "tag" || len(tag.Name) == 0 {
+			continue
+		}
+
+		name := tag.Name
+		if len(tag.Scope) > 0 {
+			name = tag.Scope + "." + name
+		}
+
+		kind := tag.Kind
+		if k, has := ctagsKinds[kind]; has {
+			kind = k
+		}
+
+		lineEnd := tag.End
+		if lineEnd < tag.Line {
+			lineEnd = tag.Line
+		}
+
+		signature := ctagsPatternText(tag.Pattern)
+		if len(signature) == 0 {
+			signature = tag.Name + tag.Signature
+		}
+
+		r = append(r, &SymbolT{
+			Name:      name,
+			Path:      file,
+			Kind:      kind,
+			LineBegin: tag.Line,
+			LineEnd:   lineEnd,
+			Signature: signature,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		panic(errors.Wrap(err, "failed to read ctags output"))
+	}
+
+	return r
+}
+
+// ctagsPatternText returns the source line of a ctags search pattern, e.g. `/^func foo() {$/`
+func ctagsPatternText(pattern string) string {
+	if len(pattern) < 2 || pattern[0] != '/' || pattern[len(pattern)-1] != '/' {
+		return ""
+	}
+	r := pattern[1 : len(pattern)-1]
+	r = strings.TrimPrefix(r, "^")
+	r = strings.TrimSuffix(r, "$")
+	r = strings.ReplaceAll(r, `\/`, "/")
+	r = strings.ReplaceAll(r, `\\`, `\`)
+	return strings.TrimSpace(r)
+}
+
+// CtagsSymbolExtractorT imports the symbols from universal-ctags, for any language it supports
+type CtagsSymbolExtractorT struct {
+	command       string
+	availableOnce sync.Once
+	available     bool
+}
+
+type CtagsSymbolExtractor = *CtagsSymbolExtractorT
+
+func NewCtagsSymbolExtractor() CtagsSymbolExtractor {
+	return &CtagsSymbolExtractorT{command: "ctags"}
+}
+
+func (me CtagsSymbolExtractor) Name() string {
+	return SYMBOL_EXTRACTOR_CTAGS
+}
+
+func (me CtagsSymbolExtractor) Extensions() []string {
+	return nil
+}
+
+func (me CtagsSymbolExtractor) Cost() int {
+	return SYMBOL_EXTRACTOR_COST_PROCESS
+}
+
+// Available tells if the ctags binary is present and supports JSON output, which is universal-ctags only
+func (me CtagsSymbolExtractor) Available() bool {
+	me.availableOnce.Do(func() {
+		if _, err := exec.LookPath(me.command); err != nil {
+			return
+		}
+		output, err := exec.Command(me.command, "--list-features").Output()
+		me.available = err == nil && strings.Contains(string(output), "json")
+	})
+	return me.available
+}
+
+func (me CtagsSymbolExtractor) Extract(x Kontext, file string, code string) []Symbol {
+	// ctags reads the file by itself, so writes the latest code into a temporary file of same name to keep the language
+	tmpDir, err := os.MkdirTemp("", "batchai-ctags-")
+	if err != nil {
+		panic(errors.Wrap(err, "failed to create temporary directory for ctags"))
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tmpFile := filepath.Join(tmpDir, filepath.Base(file))
+	if err := os.WriteFile(tmpFile, []byte(code), 0o600); err != nil {
+		panic(errors.Wrapf(err, "failed to write temporary file for ctags: %s", tmpFile))
+	}
+
+	command := exec.CommandContext(x.Context, me.command, "--output-format=json", "--fields=+nKSe", "--sort=no", "-f", "-", tmpFile)
+	output, err := command.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to run ctags on %s", file))
+	}
+
+	return ParseCtagsJson(string(output), file)
+}
@@ -0,0 +1,33 @@
+package batchai
+
+// the names of symbol extractors, recorded in the extracted symbols
+const (
+	SYMBOL_EXTRACTOR_GO         = "go"
+	SYMBOL_EXTRACTOR_JAVA       = "regex-java"
+	SYMBOL_EXTRACTOR_PYTHON     = "regex-python"
+	SYMBOL_EXTRACTOR_TYPESCRIPT = "regex-typescript"
+	SYMBOL_EXTRACTOR_CTAGS      = "ctags"
+	// the fallback if no extractor is available for the file
+	SYMBOL_EXTRACTOR_MODEL = "model"
+)
+
+// the relative costs of symbol extractors
+const (
+	// parses in process
+	SYMBOL_EXTRACTOR_COST_PARSER = 1
+	// matches line by line in process
+	SYMBOL_EXTRACTOR_COST_REGEX = 2
+	// spawns an external process
+	SYMBOL_EXTRACTOR_COST_PROCESS = 10
+)
+
+// SymbolExtractor extracts the symbols of a file natively, without asking the model
+type SymbolExtractor interface {
+	Name() string
+	// the supported file extensions including the dot, or empty for any file
+	Extensions() []string
+	// relative cost, the cheapest available extractor is picked
+	Cost() int
+	Available() bool
+	Extract(x Kontext, file string, code string) []Symbol
+}
@@ -0,0 +1,64 @@
+package batchai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testSymbolExtractorT struct {
+	name       string
+	extensions []string
+	cost       int
+	available  bool
+}
+
+func (me *testSymbolExtractorT) Name() string         { return me.name }
+func (me *testSymbolExtractorT) Extensions() []string { return me.extensions }
+func (me *testSymbolExtractorT) Cost() int            { return me.cost }
+func (me *testSymbolExtractorT) Available() bool      { return me.available }
+func (me *testSymbolExtractorT) Extract(x Kontext, file string, code string) []Symbol {
+	return []Symbol{&SymbolT{Name: me.name, Path: file}}
+}
+
+func TestSymbolManagerExtractor(t *testing.T) {
+	a := require.New(t)
+
+	m := &SymbolManagerT{extractors: map[string][]SymbolExtractor{}}
+	a.Nil(m.Extractor("a.rb"))
+
+	m.RegisterExtractor(&testSymbolExtractorT{name: "any", cost: 10, available: true})
+	m.RegisterExtractor(&testSymbolExtractorT{name: "ruby", extensions: []string{".RB"}, cost: 2, available: true})
+	m.RegisterExtractor(&testSymbolExtractorT{name: "cheaper-ruby", extensions: []string{".rb"}, cost: 1, available: false})
+
+	a.Equal("ruby", m.Extractor("lib/a.rb").Name())
+	a.Equal("any", m.Extractor("lib/a.c").Name())
+
+	m = NewSymbolManager()
+	a.Equal(SYMBOL_EXTRACTOR_GO, m.Extractor("a.go").Name())
+	a.Equal(SYMBOL_EXTRACTOR_JAVA, m.Extractor("A.java").Name())
+	a.Equal(SYMBOL_EXTRACTOR_PYTHON, m.Extractor("a.py").Name())
+	a.Equal(SYMBOL_EXTRACTOR_TYPESCRIPT, m.Extractor("a.tsx").Name())
+}
+
+func TestParseCtagsJson(t *testing.T) {
+	a := require.New(t)
+
+	output := `{"_type": "ptag", "name": "JSON_OUTPUT_VERSION", "path": "0.0", "pattern": "in development"}
+{"_type": "tag", "name": "Calc", "path": "/tmp/calc.rb", "pattern": "/^class Calc$/", "line": 1, "kind": "class", "end": 5}
+{"_type": "tag", "name": "add", "path": "/tmp/calc.rb", "pattern": "/^  def add(a, b) \/\/ sum$/", "line": 2, "kind": "method", "scope": "Calc", "scopeKind": "class", "end": 4}
+{"_type": "tag", "name": "MAX", "path": "/tmp/calc.rb", "line": 7, "kind": "member", "signature": " = 1"}
+`
+	symbols := ParseCtagsJson(output, "/repo/calc.rb")
+	a.Equal([]string{
+		"class Calc 1-5",
+		"method Calc.add 2-4",
+		"field MAX 7-7",
+	}, formatSymbols(symbols))
+	a.Equal("class Calc", symbols[0].Signature)
+	a.Equal("def add(a, b) // sum", symbols[1].Signature)
+	a.Equal("MAX = 1", symbols[2].Signature)
+	a.Equal("/repo/calc.rb", symbols[1].Path)
+
+	a.Panics(func() { ParseCtagsJson("not json", "/repo/calc.rb") })
+}
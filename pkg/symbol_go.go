@@ -0,0 +1,189 @@
+package batchai
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// GoPackagePath returns the import path of the package in the directory, by the nearest go.mod up to the repository.
+// It returns empty if no go.mod found.
+func GoPackagePath(fs afero.Fs, repository string, dir string) string {
+	for moduleDir := dir; ; moduleDir = filepath.Dir(moduleDir) {
+		if modulePath := goModulePath(fs, moduleDir); len(modulePath) > 0 {
+			rel, _ := filepath.Rel(moduleDir, dir)
+			if rel == "." {
+				return modulePath
+			}
+			return modulePath + "/" + filepath.ToSlash(rel)
+		}
+
+		if len(moduleDir) <= len(repository) || moduleDir == filepath.Dir(moduleDir) {
+			return ""
+		}
+	}
+}
+
+type goSymbolCollectorT struct {
+	fset      *token.FileSet
+	source    []byte
+	path      string
+	qualifier string
+	symbols   []Symbol
+}
+
+// ExtractGoSymbols extracts the symbols declared in the Go file by parsing it, instead of asking the model.
+// The names are qualified by the package import path, e.g. `github.com/foo/bar.Type.Method`,
+// or by the package name if the package import path is unknown.
+func ExtractGoSymbols(fs afero.Fs, repository string, file string, code string) []Symbol {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, code, parser.SkipObjectResolution)
+	if f == nil {
+		panic(errors.Wrapf(err, "failed to parse %s", file))
+	}
+	// a file with syntax errors is still partially parsed, so take the symbols as many as possible
+
+	qualifier := GoPackagePath(fs, repository, filepath.Dir(file))
+	if len(qualifier) == 0 {
+		qualifier = f.Name.Name
+	}
+
+	me := &goSymbolCollectorT{fset: fset, source: []byte(code), path: file, qualifier: qualifier, symbols: []Symbol{}}
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			me.extractFunc(d)
+		case *ast.GenDecl:
+			me.extractGenDecl(d)
+		}
+	}
+	return me.symbols
+}
+
+func (me *goSymbolCollectorT) add(kind string, name string, node ast.Node, signature string) {
+	me.symbols = append(me.symbols, &SymbolT{
+		Name:      me.qualifier + "." + name,
+		Path:      me.path,
+		Kind:      kind,
+		LineBegin: me.fset.Position(node.Pos()).Line,
+		LineEnd:   me.fset.Position(node.End()).Line,
+		Signature: signature,
+	})
+}
+
+func (me *goSymbolCollectorT) text(node ast.Node) string {
+	return string(me.source[me.fset.Position(node.Pos()).Offset:me.fset.Position(node.End()).Offset])
+}
+
+func (me *goSymbolCollectorT) extractFunc(d *ast.FuncDecl) {
+	if d.Name.Name == "_" || (d.Recv == nil && d.Name.Name == "init") {
+		return
+	}
+
+	// the signature excludes the body
+	var signature bytes.Buffer
+	if err := printer.Fprint(&signature, me.fset, &ast.FuncDecl{Recv: d.Recv, Name: d.Name, Type: d.Type}); err != nil {
+		panic(errors.Wrapf(err, "failed to print the signature of %s", d.Name.Name))
+	}
+
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		me.add(SYMBOL_KIND_FUNCTION, d.Name.Name, d, signature.String())
+		return
+	}
+	me.add(SYMBOL_KIND_METHOD, goReceiverTypeName(d.Recv.List[0].Type)+"."+d.Name.Name, d, signature.String())
+}
+
+func (me *goSymbolCollectorT) extractGenDecl(d *ast.GenDecl) {
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			me.extractType(s)
+		case *ast.ValueSpec:
+			kind := SYMBOL_KIND_VARIABLE
+			if d.Tok == token.CONST {
+				kind = SYMBOL_KIND_CONSTANT
+			}
+			signature := d.Tok.String() + " " + me.text(s)
+			for _, name := range s.Names {
+				if name.Name != "_" {
+					me.add(kind, name.Name, s, signature)
+				}
+			}
+		}
+	}
+}
+
+func (me *goSymbolCollectorT) extractType(s *ast.TypeSpec) {
+	if s.Name.Name == "_" {
+		return
+	}
+
+	signature := "type " + me.text(s)
+	switch t := s.Type.(type) {
+	case *ast.StructType:
+		me.add(SYMBOL_KIND_STRUCT, s.Name.Name, s, signature)
+		me.extractFields(s.Name.Name, t.Fields, SYMBOL_KIND_FIELD)
+	case *ast.InterfaceType:
+		me.add(SYMBOL_KIND_INTERFACE, s.Name.Name, s, signature)
+		me.extractFields(s.Name.Name, t.Methods, SYMBOL_KIND_METHOD)
+	default:
+		me.add(SYMBOL_KIND_TYPE, s.Name.Name, s, signature)
+	}
+}
+
+// extractFields extracts the named fields of struct, or the methods of interface; the embedded ones are skipped
+func (me *goSymbolCollectorT) extractFields(typeName string, fields *ast.FieldList, kind string) {
+	if fields == nil {
+		return
+	}
+	for _, field := range fields.List {
+		for _, name := range field.Names {
+			if name.Name == "_" {
+				continue
+			}
+			signature := me.text(field)
+			if len(field.Names) > 1 {
+				signature = fmt.Sprintf("%s %s", name.Name, me.text(field.Type))
+			}
+			me.add(kind, typeName+"."+name.Name, field, strings.TrimSpace(signature))
+		}
+	}
+}
+
+// GoSymbolExtractorT extracts the symbols of Go files by go/parser
+type GoSymbolExtractorT struct {
+}
+
+type GoSymbolExtractor = *GoSymbolExtractorT
+
+func NewGoSymbolExtractor() GoSymbolExtractor {
+	return &GoSymbolExtractorT{}
+}
+
+func (me GoSymbolExtractor) Name() string {
+	return SYMBOL_EXTRACTOR_GO
+}
+
+func (me GoSymbolExtractor) Extensions() []string {
+	return []string{".go"}
+}
+
+func (me GoSymbolExtractor) Cost() int {
+	return SYMBOL_EXTRACTOR_COST_PARSER
+}
+
+func (me GoSymbolExtractor) Available() bool {
+	return true
+}
+
+func (me GoSymbolExtractor) Extract(x Kontext, file string, code string) []Symbol {
+	return ExtractGoSymbols(x.Fs, x.Args.Repository, file, code)
+}
@@ -0,0 +1,106 @@
+package batchai
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+const testGoSymbolCode = `package calc
+
+import "fmt"
+
+// Max is the max value
+const Max, Min = 100, 0
+
+var _ = fmt.Sprint
+
+type Op func(a, b int) int
+
+type Calc struct {
+	Name    string ` + "`json:\"name\"`" + `
+	x, y    int
+	fmt.Stringer
+}
+
+type Adder interface {
+	Add(a, b int) int
+}
+
+func init() {}
+
+// NewCalc creates a Calc
+func NewCalc(name string) *Calc {
+	return &Calc{Name: name}
+}
+
+func (me *Calc) Add(a, b int) int {
+	return a + b
+}
+`
+
+func TestExtractGoSymbols(t *testing.T) {
+	a := require.New(t)
+
+	fs := afero.NewMemMapFs()
+	comm.WriteFileTextP(fs, "/repo/go.mod", "module example.com/demo\n")
+
+	symbols := ExtractGoSymbols(fs, "/repo", "/repo/pkg/calc/calc.go", testGoSymbolCode)
+
+	byName := map[string]Symbol{}
+	names := []string{}
+	for _, s := range symbols {
+		a.Equal("/repo/pkg/calc/calc.go", s.Path)
+		byName[s.Name] = s
+		names = append(names, s.Name)
+	}
+
+	q := "example.com/demo/pkg/calc."
+	a.Equal([]string{
+		q + "Max", q + "Min", q + "Op", q + "Calc", q + "Calc.Name", q + "Calc.x", q + "Calc.y",
+		q + "Adder", q + "Adder.Add", q + "NewCalc", q + "Calc.Add",
+	}, names)
+
+	a.Equal(&SymbolT{Name: q + "NewCalc", Path: "/repo/pkg/calc/calc.go", Kind: SYMBOL_KIND_FUNCTION,
+		LineBegin: 25, LineEnd: 27, Signature: "func NewCalc(name string) *Calc"}, byName[q+"NewCalc"])
+
+	add := byName[q+"Calc.Add"]
+	a.Equal(SYMBOL_KIND_METHOD, add.Kind)
+	a.Equal("func (me *Calc) Add(a, b int) int", add.Signature)
+	a.Equal(add.Signature, add.Definition())
+
+	a.Equal(SYMBOL_KIND_CONSTANT, byName[q+"Max"].Kind)
+	a.Equal("const Max, Min = 100, 0", byName[q+"Min"].Signature)
+
+	calc := byName[q+"Calc"]
+	a.Equal(SYMBOL_KIND_STRUCT, calc.Kind)
+	a.Equal(12, calc.LineBegin)
+	a.Equal(16, calc.LineEnd)
+
+	a.Equal(SYMBOL_KIND_FIELD, byName[q+"Calc.Name"].Kind)
+	a.Equal("Name    string `json:\"name\"`", byName[q+"Calc.Name"].Signature)
+	a.Equal("y int", byName[q+"Calc.y"].Signature)
+	a.Equal(SYMBOL_KIND_INTERFACE, byName[q+"Adder"].Kind)
+	a.Equal("Add(a, b int) int", byName[q+"Adder.Add"].Signature)
+	a.Equal(SYMBOL_KIND_TYPE, byName[q+"Op"].Kind)
+
+	// qualified by the package name if no go.mod
+	symbols = ExtractGoSymbols(afero.NewMemMapFs(), "/repo", "/repo/calc.go", testGoSymbolCode)
+	a.Equal("calc.Max", symbols[0].Name)
+}
+
+func TestGoPackagePath(t *testing.T) {
+	a := require.New(t)
+
+	fs := afero.NewMemMapFs()
+	comm.WriteFileTextP(fs, "/repo/go.mod", "module example.com/demo\n")
+	comm.WriteFileTextP(fs, "/repo/tools/go.mod", "module \"example.com/tools\"\n")
+
+	a.Equal("example.com/demo", GoPackagePath(fs, "/repo", "/repo"))
+	a.Equal("example.com/demo/a/b", GoPackagePath(fs, "/repo", "/repo/a/b"))
+	a.Equal("example.com/tools/gen", GoPackagePath(fs, "/repo", "/repo/tools/gen"))
+	a.Empty(GoPackagePath(afero.NewMemMapFs(), "/repo", "/repo/a"))
+}
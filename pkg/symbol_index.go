@@ -0,0 +1,220 @@
+package batchai
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/qiangyt/batchai/comm"
+	"github.com/spf13/afero"
+)
+
+// the symbols of whole repository are indexed in this file under the cache directory, one line per source file
+const SYMBOL_INDEX_FILE = "symbols.batchai.jsonl"
+
+// SymbolIndexEntryT is the symbols of a source file
+type SymbolIndexEntryT struct {
+	// relative to the repository
+	Path string `json:"path"`
+	// sha256 of the file content, the symbols are valid only if it matches
+	Hash string `json:"hash"`
+	// the size and modification time are checked before the hash, to avoid reading the unchanged files
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"mod_time"`
+	// the paths of symbols are omitted
+	Symbols []Symbol `json:"symbols,omitempty"`
+}
+
+type SymbolIndexEntry = *SymbolIndexEntryT
+
+func HashCode(code string) string {
+	hash := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(hash[:])
+}
+
+// SymbolIndexT is an append-only JSON lines file, the last line of a path wins. It's compacted by Flush.
+type SymbolIndexT struct {
+	file    string
+	entries map[string]SymbolIndexEntry
+	// number of lines in the index file, more than the entries if some lines are superseded
+	lines int
+	lock  sync.Mutex
+}
+
+type SymbolIndex = *SymbolIndexT
+
+func ResolveSymbolIndexFile(cacheDir string, repository string) string {
+	return path.Join(cacheDir, path.Base(repository), SYMBOL_INDEX_FILE)
+}
+
+func LoadSymbolIndex(fs afero.Fs, file string) SymbolIndex {
+	r := &SymbolIndexT{file: file, entries: map[string]SymbolIndexEntry{}}
+	if !comm.FileExistsP(fs, file) {
+		return r
+	}
+
+	f, err := fs.Open(file)
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to open symbol index: %s", file))
+	}
+	defer f.Close()
+
+	lines := [][]byte{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Bytes(); len(line) > 0 {
+			lines = append(lines, append([]byte{}, line...))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		panic(errors.Wrapf(err, "failed to read symbol index: %s", file))
+	}
+	r.lines = len(lines)
+
+	// decoding is the most expensive part for large repositories, so decodes the chunks of lines concurrently
+	entries := make([]SymbolIndexEntry, len(lines))
+	chunkSize := (len(lines) + runtime.GOMAXPROCS(0) - 1) / runtime.GOMAXPROCS(0)
+	var wg sync.WaitGroup
+	for begin := 0; begin < len(lines); begin += chunkSize {
+		end := min(begin+chunkSize, len(lines))
+		wg.Add(1)
+		go func(begin int, end int) {
+			defer wg.Done()
+			for i := begin; i < end; i++ {
+				entry := &SymbolIndexEntryT{}
+				// skips the line partially written by an interrupted execution, the file is just extracted again
+				if err := json.Unmarshal(lines[i], entry); err == nil {
+					entries[i] = entry
+				}
+			}
+		}(begin, end)
+	}
+	wg.Wait()
+
+	for _, entry := range entries {
+		if entry != nil {
+			r.entries[entry.Path] = entry
+		}
+	}
+
+	return r
+}
+
+func (me SymbolIndex) Get(relativePath string) SymbolIndexEntry {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+
+	return me.entries[relativePath]
+}
+
+// Entries returns the entries sorted by path
+func (me SymbolIndex) Entries() []SymbolIndexEntry {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+
+	r := make([]SymbolIndexEntry, 0, len(me.entries))
+	for _, entry := range me.entries {
+		r = append(r, entry)
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].Path < r[j].Path })
+	return r
+}
+
+// Search returns the symbols of which the names contain the query case-insensitively, optionally filtered by the kind.
+// The symbol paths are relative to the repository.
+func (me SymbolIndex) Search(query string, kind string) []Symbol {
+	query = strings.ToLower(query)
+
+	r := []Symbol{}
+	for _, entry := range me.Entries() {
+		for _, s := range entry.Symbols {
+			if len(kind) > 0 && s.Kind != kind {
+				continue
+			}
+			if strings.Contains(strings.ToLower(s.Name), query) {
+				found := *s
+				found.Path = entry.Path
+				r = append(r, &found)
+			}
+		}
+	}
+	return r
+}
+
+// Put appends the entry to the index file, so the extracted symbols survive the interrupted execution
+func (me SymbolIndex) Put(fs afero.Fs, entry SymbolIndexEntry) {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+
+	me.append(fs, entry)
+	me.entries[entry.Path] = entry
+}
+
+func (me SymbolIndex) append(fs afero.Fs, entry SymbolIndexEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to marshal symbol index entry: %s", entry.Path))
+	}
+
+	comm.MkdirP(fs, path.Dir(me.file))
+	f, err := fs.OpenFile(me.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to open symbol index: %s", me.file))
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		panic(errors.Wrapf(err, "failed to write symbol index: %s", me.file))
+	}
+	me.lines++
+}
+
+// Flush removes the entries of the source files no longer existing, and rewrites the index file without the superseded lines
+func (me SymbolIndex) Flush(fs afero.Fs, repository string) {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+
+	for relativePath := range me.entries {
+		if !comm.FileExistsP(fs, path.Join(repository, relativePath)) {
+			delete(me.entries, relativePath)
+		}
+	}
+
+	if me.lines == len(me.entries) {
+		return
+	}
+
+	paths := make([]string, 0, len(me.entries))
+	for relativePath := range me.entries {
+		paths = append(paths, relativePath)
+	}
+	sort.Strings(paths)
+
+	var text strings.Builder
+	for _, relativePath := range paths {
+		line, err := json.Marshal(me.entries[relativePath])
+		if err != nil {
+			panic(errors.Wrapf(err, "failed to marshal symbol index entry: %s", relativePath))
+		}
+		text.Write(line)
+		text.WriteByte('\n')
+	}
+
+	// writes a temporary file then renames it, to not lose the index if interrupted
+	comm.MkdirP(fs, path.Dir(me.file))
+	tmpFile := me.file + ".tmp"
+	comm.WriteFileTextP(fs, tmpFile, text.String())
+	if err := comm.Rename(fs, tmpFile, me.file); err != nil {
+		panic(err)
+	}
+	me.lines = len(me.entries)
+}
@@ -0,0 +1,96 @@
+package batchai
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+func TestSymbolManagerIndex(t *testing.T) {
+	a := require.New(t)
+
+	x := newSymbolTestKontext()
+	comm.WriteFileTextP(x.Fs, "/repo/a.go", "package a\n\nfunc A() {}\n")
+	comm.WriteFileTextP(x.Fs, "/repo/b.go", "package b\n")
+
+	m := NewSymbolManager()
+	a.Nil(m.Load(x, "/repo/a.go"))
+
+	m.Save(x, "/repo/a.go", "package a\n\nfunc A() {}\n", []Symbol{
+		{Name: "a.A", Path: "/repo/a.go", Kind: SYMBOL_KIND_FUNCTION, LineBegin: 3, LineEnd: 3, Extractor: SYMBOL_EXTRACTOR_GO},
+	})
+	m.Save(x, "/repo/b.go", "package b\n", []Symbol{})
+	a.Len(m.Lookup(x, []SymbolQuery{{Name: "a.A"}}, ""), 1)
+
+	indexFile := ResolveSymbolIndexFile("/cache", "/repo")
+	a.Equal("/cache/repo/"+SYMBOL_INDEX_FILE, indexFile)
+	a.Len(strings.Split(strings.TrimSpace(comm.ReadFileTextP(x.Fs, indexFile)), "\n"), 2)
+
+	// loaded by another execution
+	m = NewSymbolManager()
+	symbols := m.Load(x, "/repo/a.go")
+	a.Len(symbols, 1)
+	a.Equal("a.A", symbols[0].Name)
+	a.Equal("/repo/a.go", symbols[0].Path)
+	a.Equal(SYMBOL_EXTRACTOR_GO, symbols[0].Extractor)
+	a.Empty(m.Index(x).Get("a.go").Symbols[0].Path)
+
+	// touched but not changed
+	a.NoError(x.Fs.Chtimes("/repo/a.go", time.Now(), time.Now().Add(time.Hour)))
+	a.Len(NewSymbolManager().Load(x, "/repo/a.go"), 1)
+
+	// changed
+	comm.WriteFileTextP(x.Fs, "/repo/a.go", "package a\n\nfunc B() {}\n")
+	m = NewSymbolManager()
+	a.Nil(m.Load(x, "/repo/a.go"))
+
+	m.Save(x, "/repo/a.go", "package a\n\nfunc B() {}\n", []Symbol{{Name: "a.B", Path: "/repo/a.go"}})
+	a.Empty(m.Lookup(x, []SymbolQuery{{Name: "a.A"}}, ""))
+	a.Len(m.Lookup(x, []SymbolQuery{{Name: "a.B"}}, ""), 1)
+	a.Len(strings.Split(strings.TrimSpace(comm.ReadFileTextP(x.Fs, indexFile)), "\n"), 3)
+
+	// compacted, and the removed file is pruned
+	a.NoError(x.Fs.Remove("/repo/b.go"))
+	m.Flush(x)
+	a.Equal([]string{"a.go"}, symbolIndexPaths(LoadSymbolIndex(x.Fs, indexFile)))
+	a.Len(strings.Split(strings.TrimSpace(comm.ReadFileTextP(x.Fs, indexFile)), "\n"), 1)
+}
+
+func symbolIndexPaths(index SymbolIndex) []string {
+	r := []string{}
+	for _, entry := range index.Entries() {
+		r = append(r, entry.Path)
+	}
+	return r
+}
+
+func TestLoadSymbolIndex(t *testing.T) {
+	a := require.New(t)
+
+	fs := afero.NewMemMapFs()
+	comm.WriteFileTextP(fs, "/cache/repo/"+SYMBOL_INDEX_FILE,
+		`{"path":"b.py","hash":"1","symbols":[{"name":"b.Foo","kind":"class"},{"name":"b.foo","kind":"function"}]}
+{"path":"a.py","hash":"2","symbols":[{"name":"a.bar","kind":"function"}]}
+{"path":"b.py","hash":"3","symbols":[{"name":"b.Foo","kind":"class"},{"name":"b.Foo.food","kind":"method"}]}
+{"path":"c.py","hash":`)
+
+	index := LoadSymbolIndex(fs, "/cache/repo/"+SYMBOL_INDEX_FILE)
+	a.Equal([]string{"a.py", "b.py"}, symbolIndexPaths(index))
+	a.Equal("3", index.Get("b.py").Hash)
+
+	found := index.Search("FOO", "")
+	a.Len(found, 2)
+	a.Equal("b.Foo", found[0].Name)
+	a.Equal("b.py", found[0].Path)
+
+	found = index.Search("foo", SYMBOL_KIND_METHOD)
+	a.Len(found, 1)
+	a.Equal("b.Foo.food", found[0].Name)
+
+	a.Empty(LoadSymbolIndex(fs, "/cache/other/"+SYMBOL_INDEX_FILE).Entries())
+}
@@ -1,8 +1,10 @@
 package batchai
 
 import (
+	"encoding/json"
 	"fmt"
 	"path"
+	"sort"
 	"strings"
 	"sync"
 
@@ -16,10 +18,32 @@ const SYMBOL_JSON_FORMAT = `
 }
 `
 
+const (
+	SYMBOL_KIND_FUNCTION  = "function"
+	SYMBOL_KIND_METHOD    = "method"
+	SYMBOL_KIND_TYPE      = "type"
+	SYMBOL_KIND_STRUCT    = "struct"
+	SYMBOL_KIND_INTERFACE = "interface"
+	SYMBOL_KIND_CLASS     = "class"
+	SYMBOL_KIND_ENUM      = "enum"
+	SYMBOL_KIND_FIELD     = "field"
+	SYMBOL_KIND_CONSTANT  = "constant"
+	SYMBOL_KIND_VARIABLE  = "variable"
+)
+
 type SymbolT struct {
 	Name  string `json:"name"`
-	Path  string `json:"path"`
-	Lines string `json:"lines"`
+	Path  string `json:"path,omitempty"`
+	Lines string `json:"lines,omitempty"`
+
+	// below are provided by the native extractors only
+	Kind      string `json:"kind,omitempty"`
+	LineBegin int    `json:"line_begin,omitempty"`
+	LineEnd   int    `json:"line_end,omitempty"`
+	Signature string `json:"signature,omitempty"`
+
+	// name of the extractor which produced this symbol, see SYMBOL_EXTRACTOR_*
+	Extractor string `json:"extractor,omitempty"`
 }
 
 type Symbol = *SymbolT
@@ -27,45 +51,235 @@ type Symbol = *SymbolT
 func (me Symbol) IsSame(that Symbol) bool {
 	return me.Name == that.Name &&
 		me.Path == that.Path &&
-		me.Lines == that.Lines
+		me.Lines == that.Lines &&
+		me.Kind == that.Kind &&
+		me.LineBegin == that.LineBegin &&
+		me.LineEnd == that.LineEnd &&
+		me.Signature == that.Signature &&
+		me.Extractor == that.Extractor
+}
+
+// Definition returns the declaration signature if extracted natively, otherwise the lines answered by the model
+func (me Symbol) Definition() string {
+	if len(me.Signature) > 0 {
+		return me.Signature
+	}
+	return me.Lines
 }
 
 type SymbolManagerT struct {
-	symbolsByName map[string][]Symbol
-	symbolsByFile map[string][]Symbol
-	lock          sync.RWMutex
+	// the inverted index by the simple names, e.g. `Method` of `pkg.Type.Method`
+	symbolsBySimpleName map[string][]Symbol
+	symbolsByFile       map[string][]Symbol
+	// by the file extensions, the empty key is for the extractors of any file
+	extractors map[string][]SymbolExtractor
+	index      SymbolIndex
+	indexOnce  sync.Once
+	// built by LoadAll from the repository files
+	dependencyGraph DependencyGraph
+	lock            sync.RWMutex
 }
 
 type SymbolManager = *SymbolManagerT
 
 func NewSymbolManager() SymbolManager {
-	return &SymbolManagerT{
-		symbolsByName: map[string][]Symbol{},
-		symbolsByFile: map[string][]Symbol{},
+	r := &SymbolManagerT{
+		symbolsBySimpleName: map[string][]Symbol{},
+		symbolsByFile:       map[string][]Symbol{},
+		extractors:          map[string][]SymbolExtractor{},
+	}
+
+	r.RegisterExtractor(NewGoSymbolExtractor())
+	r.RegisterExtractor(NewJavaSymbolExtractor())
+	r.RegisterExtractor(NewPythonSymbolExtractor())
+	r.RegisterExtractor(NewTypeScriptSymbolExtractor())
+	r.RegisterExtractor(NewCtagsSymbolExtractor())
+	return r
+}
+
+func (me SymbolManager) RegisterExtractor(extractor SymbolExtractor) {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+
+	extensions := extractor.Extensions()
+	if len(extensions) == 0 {
+		extensions = []string{""}
+	}
+	for _, ext := range extensions {
+		ext = strings.ToLower(ext)
+		me.extractors[ext] = append(me.extractors[ext], extractor)
 	}
 }
 
-func (me SymbolManager) Lookup(x Kontext, symbolNames []string, excludedFile string) []Symbol {
+// Extractor returns the cheapest available extractor for the file, or nil if the model has to extract
+func (me SymbolManager) Extractor(file string) SymbolExtractor {
 	me.lock.RLock()
 	defer me.lock.RUnlock()
 
-	r := []Symbol{}
-	for _, sn := range symbolNames {
-		for name, symbols := range me.symbolsByName {
-			if strings.Contains(name, sn) {
-				for _, s := range symbols {
-					if s.Path != excludedFile {
-						r = append(r, s)
-					}
+	candidates := append(append([]SymbolExtractor{}, me.extractors[strings.ToLower(path.Ext(file))]...), me.extractors[""]...)
+
+	var r SymbolExtractor
+	for _, candidate := range candidates {
+		if (r == nil || candidate.Cost() < r.Cost()) && candidate.Available() {
+			r = candidate
+		}
+	}
+	return r
+}
+
+// SymbolQueryT looks up the symbols by name, and optionally by kind
+type SymbolQueryT struct {
+	// simple or qualified name, e.g. `Method`, `Type.Method` or `pkg.Type.Method`
+	Name string `json:"name"`
+	// see SYMBOL_KIND_*, matches any kind if empty
+	Kind string `json:"kind,omitempty"`
+}
+
+type SymbolQuery = *SymbolQueryT
+
+// UnmarshalJSON accepts a plain name as well, e.g. `"Type.Method"`
+func (me *SymbolQueryT) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		me.Name = name
+		return nil
+	}
+
+	type symbolQuery SymbolQueryT
+	return json.Unmarshal(data, (*symbolQuery)(me))
+}
+
+// the match qualities of symbol lookup, the less the better
+const (
+	symbolMatchExact = iota
+	symbolMatchSuffix
+	// matched by the suffix of query, because the query name is qualified differently, e.g. by the module name
+	symbolMatchPartial
+)
+
+type symbolMatchT struct {
+	symbol   Symbol
+	quality  int
+	imported bool
+	distance int
+}
+
+// simpleSymbolName returns the last part of qualified name, e.g. `Method` of `github.com/foo/bar.Type.Method`
+func simpleSymbolName(name string) string {
+	return name[strings.LastIndexAny(name, "./")+1:]
+}
+
+func isSymbolNameSuffix(name string, suffix string) bool {
+	if !strings.HasSuffix(name, suffix) {
+		return false
+	}
+	if len(name) == len(suffix) {
+		return true
+	}
+	ch := name[len(name)-len(suffix)-1]
+	return ch == '.' || ch == '/'
+}
+
+// directoryDistance returns the number of directories to walk from the directory of a file to the directory of b file
+func directoryDistance(a string, b string) int {
+	aDirs := strings.Split(path.Dir(a), "/")
+	bDirs := strings.Split(path.Dir(b), "/")
+
+	common := 0
+	for common < len(aDirs) && common < len(bDirs) && aDirs[common] == bDirs[common] {
+		common++
+	}
+	return len(aDirs) - common + len(bDirs) - common
+}
+
+// Lookup finds the symbols by the queries, excluding the ones of the requesting file. The symbols of exactly same
+// names come first, then the ones qualified by the query names, then the ones of the files imported by the requesting
+// file, then the ones closer to the requesting file.
+// If nothing is found for a query, the leading parts of the query name are dropped one by one.
+func (me SymbolManager) Lookup(x Kontext, queries []SymbolQuery, requestingFile string) []Symbol {
+	imported := map[string]bool{}
+	for _, dependency := range me.Dependencies(requestingFile) {
+		imported[dependency] = true
+	}
+
+	me.lock.RLock()
+	defer me.lock.RUnlock()
+
+	matches := []*symbolMatchT{}
+	found := map[Symbol]bool{}
+
+	for _, q := range queries {
+		name := strings.TrimSuffix(strings.TrimSpace(q.Name), "()")
+		if len(name) == 0 {
+			continue
+		}
+
+		matched := len(matches)
+		quality := symbolMatchSuffix
+		for {
+			for _, s := range me.symbolsBySimpleName[simpleSymbolName(name)] {
+				if s.Path == requestingFile || found[s] || !isSymbolNameSuffix(s.Name, name) {
+					continue
+				}
+				// the symbols extracted by the model have no kind
+				if len(q.Kind) > 0 && len(s.Kind) > 0 && s.Kind != q.Kind {
+					continue
+				}
+
+				m := &symbolMatchT{symbol: s, quality: quality, imported: imported[s.Path], distance: directoryDistance(requestingFile, s.Path)}
+				if s.Name == name {
+					m.quality = symbolMatchExact
 				}
+				matches = append(matches, m)
+				found[s] = true
 			}
+
+			dot := strings.Index(name, ".")
+			if len(matches) > matched || dot < 0 {
+				break
+			}
+			name = name[dot+1:]
+			quality = symbolMatchPartial
 		}
 	}
 
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].quality != matches[j].quality {
+			return matches[i].quality < matches[j].quality
+		}
+		if matches[i].imported != matches[j].imported {
+			return matches[i].imported
+		}
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].symbol.Name < matches[j].symbol.Name
+	})
+
+	r := make([]Symbol, 0, len(matches))
+	for _, m := range matches {
+		r = append(r, m.symbol)
+	}
 	return r
 }
 
+// Index returns the symbol index of the repository, loaded at the first time
+func (me SymbolManager) Index(x Kontext) SymbolIndex {
+	me.indexOnce.Do(func() {
+		me.index = LoadSymbolIndex(x.Fs, ResolveSymbolIndexFile(x.Config.CacheDir, x.Args.Repository))
+	})
+	return me.index
+}
+
+func (me SymbolManager) relativePath(x Kontext, file string) string {
+	// the file is relative to working directory, so take the relative path
+	return strings.TrimPrefix(file[len(x.Args.Repository):], "/")
+}
+
+// Load returns the indexed symbols of the file, or nil if not indexed or the file is changed since indexed
 func (me SymbolManager) Load(x Kontext, file string) []Symbol {
+	index := me.Index(x)
+
 	me.lock.Lock()
 	defer me.lock.Unlock()
 
@@ -74,34 +288,30 @@ func (me SymbolManager) Load(x Kontext, file string) []Symbol {
 		return r
 	}
 
-	fs := x.Fs
-
-	symbolFile := ResolveSymbolFile(x.Config.CacheDir, x.Args.Repository, file)
-	if !comm.FileExistsP(fs, symbolFile) {
+	entry := index.Get(me.relativePath(x, file))
+	if entry == nil {
 		return nil
 	}
 
-	r = []Symbol{}
-	comm.FromJsonFileP(x.Fs, symbolFile, false, &r)
-	me.symbolsByFile[file] = r
-
-	// verify the symbols
-	for _, s := range r {
-		name := s.Name
-		if file != s.Path {
-			panic(fmt.Errorf("expect symbol.Path is %s but got %s", s.Path, file))
+	fs := x.Fs
+	info := comm.StatP(fs, file, false)
+	if info == nil {
+		return nil
+	}
+	if info.Size() != entry.Size || info.ModTime().UnixNano() != entry.ModTime {
+		// touched but maybe not changed
+		if HashCode(comm.ReadFileTextP(fs, file)) != entry.Hash {
+			return nil
 		}
+	}
 
-		if existings, has := me.symbolsByName[name]; has {
-			for _, existing := range existings {
-				if !s.IsSame(existing) {
-					me.symbolsByName[name] = append(existings, s)
-				}
-			}
-		} else {
-			me.symbolsByName[name] = []Symbol{s}
-		}
+	r = make([]Symbol, 0, len(entry.Symbols))
+	for _, s := range entry.Symbols {
+		loaded := *s
+		loaded.Path = file
+		r = append(r, &loaded)
 	}
+	me.register(file, r)
 
 	return r
 }
@@ -110,41 +320,79 @@ func (me SymbolManager) LoadAll(x Kontext, files []string) {
 	for _, f := range files {
 		me.Load(x, f)
 	}
+
+	me.lock.Lock()
+	me.dependencyGraph = NewDependencyGraph(x.Fs, x.Args.Repository, files)
+	me.lock.Unlock()
 }
 
-func (me SymbolManager) Save(x Kontext, file string, symbols []Symbol) {
+// Dependencies returns the files directly imported by the file, empty if the repository files are not loaded
+func (me SymbolManager) Dependencies(file string) []string {
+	me.lock.RLock()
+	graph := me.dependencyGraph
+	me.lock.RUnlock()
+
+	if graph == nil {
+		return nil
+	}
+	return graph.Dependencies(file)
+}
+
+// Save indexes the symbols of the file, the code is the file content which the symbols are extracted from
+func (me SymbolManager) Save(x Kontext, file string, code string, symbols []Symbol) {
+	index := me.Index(x)
+
 	me.lock.Lock()
 	defer me.lock.Unlock()
 
-	me.symbolsByFile[file] = symbols
-
 	for _, s := range symbols {
-		name := s.Name
 		if file != s.Path {
 			panic(fmt.Errorf("expect symbol.Path is %s but got %s", s.Path, file))
 		}
+	}
+	me.register(file, symbols)
 
-		if existings, has := me.symbolsByName[name]; has {
-			for _, existing := range existings {
-				if !s.IsSame(existing) {
-					me.symbolsByName[name] = append(existings, s)
-				}
+	entry := &SymbolIndexEntryT{Path: me.relativePath(x, file), Hash: HashCode(code), Symbols: make([]Symbol, 0, len(symbols))}
+	if info := comm.StatP(x.Fs, file, false); info != nil && info.Size() == int64(len(code)) {
+		entry.Size = info.Size()
+		entry.ModTime = info.ModTime().UnixNano()
+	}
+	for _, s := range symbols {
+		saved := *s
+		saved.Path = ""
+		entry.Symbols = append(entry.Symbols, &saved)
+	}
+	index.Put(x.Fs, entry)
+}
+
+// Flush compacts the symbol index, if it's loaded
+func (me SymbolManager) Flush(x Kontext) {
+	if me.index != nil {
+		me.index.Flush(x.Fs, x.Args.Repository)
+	}
+}
+
+// register replaces the symbols of the file in memory, the caller must hold the lock
+func (me SymbolManager) register(file string, symbols []Symbol) {
+	for _, s := range me.symbolsByFile[file] {
+		simpleName := simpleSymbolName(s.Name)
+		existings := me.symbolsBySimpleName[simpleName]
+		kept := make([]Symbol, 0, len(existings))
+		for _, existing := range existings {
+			if existing.Path != file {
+				kept = append(kept, existing)
 			}
+		}
+		if len(kept) == 0 {
+			delete(me.symbolsBySimpleName, simpleName)
 		} else {
-			me.symbolsByName[name] = []Symbol{s}
+			me.symbolsBySimpleName[simpleName] = kept
 		}
 	}
 
-	symbolFile := ResolveSymbolFile(x.Config.CacheDir, x.Args.Repository, file)
-	symbolText := comm.ToJsonP(symbols, true)
-
-	comm.MkdirP(x.Fs, path.Dir(symbolFile))
-	comm.WriteFileTextP(x.Fs, symbolFile, symbolText)
-}
-
-func ResolveSymbolFile(cacheDir string, repository string, file string) string {
-	// the file is relative to working directory, so take the relative path
-	relativePath := file[len(repository):]
-	repoName := path.Base(repository)
-	return path.Join(cacheDir, repoName, relativePath+".symbol.batchai.json")
+	me.symbolsByFile[file] = symbols
+	for _, s := range symbols {
+		simpleName := simpleSymbolName(s.Name)
+		me.symbolsBySimpleName[simpleName] = append(me.symbolsBySimpleName[simpleName], s)
+	}
 }
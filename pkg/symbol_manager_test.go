@@ -0,0 +1,122 @@
+package batchai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+func newLookupTestSymbolManager() SymbolManager {
+	m := NewSymbolManager()
+	m.register("/repo/pkg/a/a.go", []Symbol{
+		{Name: "example.com/demo/pkg/a.Id", Path: "/repo/pkg/a/a.go", Kind: SYMBOL_KIND_TYPE},
+		{Name: "example.com/demo/pkg/a.User.Id", Path: "/repo/pkg/a/a.go", Kind: SYMBOL_KIND_FIELD},
+		{Name: "example.com/demo/pkg/a.User.SetId", Path: "/repo/pkg/a/a.go", Kind: SYMBOL_KIND_METHOD},
+	})
+	m.register("/repo/pkg/b/b.go", []Symbol{
+		{Name: "example.com/demo/pkg/b.User.Id", Path: "/repo/pkg/b/b.go", Kind: SYMBOL_KIND_METHOD},
+		{Name: "example.com/demo/pkg/b.Valid", Path: "/repo/pkg/b/b.go", Kind: SYMBOL_KIND_FUNCTION},
+	})
+	m.register("/repo/web/app.ts", []Symbol{
+		{Name: "User.Id", Path: "/repo/web/app.ts", Kind: SYMBOL_KIND_FIELD},
+	})
+	m.register("/repo/web/util.py", []Symbol{
+		// extracted by the model, without kind
+		{Name: "Id", Path: "/repo/web/util.py"},
+	})
+	return m
+}
+
+func lookupNames(symbols []Symbol) []string {
+	r := []string{}
+	for _, s := range symbols {
+		r = append(r, s.Path+" "+s.Name)
+	}
+	return r
+}
+
+func TestSymbolManagerLookup(t *testing.T) {
+	a := require.New(t)
+
+	m := newLookupTestSymbolManager()
+	x := newSymbolTestKontext()
+
+	// no substring match, and the closest file first
+	a.Equal([]string{
+		"/repo/web/util.py Id",
+		"/repo/pkg/b/b.go example.com/demo/pkg/b.User.Id",
+		"/repo/pkg/a/a.go example.com/demo/pkg/a.Id",
+		"/repo/pkg/a/a.go example.com/demo/pkg/a.User.Id",
+		"/repo/web/app.ts User.Id",
+	}, lookupNames(m.Lookup(x, []SymbolQuery{{Name: "Id"}}, "/repo/pkg/b/main.go")))
+
+	// suffix qualified, the exact match first
+	a.Equal([]string{
+		"/repo/web/app.ts User.Id",
+		"/repo/pkg/a/a.go example.com/demo/pkg/a.User.Id",
+		"/repo/pkg/b/b.go example.com/demo/pkg/b.User.Id",
+	}, lookupNames(m.Lookup(x, []SymbolQuery{{Name: "User.Id"}}, "/repo/main.go")))
+
+	a.Equal([]string{
+		"/repo/pkg/b/b.go example.com/demo/pkg/b.User.Id",
+	}, lookupNames(m.Lookup(x, []SymbolQuery{{Name: "b.User.Id"}}, "/repo/main.go")))
+
+	// kind filtered, the unknown kind is matched
+	a.Equal([]string{
+		"/repo/web/util.py Id",
+		"/repo/pkg/a/a.go example.com/demo/pkg/a.User.Id",
+		"/repo/web/app.ts User.Id",
+	}, lookupNames(m.Lookup(x, []SymbolQuery{{Name: "Id", Kind: SYMBOL_KIND_FIELD}}, "/repo/pkg/b/main.go")))
+
+	// qualified differently, falls back to the suffix of query name; and the requesting file is excluded
+	a.Equal([]string{
+		"/repo/pkg/a/a.go example.com/demo/pkg/a.User.SetId",
+	}, lookupNames(m.Lookup(x, []SymbolQuery{{Name: "models.User.SetId()"}, {Name: "Valid"}}, "/repo/pkg/b/b.go")))
+
+	a.Len(m.Lookup(x, []SymbolQuery{{Name: "Unknown.Id"}, {Name: " "}}, "/repo/main.go"), 5)
+	a.Empty(m.Lookup(x, []SymbolQuery{{Name: "Unknown"}}, "/repo/main.go"))
+}
+
+func TestSymbolQueryUnmarshal(t *testing.T) {
+	a := require.New(t)
+
+	queries := []SymbolQuery{}
+	comm.FromJsonP(`["Type.Method", {"name": "Foo", "kind": "class"}]`, false, &queries)
+	a.Equal([]SymbolQuery{{Name: "Type.Method"}, {Name: "Foo", Kind: SYMBOL_KIND_CLASS}}, queries)
+}
+
+func TestSymbolReferencesBudget(t *testing.T) {
+	a := require.New(t)
+
+	model := newTestModelConfig(MODEL_PROVIDER_OPENAI, "http://localhost/")
+	model.ContextWindow = 1200
+	model.MaxCompletionTokens = 200
+	config := &AppConfigT{Models: []ModelConfig{model}}
+	agent := &SymbolAwareAgentT{BaseAgentT: newBaseAgent(NewModelService(config))}
+
+	symbols := []Symbol{}
+	for i := 0; i < 20; i++ {
+		symbols = append(symbols, &SymbolT{Name: "Foo", Path: "/repo/foo.go", Signature: strings.Repeat("func Foo() ", 10)})
+	}
+
+	mem := NewChatMemory().AddSystemMessage(strings.Repeat("code ", 500))
+	budget := agent.modelService.RemainingPromptTokens(model.Id, mem)
+	a.Greater(budget, 0)
+	a.Less(budget, 500)
+
+	references, omitted := agent.symbolReferences(newSymbolTestKontext(), model.Id, mem, symbols)
+	a.NotEmpty(references)
+	a.Equal(len(symbols), len(references)+omitted)
+	a.Greater(omitted, 0)
+	a.LessOrEqual(agent.modelService.EvaluatedTokens(model.Id, strings.Join(references, "\n")), budget)
+
+	// no budget if the context window is not configured
+	model.ContextWindow = 0
+	a.Equal(-1, agent.modelService.RemainingPromptTokens(model.Id, mem))
+	references, omitted = agent.symbolReferences(newSymbolTestKontext(), model.Id, mem, symbols)
+	a.Len(references, len(symbols))
+	a.Zero(omitted)
+}
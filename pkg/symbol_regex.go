@@ -0,0 +1,410 @@
+package batchai
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// RegexSymbolExtractorT extracts the symbols by matching the declarations line by line.
+// It's lightweight but not precise, e.g. the declarations across multiple lines are partially taken.
+type RegexSymbolExtractorT struct {
+	name       string
+	extensions []string
+	extract    func(x Kontext, file string, code string) []Symbol
+}
+
+type RegexSymbolExtractor = *RegexSymbolExtractorT
+
+func (me RegexSymbolExtractor) Name() string {
+	return me.name
+}
+
+func (me RegexSymbolExtractor) Extensions() []string {
+	return me.extensions
+}
+
+func (me RegexSymbolExtractor) Cost() int {
+	return SYMBOL_EXTRACTOR_COST_REGEX
+}
+
+func (me RegexSymbolExtractor) Available() bool {
+	return true
+}
+
+func (me RegexSymbolExtractor) Extract(x Kontext, file string, code string) []Symbol {
+	return me.extract(x, file, code)
+}
+
+// braceLanguageT describes the declarations of languages which scope by braces
+type braceLanguageT struct {
+	// group 1 is the keyword, group 2 is the name
+	typePattern *regexp.Regexp
+	// the keywords of type declarations, to the symbol kinds
+	typeKinds map[string]string
+	// below are matched in the body of types, group 1 is the name
+	methodPattern *regexp.Regexp
+	fieldPattern  *regexp.Regexp
+	// below are matched at the top level, optional
+	functionPattern *regexp.Regexp
+	// group 1 is the keyword, group 2 is the name
+	variablePattern *regexp.Regexp
+}
+
+const regexAnnotations = `(?:@[\w.]+(?:\([^)]*\))?\s*)*`
+
+const javaModifiers = `(?:(?:public|protected|private|abstract|static|final|sealed|non-sealed|strictfp|synchronized|native|default|transient|volatile)\s+)*`
+
+const javaTypeName = `[\w.$]+(?:\s*<.*>)?(?:\s*\[\s*\])*`
+
+var javaPackagePattern = regexp.MustCompile(`^\s*package\s+([\w.]+)\s*;`)
+
+var javaLanguage = &braceLanguageT{
+	typePattern: regexp.MustCompile(`^\s*` + regexAnnotations + javaModifiers + `(class|interface|enum|record|@interface)\s+(\w+)`),
+	typeKinds: map[string]string{
+		"class":      SYMBOL_KIND_CLASS,
+		"interface":  SYMBOL_KIND_INTERFACE,
+		"enum":       SYMBOL_KIND_ENUM,
+		"record":     SYMBOL_KIND_CLASS,
+		"@interface": SYMBOL_KIND_INTERFACE,
+	},
+	methodPattern: regexp.MustCompile(`^\s*` + regexAnnotations + javaModifiers + `(?:<.*>\s+)?(?:` + javaTypeName + `\s+)?(\w+)\s*\(`),
+	fieldPattern:  regexp.MustCompile(`^\s*` + regexAnnotations + javaModifiers + javaTypeName + `\s+(\w+)\s*(?:=|;|,)`),
+}
+
+const typeScriptModifiers = `(?:(?:public|private|protected|static|readonly|abstract|override|declare|async|get|set|accessor)\s+)*`
+
+var typeScriptLanguage = &braceLanguageT{
+	typePattern: regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?(?:const\s+)?(class|interface|enum|type)\s+([\w$]+)`),
+	typeKinds: map[string]string{
+		"class":     SYMBOL_KIND_CLASS,
+		"interface": SYMBOL_KIND_INTERFACE,
+		"enum":      SYMBOL_KIND_ENUM,
+		"type":      SYMBOL_KIND_TYPE,
+	},
+	methodPattern:   regexp.MustCompile(`^\s*` + regexAnnotations + typeScriptModifiers + `\*?(#?[\w$]+)\s*(?:<[^>]*>)?\s*\(`),
+	fieldPattern:    regexp.MustCompile(`^\s*` + regexAnnotations + typeScriptModifiers + `(#?[\w$]+)\s*[?!]?\s*(?::|=|;)`),
+	functionPattern: regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:async\s+)?function\s*\*?\s*([\w$]+)`),
+	variablePattern: regexp.MustCompile(`^\s*(?:export\s+)?(?:declare\s+)?(const|let|var)\s+([\w$]+)`),
+}
+
+func NewJavaSymbolExtractor() RegexSymbolExtractor {
+	return &RegexSymbolExtractorT{
+		name:       SYMBOL_EXTRACTOR_JAVA,
+		extensions: []string{".java"},
+		extract: func(x Kontext, file string, code string) []Symbol {
+			qualifier := ""
+			for _, line := range strings.Split(code, "\n") {
+				if m := javaPackagePattern.FindStringSubmatch(line); m != nil {
+					qualifier = m[1]
+					break
+				}
+			}
+			return extractBraceSymbols(javaLanguage, qualifier, file, code)
+		},
+	}
+}
+
+func NewTypeScriptSymbolExtractor() RegexSymbolExtractor {
+	return &RegexSymbolExtractorT{
+		name:       SYMBOL_EXTRACTOR_TYPESCRIPT,
+		extensions: []string{".ts", ".tsx", ".mts", ".cts"},
+		extract: func(x Kontext, file string, code string) []Symbol {
+			// the module is identified by the file path, so not qualified
+			return extractBraceSymbols(typeScriptLanguage, "", file, code)
+		},
+	}
+}
+
+type braceScopeT struct {
+	symbol Symbol
+	isType bool
+	// the brace depth where the declaration is
+	depth  int
+	opened bool
+}
+
+type braceSymbolCollectorT struct {
+	lang      *braceLanguageT
+	qualifier string
+	file      string
+	symbols   []Symbol
+	scopes    []*braceScopeT
+}
+
+// extractBraceSymbols takes the types and their members, and the top level functions and variables.
+// The line ranges are by the matching braces.
+func extractBraceSymbols(lang *braceLanguageT, qualifier string, file string, code string) []Symbol {
+	me := &braceSymbolCollectorT{lang: lang, qualifier: qualifier, file: file, symbols: []Symbol{}}
+
+	lines := strings.Split(code, "\n")
+	depth := 0
+	inComment := false
+	for i, line := range lines {
+		lineNo := i + 1
+
+		var stripped string
+		stripped, inComment = stripCodeLine(line, inComment)
+		if len(strings.TrimSpace(stripped)) > 0 {
+			me.matchLine(line, stripped, lineNo, depth)
+		}
+
+		for _, ch := range stripped {
+			switch ch {
+			case '{':
+				depth++
+				if top := me.top(); top != nil && !top.opened && depth == top.depth+1 {
+					top.opened = true
+				}
+			case '}':
+				depth--
+				for top := me.top(); top != nil && top.opened && depth <= top.depth; top = me.top() {
+					top.symbol.LineEnd = lineNo
+					me.scopes = me.scopes[:len(me.scopes)-1]
+				}
+			}
+		}
+	}
+
+	for _, scope := range me.scopes {
+		scope.symbol.LineEnd = len(lines)
+	}
+	return me.symbols
+}
+
+func (me *braceSymbolCollectorT) top() *braceScopeT {
+	if len(me.scopes) == 0 {
+		return nil
+	}
+	return me.scopes[len(me.scopes)-1]
+}
+
+func (me *braceSymbolCollectorT) matchLine(line string, stripped string, lineNo int, depth int) {
+	lang := me.lang
+
+	top := me.top()
+	atTop := depth == 0
+	inTypeBody := top != nil && top.isType && top.opened && depth == top.depth+1
+	if !atTop && !inTypeBody {
+		return
+	}
+
+	if m := lang.typePattern.FindStringSubmatch(stripped); m != nil {
+		kind := lang.typeKinds[m[1]]
+		// the type alias has no members
+		me.add(kind, m[2], line, stripped, lineNo, depth, kind != SYMBOL_KIND_TYPE)
+		return
+	}
+
+	if inTypeBody {
+		if m := lang.methodPattern.FindStringSubmatch(stripped); m != nil {
+			me.add(SYMBOL_KIND_METHOD, m[1], line, stripped, lineNo, depth, false)
+		} else if m := lang.fieldPattern.FindStringSubmatch(stripped); m != nil {
+			me.add(SYMBOL_KIND_FIELD, m[1], line, stripped, lineNo, depth, false)
+		}
+		return
+	}
+
+	if lang.functionPattern != nil {
+		if m := lang.functionPattern.FindStringSubmatch(stripped); m != nil {
+			me.add(SYMBOL_KIND_FUNCTION, m[1], line, stripped, lineNo, depth, false)
+			return
+		}
+	}
+	if lang.variablePattern != nil {
+		if m := lang.variablePattern.FindStringSubmatch(stripped); m != nil {
+			kind := SYMBOL_KIND_VARIABLE
+			if m[1] == "const" {
+				kind = SYMBOL_KIND_CONSTANT
+			}
+			me.add(kind, m[2], line, stripped, lineNo, depth, false)
+		}
+	}
+}
+
+// add adds the symbol, and opens a scope for the lines of it. The scope of type with members is opened by the
+// first brace even it's in later lines, while others are opened only if the brace is in same line, e.g. the method body.
+func (me *braceSymbolCollectorT) add(kind string, name string, line string, stripped string, lineNo int, depth int, isType bool) {
+	names := []string{}
+	if len(me.qualifier) > 0 {
+		names = append(names, me.qualifier)
+	}
+	for _, scope := range me.scopes {
+		names = append(names, scope.symbol.Name[strings.LastIndex(scope.symbol.Name, ".")+1:])
+	}
+	names = append(names, name)
+
+	s := &SymbolT{
+		Name:      strings.Join(names, "."),
+		Path:      me.file,
+		Kind:      kind,
+		LineBegin: lineNo,
+		LineEnd:   lineNo,
+		Signature: strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), "{")),
+	}
+	me.symbols = append(me.symbols, s)
+
+	if isType || strings.Count(stripped, "{") > strings.Count(stripped, "}") {
+		me.scopes = append(me.scopes, &braceScopeT{symbol: s, isType: isType, depth: depth})
+	}
+}
+
+// stripCodeLine removes the comments and the content of string literals, to count the braces
+func stripCodeLine(line string, inComment bool) (string, bool) {
+	var r strings.Builder
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		if inComment {
+			if ch == '*' && i+1 < len(line) && line[i+1] == '/' {
+				inComment = false
+				i++
+			}
+			continue
+		}
+		if quote != 0 {
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+				r.WriteByte(ch)
+			}
+			continue
+		}
+
+		switch {
+		case ch == '/' && i+1 < len(line) && line[i+1] == '/':
+			return r.String(), false
+		case ch == '/' && i+1 < len(line) && line[i+1] == '*':
+			inComment = true
+			i++
+		case ch == '"' || ch == '\'' || ch == '`':
+			quote = ch
+			r.WriteByte(ch)
+		default:
+			r.WriteByte(ch)
+		}
+	}
+	return r.String(), inComment
+}
+
+var (
+	pythonClassPattern  = regexp.MustCompile(`^class\s+(\w+)`)
+	pythonDefPattern    = regexp.MustCompile(`^(?:async\s+)?def\s+(\w+)`)
+	pythonAssignPattern = regexp.MustCompile(`^([A-Za-z_]\w*)\s*(?::[^=]+)?=(?:[^=]|$)`)
+	pythonConstantName  = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+)
+
+type pythonScopeT struct {
+	symbol  Symbol
+	indent  int
+	isClass bool
+}
+
+func NewPythonSymbolExtractor() RegexSymbolExtractor {
+	return &RegexSymbolExtractorT{
+		name:       SYMBOL_EXTRACTOR_PYTHON,
+		extensions: []string{".py"},
+		extract: func(x Kontext, file string, code string) []Symbol {
+			return extractPythonSymbols(pythonModuleName(x.Args.Repository, file), file, code)
+		},
+	}
+}
+
+// pythonModuleName returns the dotted module name by the path relative to repository, e.g. `foo.bar` for `foo/bar.py`
+func pythonModuleName(repository string, file string) string {
+	rel, err := filepath.Rel(repository, file)
+	if err != nil {
+		rel = filepath.Base(file)
+	}
+	r := strings.ReplaceAll(filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel))), "/", ".")
+	return strings.TrimSuffix(strings.TrimSuffix(r, "__init__"), ".")
+}
+
+// extractPythonSymbols takes the classes, functions, methods, class attributes and module level variables.
+// The line ranges are by the indentation.
+func extractPythonSymbols(module string, file string, code string) []Symbol {
+	r := []Symbol{}
+	scopes := []*pythonScopeT{}
+	lastLine := 0
+	inDocString := false
+
+	popScopes := func(indent int) {
+		for len(scopes) > 0 && scopes[len(scopes)-1].indent >= indent {
+			scopes[len(scopes)-1].symbol.LineEnd = lastLine
+			scopes = scopes[:len(scopes)-1]
+		}
+	}
+
+	for i, line := range strings.Split(code, "\n") {
+		lineNo := i + 1
+		trimmed := strings.TrimSpace(line)
+
+		quotes := strings.Count(trimmed, `"""`) + strings.Count(trimmed, `'''`)
+		if inDocString {
+			lastLine = lineNo
+			inDocString = quotes%2 == 0
+			continue
+		}
+		if len(trimmed) == 0 || trimmed[0] == '#' {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		popScopes(indent)
+		lastLine = lineNo
+		inDocString = quotes%2 == 1
+
+		var parent *pythonScopeT
+		if len(scopes) > 0 {
+			parent = scopes[len(scopes)-1]
+			// the nested declarations in functions are not visible
+			if !parent.isClass {
+				continue
+			}
+		}
+
+		names := []string{}
+		if len(module) > 0 {
+			names = append(names, module)
+		}
+		for _, scope := range scopes {
+			names = append(names, scope.symbol.Name[strings.LastIndex(scope.symbol.Name, ".")+1:])
+		}
+
+		newSymbol := func(kind string, name string) Symbol {
+			s := &SymbolT{
+				Name:      strings.Join(append(names, name), "."),
+				Path:      file,
+				Kind:      kind,
+				LineBegin: lineNo,
+				LineEnd:   lineNo,
+				Signature: strings.TrimSuffix(trimmed, ":"),
+			}
+			r = append(r, s)
+			return s
+		}
+
+		if m := pythonClassPattern.FindStringSubmatch(trimmed); m != nil {
+			scopes = append(scopes, &pythonScopeT{symbol: newSymbol(SYMBOL_KIND_CLASS, m[1]), indent: indent, isClass: true})
+		} else if m := pythonDefPattern.FindStringSubmatch(trimmed); m != nil {
+			kind := SYMBOL_KIND_FUNCTION
+			if parent != nil {
+				kind = SYMBOL_KIND_METHOD
+			}
+			scopes = append(scopes, &pythonScopeT{symbol: newSymbol(kind, m[1]), indent: indent})
+		} else if m := pythonAssignPattern.FindStringSubmatch(trimmed); m != nil {
+			kind := SYMBOL_KIND_FIELD
+			if parent == nil {
+				kind = SYMBOL_KIND_VARIABLE
+				if pythonConstantName.MatchString(m[1]) {
+					kind = SYMBOL_KIND_CONSTANT
+				}
+			}
+			newSymbol(kind, m[1])
+		}
+	}
+
+	popScopes(0)
+	return r
+}
@@ -0,0 +1,168 @@
+package batchai
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func formatSymbols(symbols []Symbol) []string {
+	r := []string{}
+	for _, s := range symbols {
+		r = append(r, fmt.Sprintf("%s %s %d-%d", s.Kind, s.Name, s.LineBegin, s.LineEnd))
+	}
+	return r
+}
+
+func newSymbolTestKontext() Kontext {
+	x := NewKontext(afero.NewMemMapFs())
+	x.Args = &AppArgsT{Repository: "/repo"}
+	x.Config = &AppConfigT{CacheDir: "/cache"}
+	return x
+}
+
+func TestJavaSymbolExtractor(t *testing.T) {
+	a := require.New(t)
+
+	code := `package com.example.calc;
+
+import java.util.List;
+
+/* class Commented { */
+@Service
+public class Calc implements Op {
+    private static final int MAX = 100;
+    private Map<String, List<Integer>> cache = new HashMap<>();
+
+    public Calc(int max) {
+        this.max = max;
+    }
+
+    @Override
+    public int add(int a, int b) {
+        String s = "}";
+        return a + b;
+    }
+
+    static class Inner {
+        void run() {}
+    }
+}
+
+interface Op {
+    int add(int a, int b);
+}
+`
+	symbols := NewJavaSymbolExtractor().Extract(newSymbolTestKontext(), "/repo/Calc.java", code)
+	a.Equal([]string{
+		"class com.example.calc.Calc 7-24",
+		"field com.example.calc.Calc.MAX 8-8",
+		"field com.example.calc.Calc.cache 9-9",
+		"method com.example.calc.Calc.Calc 11-13",
+		"method com.example.calc.Calc.add 16-19",
+		"class com.example.calc.Calc.Inner 21-23",
+		"method com.example.calc.Calc.Inner.run 22-22",
+		"interface com.example.calc.Op 26-28",
+		"method com.example.calc.Op.add 27-27",
+	}, formatSymbols(symbols))
+	a.Equal("public int add(int a, int b)", symbols[4].Signature)
+	a.Equal("/repo/Calc.java", symbols[4].Path)
+}
+
+func TestTypeScriptSymbolExtractor(t *testing.T) {
+	a := require.New(t)
+
+	code := "import { x } from './x';\n" +
+		"\n" +
+		"export const MAX = 100;\n" +
+		"let count = 0;\n" +
+		"\n" +
+		"export interface Shape {\n" +
+		"  name: string;\n" +
+		"  area(): number;\n" +
+		"}\n" +
+		"\n" +
+		"export type Point = { x: number; y: number };\n" +
+		"\n" +
+		"export default class Circle implements Shape {\n" +
+		"  private readonly radius: number = 1;\n" +
+		"  name = `circle {`;\n" +
+		"\n" +
+		"  constructor(radius: number) {\n" +
+		"    this.radius = radius;\n" +
+		"  }\n" +
+		"\n" +
+		"  async area(): Promise<number> {\n" +
+		"    const local = 1;\n" +
+		"    return Math.PI * this.radius;\n" +
+		"  }\n" +
+		"}\n" +
+		"\n" +
+		"export async function draw(shape: Shape): void {\n" +
+		"  let inner = 0;\n" +
+		"}\n"
+
+	symbols := NewTypeScriptSymbolExtractor().Extract(newSymbolTestKontext(), "/repo/src/shape.ts", code)
+	a.Equal([]string{
+		"constant MAX 3-3",
+		"variable count 4-4",
+		"interface Shape 6-9",
+		"field Shape.name 7-7",
+		"method Shape.area 8-8",
+		"type Point 11-11",
+		"class Circle 13-25",
+		"field Circle.radius 14-14",
+		"field Circle.name 15-15",
+		"method Circle.constructor 17-19",
+		"method Circle.area 21-24",
+		"function draw 27-29",
+	}, formatSymbols(symbols))
+}
+
+func TestPythonSymbolExtractor(t *testing.T) {
+	a := require.New(t)
+
+	code := `"""Module doc."""
+import os
+
+MAX = 100
+cache: dict = {}
+
+
+class Calc(Base):
+    """
+def not_a_function():
+    """
+    name = "calc"
+
+    def __init__(self, x):
+        self.x = x
+
+        def nested():
+            pass
+
+    @property
+    async def add(self, a, b):
+        return a + b
+
+
+def main():
+    if MAX == 1:
+        pass
+`
+	symbols := NewPythonSymbolExtractor().Extract(newSymbolTestKontext(), "/repo/pkg/calc.py", code)
+	a.Equal([]string{
+		"constant pkg.calc.MAX 4-4",
+		"variable pkg.calc.cache 5-5",
+		"class pkg.calc.Calc 8-22",
+		"field pkg.calc.Calc.name 12-12",
+		"method pkg.calc.Calc.__init__ 14-18",
+		"method pkg.calc.Calc.add 21-22",
+		"function pkg.calc.main 25-27",
+	}, formatSymbols(symbols))
+	a.Equal("def __init__(self, x)", symbols[4].Signature)
+
+	a.Equal("pkg", pythonModuleName("/repo", "/repo/pkg/__init__.py"))
+}
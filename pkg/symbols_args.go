@@ -0,0 +1,61 @@
+package batchai
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+)
+
+type SymbolsArgsT struct {
+	Search string
+	Kind   string
+	Dump   bool
+	Update bool
+}
+
+type SymbolsArgs = *SymbolsArgsT
+
+func (me SymbolsArgs) WithCliContext(x Kontext, cliContext *cli.Context) error {
+	me.Search = cliContext.String("search")
+	me.Kind = cliContext.String("kind")
+	me.Dump = cliContext.Bool("dump")
+	me.Update = cliContext.Bool("update")
+	return nil
+}
+
+func SymbolsUrfaveCommand(x Kontext) *cli.Command {
+	return &cli.Command{
+		Name:  "symbols",
+		Usage: fmt.Sprintf("Lists, searches or dumps the symbol index saved in '%s'", os.Getenv("BATCHAI_CACHE_DIR")),
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "search", Aliases: []string{"s"}, Usage: "Searches the symbols of which the names contain the text"},
+			&cli.StringFlag{Name: "kind", Usage: "Searches the symbols of the kind only, e.g. function, method, class"},
+			&cli.BoolFlag{Name: "dump", DefaultText: "false", Usage: "Prints the index entries as JSON lines"},
+			&cli.BoolFlag{Name: "update", DefaultText: "false", Usage: "Extracts the symbols of the files changed since indexed, before listing"},
+		},
+		Args:   true,
+		Action: SymbolsFunc(x),
+	}
+}
+
+func SymbolsFunc(x Kontext) func(*cli.Context) error {
+	return func(cliContext *cli.Context) error {
+		x.Config.Init("symbols")
+
+		a := &AppArgsT{}
+		if err := a.WithCliContext(x, cliContext); err != nil {
+			return err
+		}
+		x.Args = a
+
+		sa := &SymbolsArgsT{}
+		if err := sa.WithCliContext(x, cliContext); err != nil {
+			return err
+		}
+
+		NewSymbolsCommand(x).Symbols(x, sa)
+
+		return nil
+	}
+}
@@ -0,0 +1,126 @@
+package batchai
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+type SymbolsCommandT struct {
+	BaseModelCommandT
+}
+
+type SymbolsCommand = *SymbolsCommandT
+
+func NewSymbolsCommand(x Kontext) SymbolsCommand {
+	return &SymbolsCommandT{
+		BaseModelCommandT: *NewBaseModelCommand(x),
+	}
+}
+
+func (me SymbolsCommand) Symbols(x Kontext, symbolsArgs SymbolsArgs) {
+	c := comm.NewConsole(true)
+
+	if symbolsArgs.Update {
+		_, _, _, repoFiles := me.listCommand.CollectWorkingFiles(x, c)
+		me.launchSymbolAgents(x, repoFiles)
+	}
+
+	index := me.symbolManager.Index(x)
+	if symbolsArgs.Dump {
+		me.dump(x, c, index)
+	} else if len(symbolsArgs.Search) > 0 || len(symbolsArgs.Kind) > 0 {
+		me.search(x, c, index, symbolsArgs)
+	} else {
+		me.list(x, c, index)
+	}
+}
+
+// isTarget tells if the file relative to the repository is under the target paths, or no target path specified
+func (me SymbolsCommand) isTarget(x Kontext, relativePath string) bool {
+	if len(x.Args.TargetPaths) == 0 {
+		return true
+	}
+
+	file := path.Join(x.Args.Repository, relativePath)
+	for _, p := range x.Args.TargetPaths {
+		if file == p || strings.HasPrefix(file, strings.TrimSuffix(p, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (me SymbolsCommand) list(x Kontext, c comm.Console, index SymbolIndex) {
+	files, symbols, stale := 0, 0, 0
+
+	for _, entry := range index.Entries() {
+		if !me.isTarget(x, entry.Path) {
+			continue
+		}
+		files++
+		symbols += len(entry.Symbols)
+
+		extractors := map[string]bool{}
+		for _, s := range entry.Symbols {
+			extractors[s.Extractor] = true
+		}
+		extractorNames := make([]string, 0, len(extractors))
+		for name := range extractors {
+			if len(name) > 0 {
+				extractorNames = append(extractorNames, name)
+			}
+		}
+		sort.Strings(extractorNames)
+
+		c.NewLine().Default(entry.Path).Grayf(" %d symbols", len(entry.Symbols))
+		if len(extractorNames) > 0 {
+			c.Grayf(" by %s", strings.Join(extractorNames, ", "))
+		}
+		if me.symbolManager.Load(x, path.Join(x.Args.Repository, entry.Path)) == nil {
+			stale++
+			c.Yellow(" (stale)")
+		}
+	}
+
+	c.NewLine().NewLine().Defaultf("%d files, %d symbols indexed", files, symbols)
+	if stale > 0 {
+		c.Yellowf(", %d files stale, run with --update to extract them again", stale)
+	}
+	c.NewLine()
+}
+
+func (me SymbolsCommand) search(x Kontext, c comm.Console, index SymbolIndex, symbolsArgs SymbolsArgs) {
+	found := 0
+	for _, s := range index.Search(symbolsArgs.Search, symbolsArgs.Kind) {
+		if !me.isTarget(x, s.Path) {
+			continue
+		}
+		found++
+
+		c.NewLine().Yellow(s.Name)
+		if len(s.Kind) > 0 {
+			c.Grayf(" %s", s.Kind)
+		}
+		if s.LineBegin > 0 {
+			c.Defaultf(" %s:%d", s.Path, s.LineBegin)
+		} else {
+			c.Defaultf(" %s", s.Path)
+		}
+		if definition := s.Definition(); len(definition) > 0 && x.Args.Verbose {
+			c.NewLine().Gray("    " + strings.ReplaceAll(definition, "\n", "\n    "))
+		}
+	}
+
+	c.NewLine().NewLine().Defaultf("%d symbols found", found).NewLine()
+}
+
+func (me SymbolsCommand) dump(x Kontext, c comm.Console, index SymbolIndex) {
+	for _, entry := range index.Entries() {
+		if me.isTarget(x, entry.Path) {
+			c.Println(comm.ToJsonP(entry, false))
+		}
+	}
+}
@@ -1,9 +1,10 @@
 package batchai
 
 import (
+	"fmt"
+	"io"
 	"path"
 	"strings"
-	"sync"
 
 	"github.com/qiangyt/batchai/comm"
 )
@@ -39,13 +40,13 @@ func NewTestAgent(reportManager TestReportManager,
 	}
 }
 
-func (me TestAgent) run(x Kontext, testArgs TestArgs, resultChan chan<- TestResult) {
-	c := comm.NewConsole(!x.Args.Concurrent)
+func (me TestAgent) run(x Kontext, testArgs TestArgs, resultChan chan<- TestResult, output func(text string)) {
+	c := comm.NewConsole(!x.Args.Concurrent).WithOutput(output)
 	me.relativeFile = me.file[len(x.Args.Repository)+1:]
 
-	c.Greenf("\n\n▹▹▹▹▹ processing: %s\n", me.relativeFile)
 	c.Begin()
 	defer c.End()
+	c.Greenf("\n\n▹▹▹▹▹ processing: %s\n", me.relativeFile)
 
 	defer func() {
 		if e := recover(); e != nil {
@@ -59,19 +60,15 @@ func (me TestAgent) run(x Kontext, testArgs TestArgs, resultChan chan<- TestResu
 	resultChan <- result
 }
 
-func (me TestAgent) Run(x Kontext, testArgs TestArgs, resultChan chan<- TestResult, wg *sync.WaitGroup) {
+func (me TestAgent) Run(x Kontext, testArgs TestArgs, resultChan chan<- TestResult, pool comm.WorkerPool) {
 	if !x.Args.Concurrent {
-		me.run(x, testArgs, resultChan)
+		me.run(x, testArgs, resultChan, nil)
 		return
 	}
 
-	wg.Add(1)
-
-	go func() {
-		defer wg.Done()
-
-		me.run(x, testArgs, resultChan)
-	}()
+	pool.Submit(func(output func(text string)) {
+		me.run(x, testArgs, resultChan, output)
+	})
 }
 
 func (me TestAgent) generateTest(x Kontext, testArgs TestArgs, c comm.Console) TestResult {
@@ -100,9 +97,17 @@ func (me TestAgent) generateTest(x Kontext, testArgs TestArgs, c comm.Console) T
 	}
 
 	newReport := me.generateTestCode(x, c, testArgs, newCode, existingTestCode)
-	newReport.Print(c)
 
-	comm.WriteFileTextP(x.Fs, path.Join(x.Args.Repository, newReport.TestFilePath), newReport.TestCode)
+	if merge := me.resolveExistingTestFile(x, c, testArgs.OnExisting, newReport, lastReport); merge != nil {
+		newReport.TestCode = merge(newReport.TestCode)
+
+		if testArgs.Runner == nil {
+			comm.WriteFileTextP(x.Fs, path.Join(x.Args.Repository, newReport.TestFilePath), newReport.TestCode)
+		} else {
+			me.runTest(x, c, testArgs.Runner, newReport, merge)
+		}
+	}
+	newReport.Print(c)
 
 	reportFile := me.reportManager.SaveReport(x, me.file, newReport)
 	c.NewLine().Blue("✔ report: ").Default(reportFile[len(x.Args.Repository)+1:])
@@ -110,6 +115,152 @@ func (me TestAgent) generateTest(x Kontext, testArgs TestArgs, c comm.Console) T
 	return &TestResultT{Report: newReport, Skipped: false}
 }
 
+// resolveExistingTestFile decides how to write the generated test code if the test file exists, per --on-existing.
+// It returns the function to merge the generated test code into the test file, or nil if the test file is to be skipped.
+// The test file written by last execution and not changed since then is simply overwritten.
+func (me TestAgent) resolveExistingTestFile(x Kontext, c comm.Console, onExisting string, report TestReport, lastReport TestReport) func(string) string {
+	overwrite := func(testCode string) string { return testCode }
+
+	testFile := path.Join(x.Args.Repository, report.TestFilePath)
+	if !comm.FileExistsP(x.Fs, testFile) {
+		return overwrite
+	}
+
+	existingCode := comm.ReadFileTextP(x.Fs, testFile)
+	if lastReport != nil && lastReport.TestFilePath == report.TestFilePath && lastReport.TestCode == existingCode {
+		return overwrite
+	}
+
+	report.OnExisting = onExisting
+	switch onExisting {
+	case ON_EXISTING_SKIP:
+		c.NewLine().Yellow("test file exists, skipped: ").Default(report.TestFilePath)
+		return nil
+	case ON_EXISTING_APPEND, ON_EXISTING_MERGE:
+		if strings.HasSuffix(testFile, ".go") {
+			replace := (onExisting == ON_EXISTING_MERGE)
+			if _, err := MergeGoTestCode(existingCode, report.TestCode, replace); err != nil {
+				c.NewLine().Yellow("failed to merge the test code, fallback to write a new test file: ").Defaultf("%v", err)
+				break
+			}
+			return func(testCode string) string {
+				merged, err := MergeGoTestCode(existingCode, testCode, replace)
+				if err != nil {
+					c.NewLine().Yellow("failed to merge the test code: ").Defaultf("%v", err)
+					return ""
+				}
+				return merged
+			}
+		}
+	}
+
+	// the safe fallback which never touches the existing test file
+	report.OnExisting = ON_EXISTING_NEW_FILE
+	testFilePath := report.TestFilePath
+	report.TestFilePath = SiblingTestFile(testFilePath)
+
+	siblingFile := path.Join(x.Args.Repository, report.TestFilePath)
+	if comm.FileExistsP(x.Fs, siblingFile) {
+		siblingCode := comm.ReadFileTextP(x.Fs, siblingFile)
+		if lastReport == nil || lastReport.TestFilePath != report.TestFilePath || lastReport.TestCode != siblingCode {
+			report.OnExisting = ON_EXISTING_SKIP
+			c.NewLine().Yellow("sibling test file exists and was changed, skipped: ").Default(report.TestFilePath)
+			return nil
+		}
+	}
+	c.NewLine().Yellow("test file exists, writes a new test file: ").Default(report.TestFilePath)
+
+	switch {
+	case strings.HasSuffix(testFile, ".java"):
+		class := strings.TrimSuffix(path.Base(testFilePath), ".java")
+		newClass := strings.TrimSuffix(path.Base(report.TestFilePath), ".java")
+		return func(testCode string) string { return RenameJavaClass(testCode, class, newClass) }
+	case strings.HasSuffix(testFile, ".go"):
+		// the sibling file is in same package, so the tests and helpers declared by the other files are dropped
+		otherCodes := []string{}
+		for _, f := range comm.ListSuffixedFilesP(x.Fs, path.Dir(testFile), ".go", true) {
+			if f != siblingFile {
+				otherCodes = append(otherCodes, comm.ReadFileTextP(x.Fs, f))
+			}
+		}
+		return func(testCode string) string {
+			deduplicated, err := DropDuplicatedGoTestCode(testCode, otherCodes...)
+			if err != nil {
+				c.NewLine().Yellow("failed to de-duplicate the test code: ").Defaultf("%v", err)
+				return testCode
+			}
+			return deduplicated
+		}
+	}
+	return overwrite
+}
+
+// runTest writes the test code and runs it. If the test fails, the output of test command is fed back
+// to the model to repair the test, for a bounded number of attempts, otherwise the test is quarantined.
+func (me TestAgent) runTest(x Kontext, c comm.Console, runner TestRunner, report TestReport, merge func(string) string) {
+	testFile := path.Join(x.Args.Repository, report.TestFilePath)
+
+	command := runner.Command(report)
+	if len(command) == 0 {
+		comm.WriteFileTextP(x.Fs, testFile, report.TestCode)
+		report.RunStatus = TEST_RUN_STATUS_NOT_RUN
+		c.NewLine().Yellow("no command to run the test")
+		return
+	}
+	report.RunCommand = command
+
+	ok, output := runner.Apply(x, testFile, report.TestCode, command)
+	if ok {
+		report.RunStatus = TEST_RUN_STATUS_PASSED
+		c.NewLine().Green("✔ test passed: ").Default(command)
+		return
+	}
+
+	for attempt := 1; attempt <= runner.MaxRepairAttempts; attempt++ {
+		c.NewLine().Yellowf("test failed, repair attempt %d/%d", attempt, runner.MaxRepairAttempts)
+		report.RepairAttempts = attempt
+
+		testCode, metrics := me.repairTest(x, c, command, output)
+		report.ModelUsageMetrics.IncreaseUsage(metrics)
+		if len(testCode) > 0 {
+			testCode = merge(testCode)
+		}
+		if len(testCode) == 0 {
+			c.NewLine().Yellow("no repaired test code answered")
+			break
+		}
+		report.TestCode = testCode
+
+		if ok, output = runner.Apply(x, testFile, testCode, command); ok {
+			report.RunStatus = TEST_RUN_STATUS_REPAIRED
+			c.NewLine().Green("✔ test repaired and passed: ").Default(command)
+			return
+		}
+	}
+
+	report.RunStatus = TEST_RUN_STATUS_QUARANTINED
+	report.RunOutput = output
+	report.QuarantinedFilePath = report.TestFilePath + TEST_QUARANTINE_SUFFIX
+	comm.WriteFileTextP(x.Fs, testFile+TEST_QUARANTINE_SUFFIX, report.TestCode)
+
+	c.NewLine().Red("test quarantined: ").Default(report.QuarantinedFilePath)
+	c.NewLine().Default(output)
+}
+
+func (me TestAgent) repairTest(x Kontext, c comm.Console, command string, output string) (string, ModelUsageMetrics) {
+	mem := me.memory
+	mem.AddUserMessage(fmt.Sprintf("The generated test failed to run by `%s`, with below output:\n```\n%s\n```\n"+
+		"Repair the test so that it passes, and output the complete repaired test file in a code block starting with %s and ending with %s.",
+		command, output, TEST_BEGIN, TEST_END))
+	if x.Args.Verbose {
+		c.NewLine().Gray("chat: ").Default("repair the test")
+	}
+
+	answer, metrics := me.modelService.Chat(x, c, x.Config.Test.ModelId, true, mem, func() io.Writer { return NewTestCodeWriter(c) })
+
+	return comm.NormalizeCode(ExtractTestCode(answer)), metrics
+}
+
 type TestCodeWriterT struct {
 	console    comm.Console
 	inTestCode bool
@@ -152,13 +303,21 @@ func (me TestAgent) generateTestCode(x Kontext, c comm.Console, testArgs TestArg
 		inputExistingTestCode = ""
 	}
 
-	sysPrompt := x.Config.Test.RenderPrompt(testArgs.Libraries, code, me.relativeFile, inputExistingTestCode)
+	uncoveredCode := ""
+	if testArgs.Coverage != nil {
+		if coverageFile := testArgs.Coverage.Find(me.relativeFile); coverageFile != nil {
+			uncoveredCode = coverageFile.FormatUncoveredCode(code)
+		}
+	}
+
+	sysPrompt := x.Config.Test.RenderPrompt(testArgs.LibrariesOf(me.file), code, me.relativeFile, inputExistingTestCode, uncoveredCode)
+	if x.Args.EnableSymbolReference {
+		sysPrompt = me.provideSymbols(x, c, x.Config.Test.ModelId, me.file, code, sysPrompt)
+	}
 	mem := me.memory
 	mem.AddSystemMessage(sysPrompt)
 
 	if x.Args.EnableSymbolReference {
-		// TODO: merge metrics
-		me.provideSymbols(x, c, me.file)
 		mem.AddUserMessage("generates tests, with provided symbols as references")
 	} else {
 		mem.AddUserMessage("generates tests")
@@ -168,7 +327,7 @@ func (me TestAgent) generateTestCode(x Kontext, c comm.Console, testArgs TestArg
 		c.NewLine().Gray("chat: ").Default("generates tests")
 	}
 
-	answer, metrics := me.modelService.Chat(x, c, x.Config.Test.ModelId, true, mem, NewTestCodeWriter(c))
+	answer, metrics := me.modelService.Chat(x, c, x.Config.Test.ModelId, true, mem, func() io.Writer { return NewTestCodeWriter(c) })
 	if verbose {
 		c.NewLine().Gray("answer: ").Default(mem.Format())
 	}
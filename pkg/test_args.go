@@ -1,26 +1,80 @@
 package batchai
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/urfave/cli/v2"
 )
 
 type TestArgsT struct {
+	// specified by --library, for all files
 	Libraries []string
+	// detected by the build manifests if --library is not specified, by the files
+	DetectedLibraries map[string][]string
+	// nil if the generated tests are not to be run
+	Runner TestRunner
+	// nil if no coverage profile
+	Coverage Coverage
+	// files whose coverage is at or above this percentage are skipped
+	MinCoverage float64
+	// how to write the generated tests if the test file exists
+	OnExisting string
 }
 
 type TestArgs = *TestArgsT
 
 func (me TestArgs) WithCliContext(x Kontext, cliContext *cli.Context) error {
 	me.Libraries = cliContext.StringSlice("library")
+	if cliContext.Bool("run") {
+		me.Runner = NewTestRunner(x.Config.Test.Runners, x.Config.Test.MaxRepairAttempts)
+	}
+
+	me.OnExisting = cliContext.String("on-existing")
+	switch me.OnExisting {
+	case ON_EXISTING_APPEND, ON_EXISTING_MERGE, ON_EXISTING_SKIP, ON_EXISTING_NEW_FILE:
+	default:
+		return fmt.Errorf("unsupported --on-existing: %s, must be either %s, %s, %s or %s",
+			me.OnExisting, ON_EXISTING_APPEND, ON_EXISTING_MERGE, ON_EXISTING_SKIP, ON_EXISTING_NEW_FILE)
+	}
+
+	me.MinCoverage = cliContext.Float64("min-coverage")
+	if me.MinCoverage < 0 || me.MinCoverage > 100 {
+		return fmt.Errorf("invalid --min-coverage: %v, must be between 0 and 100", me.MinCoverage)
+	}
+
+	coverageFile := cliContext.String("coverage")
+	if len(coverageFile) > 0 {
+		var err error
+		if me.Coverage, err = LoadCoverage(x.Fs, coverageFile, x.Args.Repository); err != nil {
+			return err
+		}
+	} else if me.MinCoverage > 0 {
+		return errors.New("--min-coverage requires --coverage")
+	}
 	return nil
 }
 
+// LibrariesOf returns the test libraries to use for the file
+func (me TestArgs) LibrariesOf(file string) []string {
+	if len(me.Libraries) > 0 {
+		return me.Libraries
+	}
+	return me.DetectedLibraries[file]
+}
+
 func TestUrfaveCommand(x Kontext) *cli.Command {
 	return &cli.Command{
 		Name:  "test",
 		Usage: "Generate unit test code",
 		Flags: []cli.Flag{
 			&cli.StringSliceFlag{Name: "library", Usage: "the test library to use, by default it will be detected automatically", DefaultText: "auto"},
+			&cli.BoolFlag{Name: "run", Usage: "run the generated tests, let the model repair the failed tests, and quarantine the tests still failed", Value: false},
+			&cli.StringFlag{Name: "on-existing", Usage: "how to write the generated tests if the test file exists: append|merge|skip|new-file. " +
+				"For Go, append adds the tests not existing yet and merge replaces the existing tests of same names, " +
+				"for other languages both of them write a new test file beside the existing one", Value: ON_EXISTING_APPEND},
+			&cli.StringFlag{Name: "coverage", Usage: "the coverage profile, either Go cover.out, JaCoCo XML or lcov, to generate tests for the uncovered lines, the less covered files first"},
+			&cli.Float64Flag{Name: "min-coverage", Usage: "skip the files whose line coverage percentage is at or above this, requires --coverage", Value: 0},
 		},
 		Action: TestFunc(x),
 	}
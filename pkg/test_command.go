@@ -1,7 +1,7 @@
 package batchai
 
 import (
-	"sync"
+	"sort"
 
 	"github.com/qiangyt/batchai/comm"
 )
@@ -22,17 +22,16 @@ func NewTestCommand(x Kontext) TestCommand {
 
 func (me TestCommand) launchTestAgents(x Kontext, testArgs TestArgs, targetFiles []string, metrics TestMetrics) {
 	// launch test agents and wait for them
-	wg := &sync.WaitGroup{}
 	resultChan := make(chan TestResult, len(targetFiles))
 
 	for _, f := range targetFiles {
 		metrics.Processed++
 
 		agent := NewTestAgent(me.reportManager, me.symbolManager, me.modelService, f)
-		agent.Run(x, testArgs, resultChan, wg)
+		agent.Run(x, testArgs, resultChan, me.workerPool)
 	}
 
-	wg.Wait()
+	me.workerPool.Wait()
 	close(resultChan)
 
 	for r := range resultChan {
@@ -47,6 +46,17 @@ func (me TestCommand) launchTestAgents(x Kontext, testArgs TestArgs, targetFiles
 			metrics.ModelUsageMetricsT.IncreaseUsage(report.ModelUsageMetrics)
 
 			metrics.TotalTestCases += report.AmountOfGeneratedTestCases
+
+			switch report.RunStatus {
+			case TEST_RUN_STATUS_PASSED:
+				metrics.Passed++
+			case TEST_RUN_STATUS_REPAIRED:
+				metrics.Repaired++
+			case TEST_RUN_STATUS_QUARANTINED:
+				metrics.Quarantined++
+			case TEST_RUN_STATUS_NOT_RUN:
+				metrics.NotRun++
+			}
 		}
 	}
 }
@@ -57,7 +67,21 @@ func (me TestCommand) Test(x Kontext, testArgs TestArgs) {
 
 	c.NewLine().Default("test command uses model ").Yellowf("'%s'\n\n", x.Config.Test.ModelId)
 
-	targetFiles, _, _, repoFiles := me.listCommand.CollectWorkingFiles(x, c)
+	var prioritize func([]string) []string
+	if testArgs.Coverage != nil {
+		prioritize = func(files []string) []string {
+			return me.prioritizeByCoverage(x, c, testArgs, files, metrics)
+		}
+	}
+
+	targetFiles, ignored, failed, repoFiles := me.listCommand.CollectPrioritizedWorkingFiles(x, c, prioritize)
+	metrics.WithWorkingFiles(targetFiles, ignored, failed)
+	if len(targetFiles) > 0 && len(testArgs.Libraries) == 0 {
+		detector := NewTestLibraryDetector(x.Fs, x.Args.Repository)
+		testArgs.DetectedLibraries = detector.DetectFiles(targetFiles)
+		detector.Print(c)
+		c.NewLine()
+	}
 	if len(targetFiles) > 0 {
 		if x.Args.EnableSymbolReference {
 			me.launchSymbolAgents(x, repoFiles)
@@ -65,6 +89,41 @@ func (me TestCommand) Test(x Kontext, testArgs TestArgs) {
 		me.launchTestAgents(x, testArgs, targetFiles, metrics)
 	}
 
-	// c.NewLine()
-	// metrics.Print(c)
+	c.NewLine()
+	metrics.Print(c)
+	WriteMetricsJson(x, c, metrics)
+}
+
+// prioritizeByCoverage sorts the files by the amount of uncovered lines, and skips the files covered enough.
+// The files not in the coverage profile are kept at the end.
+func (me TestCommand) prioritizeByCoverage(x Kontext, c comm.Console, testArgs TestArgs, files []string, metrics TestMetrics) []string {
+	uncoveredLines := map[string]int{}
+
+	r := []string{}
+	for _, f := range files {
+		relativeFile := f[len(x.Args.Repository)+1:]
+
+		coverageFile := testArgs.Coverage.Find(relativeFile)
+		if coverageFile == nil {
+			uncoveredLines[f] = -1
+			r = append(r, f)
+			continue
+		}
+
+		if testArgs.MinCoverage > 0 && coverageFile.Percent() >= testArgs.MinCoverage {
+			metrics.AboveMinCoverage++
+			if x.Args.Verbose {
+				c.NewLine().Grayf("%s: coverage %.1f%%, skipped", relativeFile, coverageFile.Percent())
+			}
+			continue
+		}
+
+		uncoveredLines[f] = coverageFile.UncoveredLines()
+		r = append(r, f)
+	}
+
+	sort.SliceStable(r, func(i, j int) bool {
+		return uncoveredLines[r[i]] > uncoveredLines[r[j]]
+	})
+	return r
 }
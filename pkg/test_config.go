@@ -7,6 +7,9 @@ type TestConfigT struct {
 	ModelId   string     `mapstructure:"model_id"`
 	Prompt    TestPrompt `mapstructure:"prompt"`
 	Includes  []string   `mapstructure:"includes"`
+	// command templates to run the generated tests, by the extension of test file
+	Runners           map[string]string `mapstructure:"runners"`
+	MaxRepairAttempts int               `mapstructure:"max_repair_attempts"`
 }
 
 type TestConfig = *TestConfigT
@@ -26,12 +29,13 @@ func (me TestConfig) Init(config AppConfig) {
 	}
 }
 
-func (me TestConfig) RenderPrompt(libraries []string, codeToTest string, codeFile string, existingTestCode string) string {
+func (me TestConfig) RenderPrompt(libraries []string, codeToTest string, codeFile string, existingTestCode string, uncoveredCode string) string {
 	vars := NewTestPromptVariables().
 		WithCodeToTest(codeToTest).
 		WithLang(me.AppConfig.Lang).
 		WithPath(codeFile).
 		WithLibraries(libraries).
-		WithExistingTestCode(existingTestCode)
+		WithExistingTestCode(existingTestCode).
+		WithUncoveredCode(uncoveredCode)
 	return me.Prompt.Generate(vars)
 }
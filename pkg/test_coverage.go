@@ -0,0 +1,301 @@
+package batchai
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/qiangyt/batchai/comm"
+	"github.com/spf13/afero"
+)
+
+const (
+	COVERAGE_FORMAT_GO     = "go"
+	COVERAGE_FORMAT_JACOCO = "jacoco"
+	COVERAGE_FORMAT_LCOV   = "lcov"
+)
+
+// LineRangeT is an inclusive range of line numbers
+type LineRangeT struct {
+	Begin int
+	End   int
+}
+
+type LineRange = *LineRangeT
+
+func (me LineRange) String() string {
+	if me.Begin == me.End {
+		return strconv.Itoa(me.Begin)
+	}
+	return fmt.Sprintf("%d-%d", me.Begin, me.End)
+}
+
+// CoverageFileT is the line coverage of a file
+type CoverageFileT struct {
+	Path string
+	// covered or not, by the line numbers of instrumented lines
+	Lines map[int]bool
+}
+
+type CoverageFile = *CoverageFileT
+
+func (me CoverageFile) mark(lineNo int, covered bool) {
+	if lineNo < 1 {
+		// e.g. `DA:0,1` of lcov, no such line
+		return
+	}
+	me.Lines[lineNo] = me.Lines[lineNo] || covered
+}
+
+func (me CoverageFile) sortedLineNumbers() []int {
+	r := make([]int, 0, len(me.Lines))
+	for lineNo := range me.Lines {
+		r = append(r, lineNo)
+	}
+	sort.Ints(r)
+	return r
+}
+
+func (me CoverageFile) UncoveredLines() int {
+	r := 0
+	for _, covered := range me.Lines {
+		if !covered {
+			r++
+		}
+	}
+	return r
+}
+
+// Percent returns the percentage of covered lines, 100 if no instrumented lines
+func (me CoverageFile) Percent() float64 {
+	if len(me.Lines) == 0 {
+		return 100
+	}
+	return float64(len(me.Lines)-me.UncoveredLines()) * 100 / float64(len(me.Lines))
+}
+
+// UncoveredRanges merges the uncovered lines into ranges, which are split only by the covered lines
+func (me CoverageFile) UncoveredRanges() []LineRange {
+	r := []LineRange{}
+
+	var current LineRange
+	for _, lineNo := range me.sortedLineNumbers() {
+		if me.Lines[lineNo] {
+			current = nil
+			continue
+		}
+		if current == nil {
+			current = &LineRangeT{Begin: lineNo, End: lineNo}
+			r = append(r, current)
+		} else {
+			current.End = lineNo
+		}
+	}
+	return r
+}
+
+// FormatUncoveredCode lists the uncovered ranges along with the code of them
+func (me CoverageFile) FormatUncoveredCode(code string) string {
+	lines := strings.Split(code, "\n")
+
+	var r strings.Builder
+	for _, rg := range me.UncoveredRanges() {
+		if rg.Begin > len(lines) {
+			// the profile is out of date
+			break
+		}
+		begin := max(rg.Begin, 1)
+		end := min(rg.End, len(lines))
+		if begin > end {
+			continue
+		}
+
+		title := "lines"
+		if rg.Begin == rg.End {
+			title = "line"
+		}
+		fmt.Fprintf(&r, "%s %s:\n```\n%s\n```\n", title, rg, strings.Join(lines[begin-1:end], "\n"))
+	}
+	return r.String()
+}
+
+// CoverageT is a coverage profile, by the paths relative to the repository if possible
+type CoverageT struct {
+	Format string
+	Files  map[string]CoverageFile
+}
+
+type Coverage = *CoverageT
+
+func newCoverage(format string) Coverage {
+	return &CoverageT{Format: format, Files: map[string]CoverageFile{}}
+}
+
+func (me Coverage) file(p string) CoverageFile {
+	r := me.Files[p]
+	if r == nil {
+		r = &CoverageFileT{Path: p, Lines: map[int]bool{}}
+		me.Files[p] = r
+	}
+	return r
+}
+
+// Find finds the coverage of the file relative to the repository, nil if the file is not in the profile.
+// For the partial paths in profile, e.g. the package path of JaCoCo, the longest matched suffix wins.
+func (me Coverage) Find(relativeFile string) CoverageFile {
+	relativeFile = filepath.ToSlash(relativeFile)
+	if r := me.Files[relativeFile]; r != nil {
+		return r
+	}
+
+	var r CoverageFile
+	for p, f := range me.Files {
+		if strings.HasSuffix(relativeFile, "/"+p) && (r == nil || len(p) > len(r.Path)) {
+			r = f
+		}
+	}
+	return r
+}
+
+// LoadCoverage loads the coverage profile, the format is detected by the content.
+// The paths in profile are converted to be relative to the repository.
+func LoadCoverage(fs afero.Fs, file string, repository string) (Coverage, error) {
+	content, err := comm.ReadFileText(fs, file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read coverage profile: %s", file)
+	}
+
+	trimmed := strings.TrimSpace(content)
+	switch {
+	case strings.HasPrefix(trimmed, "mode:"):
+		return ParseGoCoverage(content, goModulePath(fs, repository))
+	case strings.HasPrefix(trimmed, "<"):
+		return ParseJacocoCoverage(content)
+	case strings.HasPrefix(trimmed, "TN:") || strings.HasPrefix(trimmed, "SF:"):
+		return ParseLcovCoverage(content, repository)
+	}
+	return nil, errors.Errorf("unknown format of coverage profile: %s", file)
+}
+
+func goModulePath(fs afero.Fs, repository string) string {
+	goModFile := filepath.Join(repository, "go.mod")
+	if !comm.FileExistsP(fs, goModFile) {
+		return ""
+	}
+	for _, line := range strings.Split(comm.ReadFileTextP(fs, goModFile), "\n") {
+		if modulePath, found := strings.CutPrefix(strings.TrimSpace(line), "module "); found {
+			return strings.Trim(strings.TrimSpace(modulePath), `"`)
+		}
+	}
+	return ""
+}
+
+// ParseGoCoverage parses the profile generated by `go test -coverprofile`, the lines are in format of
+// `name.go:line.column,line.column numberOfStatements count`
+func ParseGoCoverage(content string, modulePath string) (Coverage, error) {
+	r := newCoverage(COVERAGE_FORMAT_GO)
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+
+		colon := strings.LastIndex(line, ":")
+		if colon < 0 {
+			return nil, errors.Errorf("invalid go coverage line: %s", line)
+		}
+		fields := strings.Fields(line[colon+1:])
+		if len(fields) != 3 {
+			return nil, errors.Errorf("invalid go coverage line: %s", line)
+		}
+
+		p := line[:colon]
+		if len(modulePath) > 0 {
+			p = strings.TrimPrefix(p, modulePath+"/")
+		}
+
+		beginEnd := strings.Split(fields[0], ",")
+		begin, _ := strconv.Atoi(strings.Split(beginEnd[0], ".")[0])
+		end, _ := strconv.Atoi(strings.Split(beginEnd[len(beginEnd)-1], ".")[0])
+		count, _ := strconv.Atoi(fields[2])
+
+		f := r.file(p)
+		for lineNo := begin; lineNo <= end; lineNo++ {
+			f.mark(lineNo, count > 0)
+		}
+	}
+	return r, nil
+}
+
+type jacocoReportT struct {
+	Packages []struct {
+		Name        string `xml:"name,attr"`
+		SourceFiles []struct {
+			Name  string `xml:"name,attr"`
+			Lines []struct {
+				Nr int `xml:"nr,attr"`
+				// covered instructions
+				Ci int `xml:"ci,attr"`
+			} `xml:"line"`
+		} `xml:"sourcefile"`
+	} `xml:"package"`
+}
+
+// ParseJacocoCoverage parses the JaCoCo XML report, the paths are the package paths, e.g. `org/foo/Bar.java`
+func ParseJacocoCoverage(content string) (Coverage, error) {
+	report := &jacocoReportT{}
+
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	// the DOCTYPE of JaCoCo report refers to an external DTD, which is not needed
+	decoder.Strict = false
+	if err := decoder.Decode(report); err != nil {
+		return nil, errors.Wrap(err, "failed to parse JaCoCo report")
+	}
+
+	r := newCoverage(COVERAGE_FORMAT_JACOCO)
+	for _, pkg := range report.Packages {
+		for _, sourceFile := range pkg.SourceFiles {
+			f := r.file(path.Join(pkg.Name, sourceFile.Name))
+			for _, line := range sourceFile.Lines {
+				f.mark(line.Nr, line.Ci > 0)
+			}
+		}
+	}
+	return r, nil
+}
+
+// ParseLcovCoverage parses the lcov tracefile, the records are in format of `SF:<path>`, `DA:<line>,<count>` and `end_of_record`
+func ParseLcovCoverage(content string, repository string) (Coverage, error) {
+	r := newCoverage(COVERAGE_FORMAT_LCOV)
+
+	var f CoverageFile
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+
+		if p, found := strings.CutPrefix(line, "SF:"); found {
+			if filepath.IsAbs(p) {
+				if rel, err := filepath.Rel(repository, p); err == nil && !strings.HasPrefix(rel, "..") {
+					p = rel
+				}
+			}
+			f = r.file(filepath.ToSlash(p))
+		} else if da, found := strings.CutPrefix(line, "DA:"); found && f != nil {
+			fields := strings.Split(da, ",")
+			lineNo, _ := strconv.Atoi(fields[0])
+			count := 0
+			if len(fields) > 1 {
+				count, _ = strconv.Atoi(fields[1])
+			}
+			f.mark(lineNo, count > 0)
+		} else if line == "end_of_record" {
+			f = nil
+		}
+	}
+	return r, nil
+}
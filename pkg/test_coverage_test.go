@@ -0,0 +1,167 @@
+package batchai
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+const testGoCoverage = `mode: set
+example.com/demo/calc/add.go:3.24,5.2 1 1
+example.com/demo/calc/add.go:7.24,9.16 2 0
+example.com/demo/calc/add.go:9.16,11.3 1 0
+example.com/demo/calc/add.go:12.2,12.10 1 1
+example.com/demo/calc/add.go:14.20,16.2 1 0
+example.com/demo/main.go:3.13,5.2 1 1
+`
+
+const testJacocoCoverage = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<!DOCTYPE report PUBLIC "-//JACOCO//DTD Report 1.1//EN" "report.dtd">
+<report name="demo">
+  <package name="org/demo">
+    <class name="org/demo/Calc" sourcefilename="Calc.java"/>
+    <sourcefile name="Calc.java">
+      <line nr="3" mi="0" ci="3" mb="0" cb="0"/>
+      <line nr="5" mi="2" ci="0" mb="0" cb="0"/>
+      <line nr="6" mi="4" ci="0" mb="0" cb="0"/>
+      <line nr="8" mi="0" ci="1" mb="0" cb="0"/>
+    </sourcefile>
+  </package>
+</report>
+`
+
+const testLcovCoverage = `TN:
+SF:/repo/src/calc.ts
+DA:1,1
+DA:2,0
+DA:3,0
+end_of_record
+SF:src/util.ts
+DA:1,5
+end_of_record
+`
+
+func TestParseGoCoverage(t *testing.T) {
+	a := require.New(t)
+
+	c, err := ParseGoCoverage(testGoCoverage, "example.com/demo")
+	a.NoError(err)
+	a.Equal(COVERAGE_FORMAT_GO, c.Format)
+	a.Len(c.Files, 2)
+
+	add := c.Find("calc/add.go")
+	a.NotNil(add)
+	a.Equal(8, add.UncoveredLines())
+	a.Equal("7-11, 14-16", formatTestRanges(add.UncoveredRanges()))
+	a.InDelta(33.3, add.Percent(), 0.1)
+
+	a.Equal(100.0, c.Find("main.go").Percent())
+	a.Nil(c.Find("calc/sub.go"))
+
+	_, err = ParseGoCoverage("mode: set\nnot a profile\n", "")
+	a.Error(err)
+}
+
+func TestParseJacocoCoverage(t *testing.T) {
+	a := require.New(t)
+
+	c, err := ParseJacocoCoverage(testJacocoCoverage)
+	a.NoError(err)
+	a.Equal(COVERAGE_FORMAT_JACOCO, c.Format)
+
+	calc := c.Find("src/main/java/org/demo/Calc.java")
+	a.NotNil(calc)
+	a.Equal("5-6", formatTestRanges(calc.UncoveredRanges()))
+	a.Equal(50.0, calc.Percent())
+
+	a.Nil(c.Find("src/main/java/org/other/Calc.java"))
+
+	_, err = ParseJacocoCoverage("<report")
+	a.Error(err)
+}
+
+func TestParseLcovCoverage(t *testing.T) {
+	a := require.New(t)
+
+	c, err := ParseLcovCoverage(testLcovCoverage, "/repo")
+	a.NoError(err)
+	a.Equal(COVERAGE_FORMAT_LCOV, c.Format)
+
+	calc := c.Find("src/calc.ts")
+	a.NotNil(calc)
+	a.Equal("2-3", formatTestRanges(calc.UncoveredRanges()))
+	a.Equal("lines 2-3:\n```\nb\nc\n```\n", calc.FormatUncoveredCode("a\nb\nc\n"))
+
+	a.Equal(100.0, c.Find("src/util.ts").Percent())
+
+	// the line 0 of a stale or broken profile is ignored
+	c, err = ParseLcovCoverage("SF:src/calc.ts\nDA:0,0\nDA:1,0\nend_of_record\n", "/repo")
+	a.NoError(err)
+	calc = c.Find("src/calc.ts")
+	a.Equal("1", formatTestRanges(calc.UncoveredRanges()))
+	a.Equal("line 1:\n```\na\n```\n", calc.FormatUncoveredCode("a\n"))
+
+	calc = &CoverageFileT{Lines: map[int]bool{0: false, 1: false}}
+	a.Equal("lines 0-1:\n```\na\n```\n", calc.FormatUncoveredCode("a\n"))
+}
+
+func TestLoadCoverage(t *testing.T) {
+	a := require.New(t)
+
+	fs := afero.NewMemMapFs()
+	comm.WriteFileTextP(fs, "/repo/go.mod", "module example.com/demo\n\ngo 1.21\n")
+	comm.WriteFileTextP(fs, "/repo/cover.out", testGoCoverage)
+	comm.WriteFileTextP(fs, "/repo/jacoco.xml", testJacocoCoverage)
+	comm.WriteFileTextP(fs, "/repo/lcov.info", testLcovCoverage)
+
+	c, err := LoadCoverage(fs, "/repo/cover.out", "/repo")
+	a.NoError(err)
+	a.NotNil(c.Find(filepath.Join("calc", "add.go")))
+
+	c, err = LoadCoverage(fs, "/repo/jacoco.xml", "/repo")
+	a.NoError(err)
+	a.Equal(COVERAGE_FORMAT_JACOCO, c.Format)
+
+	c, err = LoadCoverage(fs, "/repo/lcov.info", "/repo")
+	a.NoError(err)
+	a.Equal(COVERAGE_FORMAT_LCOV, c.Format)
+
+	comm.WriteFileTextP(fs, "/repo/unknown.txt", "hello")
+	_, err = LoadCoverage(fs, "/repo/unknown.txt", "/repo")
+	a.Error(err)
+
+	_, err = LoadCoverage(fs, "/repo/missing.out", "/repo")
+	a.Error(err)
+}
+
+func TestPrioritizeByCoverage(t *testing.T) {
+	a := require.New(t)
+
+	x := NewKontext(afero.NewMemMapFs())
+	x.Args = &AppArgsT{Repository: "/repo"}
+
+	coverage, err := ParseGoCoverage(testGoCoverage, "example.com/demo")
+	a.NoError(err)
+	testArgs := &TestArgsT{Coverage: coverage, MinCoverage: 80}
+	metrics := NewTestMetrics()
+
+	files := (&TestCommandT{}).prioritizeByCoverage(x, comm.NewConsole(false), testArgs,
+		[]string{"/repo/main.go", "/repo/calc/sub.go", "/repo/calc/add.go"}, metrics)
+	a.Equal([]string{"/repo/calc/add.go", "/repo/calc/sub.go"}, files)
+	a.Equal(1, metrics.AboveMinCoverage)
+}
+
+func formatTestRanges(ranges []LineRange) string {
+	r := ""
+	for i, rg := range ranges {
+		if i > 0 {
+			r += ", "
+		}
+		r += rg.String()
+	}
+	return r
+}
@@ -0,0 +1,251 @@
+package batchai
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/qiangyt/batchai/comm"
+	"github.com/spf13/afero"
+)
+
+type testLibraryT struct {
+	name string
+	// matches any of the dependency names in the build manifest
+	matchers []*regexp.Regexp
+}
+
+type testLanguageT struct {
+	name       string
+	extensions []string
+	// build manifests, by the priority
+	manifests []string
+	// always available, e.g. the standard library
+	builtins []string
+	// used if no library is detected
+	defaults  []string
+	libraries []*testLibraryT
+}
+
+func testLibrary(name string, dependencies ...string) *testLibraryT {
+	r := &testLibraryT{name: name}
+
+	for _, dependency := range dependencies {
+		// the dependency name must not be a part of other names, e.g. `jest` in `ts-jest`
+		pattern := regexp.QuoteMeta(dependency)
+		if isTestDependencyNameChar(dependency[0]) {
+			pattern = `(^|[^A-Za-z0-9_.-])` + pattern
+		}
+		if isTestDependencyNameChar(dependency[len(dependency)-1]) {
+			pattern = pattern + `($|[^A-Za-z0-9_-])`
+		}
+		r.matchers = append(r.matchers, regexp.MustCompile(pattern))
+	}
+	return r
+}
+
+func isTestDependencyNameChar(ch byte) bool {
+	return ch == '_' || ch == '-' || ch == '.' || ('0' <= ch && ch <= '9') || ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z')
+}
+
+var testLanguages = []*testLanguageT{
+	{
+		name:       "Go",
+		extensions: []string{".go"},
+		manifests:  []string{"go.mod"},
+		builtins:   []string{"testing"},
+		libraries: []*testLibraryT{
+			testLibrary("testify", "github.com/stretchr/testify"),
+			testLibrary("gomock", "go.uber.org/mock", "github.com/golang/mock"),
+			testLibrary("ginkgo", "github.com/onsi/ginkgo", "github.com/onsi/ginkgo/v2"),
+			testLibrary("gomega", "github.com/onsi/gomega"),
+			testLibrary("go-sqlmock", "github.com/DATA-DOG/go-sqlmock"),
+			testLibrary("httpmock", "github.com/jarcoal/httpmock"),
+		},
+	},
+	{
+		name:       "JVM",
+		extensions: []string{".java", ".kt", ".scala", ".groovy"},
+		manifests:  []string{"pom.xml", "build.gradle", "build.gradle.kts"},
+		libraries: []*testLibraryT{
+			testLibrary("JUnit 5", "junit-jupiter", "junit-jupiter-api", "org.junit.jupiter"),
+			testLibrary("JUnit 4", "junit:junit", "<artifactId>junit</artifactId>"),
+			testLibrary("TestNG", "testng"),
+			testLibrary("Spring Boot Test", "spring-boot-starter-test"),
+			testLibrary("Mockito", "mockito-core", "mockito-junit-jupiter", "mockito-kotlin", "mockito-inline"),
+			testLibrary("AssertJ", "assertj-core"),
+			testLibrary("Hamcrest", "hamcrest"),
+			testLibrary("Kotest", "kotest-runner-junit5", "kotest-assertions-core"),
+			testLibrary("MockK", "mockk"),
+			testLibrary("ScalaTest", "scalatest"),
+			testLibrary("Spock", "spock-core"),
+		},
+	},
+	{
+		name:       "JavaScript",
+		extensions: []string{".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx"},
+		manifests:  []string{"package.json"},
+		libraries: []*testLibraryT{
+			testLibrary("Jest", `"jest"`),
+			testLibrary("Vitest", `"vitest"`),
+			testLibrary("Mocha", `"mocha"`),
+			testLibrary("Chai", `"chai"`),
+			testLibrary("Jasmine", `"jasmine"`, `"jasmine-core"`),
+			testLibrary("Sinon", `"sinon"`),
+			testLibrary("Testing Library", `"@testing-library/`),
+			testLibrary("AVA", `"ava"`),
+			testLibrary("Playwright", `"@playwright/test"`),
+			testLibrary("Cypress", `"cypress"`),
+		},
+	},
+	{
+		name:       "Python",
+		extensions: []string{".py"},
+		manifests:  []string{"pyproject.toml", "requirements.txt", "requirements-dev.txt", "requirements-test.txt"},
+		defaults:   []string{"unittest"},
+		libraries: []*testLibraryT{
+			testLibrary("pytest", "pytest"),
+			testLibrary("pytest-mock", "pytest-mock"),
+			testLibrary("pytest-asyncio", "pytest-asyncio"),
+			testLibrary("hypothesis", "hypothesis"),
+			testLibrary("nose2", "nose2"),
+		},
+	},
+	{
+		name:       "Rust",
+		extensions: []string{".rs"},
+		manifests:  []string{"Cargo.toml"},
+		builtins:   []string{"built-in #[test]"},
+		libraries: []*testLibraryT{
+			testLibrary("rstest", "rstest"),
+			testLibrary("proptest", "proptest"),
+			testLibrary("quickcheck", "quickcheck"),
+			testLibrary("mockall", "mockall"),
+			testLibrary("insta", "insta"),
+			testLibrary("pretty_assertions", "pretty_assertions"),
+		},
+	},
+}
+
+func findTestLanguage(file string) *testLanguageT {
+	ext := strings.ToLower(filepath.Ext(file))
+	for _, lang := range testLanguages {
+		for _, e := range lang.extensions {
+			if e == ext {
+				return lang
+			}
+		}
+	}
+	return nil
+}
+
+// TestLibraryDetectionT is the test libraries detected by a build manifest
+type TestLibraryDetectionT struct {
+	Language string
+	// relative to the repository
+	Manifest  string
+	Libraries []string
+}
+
+type TestLibraryDetection = *TestLibraryDetectionT
+
+// TestLibraryDetectorT detects the test libraries by the build manifest of the module which the file belongs to
+type TestLibraryDetectorT struct {
+	fs         afero.Fs
+	repository string
+	// by the manifest files
+	detections map[string]TestLibraryDetection
+}
+
+type TestLibraryDetector = *TestLibraryDetectorT
+
+func NewTestLibraryDetector(fs afero.Fs, repository string) TestLibraryDetector {
+	return &TestLibraryDetectorT{
+		fs:         fs,
+		repository: repository,
+		detections: map[string]TestLibraryDetection{},
+	}
+}
+
+// Detect finds the nearest build manifest of the language of file, from the directory of file up to the repository.
+// Returns nil if the language is unknown or no manifest found.
+func (me TestLibraryDetector) Detect(file string) TestLibraryDetection {
+	lang := findTestLanguage(file)
+	if lang == nil {
+		return nil
+	}
+
+	for dir := filepath.Dir(file); ; dir = filepath.Dir(dir) {
+		for _, manifest := range lang.manifests {
+			manifestFile := filepath.Join(dir, manifest)
+			if comm.FileExistsP(me.fs, manifestFile) {
+				return me.detectByManifest(lang, manifestFile)
+			}
+		}
+
+		if len(dir) <= len(me.repository) || dir == filepath.Dir(dir) {
+			return nil
+		}
+	}
+}
+
+func (me TestLibraryDetector) detectByManifest(lang *testLanguageT, manifestFile string) TestLibraryDetection {
+	if r, cached := me.detections[manifestFile]; cached {
+		return r
+	}
+
+	content := comm.ReadFileTextP(me.fs, manifestFile)
+
+	libraries := append([]string{}, lang.builtins...)
+	for _, lib := range lang.libraries {
+		for _, matcher := range lib.matchers {
+			if matcher.MatchString(content) {
+				libraries = append(libraries, lib.name)
+				break
+			}
+		}
+	}
+	if len(libraries) == 0 {
+		libraries = append(libraries, lang.defaults...)
+	}
+
+	relativeManifest, _ := filepath.Rel(me.repository, manifestFile)
+
+	r := &TestLibraryDetectionT{Language: lang.name, Manifest: filepath.ToSlash(relativeManifest), Libraries: libraries}
+	me.detections[manifestFile] = r
+	return r
+}
+
+// DetectFiles detects the test libraries of the files, and returns the libraries by the files
+func (me TestLibraryDetector) DetectFiles(files []string) map[string][]string {
+	r := map[string][]string{}
+	for _, f := range files {
+		if detection := me.Detect(f); detection != nil {
+			r[f] = detection.Libraries
+		}
+	}
+	return r
+}
+
+// Print prints the detection results by the manifests
+func (me TestLibraryDetector) Print(c comm.Console) {
+	if len(me.detections) == 0 {
+		c.NewLine().Default("no test library detected, the model will choose by itself")
+		return
+	}
+
+	detections := make([]TestLibraryDetection, 0, len(me.detections))
+	for _, detection := range me.detections {
+		detections = append(detections, detection)
+	}
+	sort.Slice(detections, func(i, j int) bool { return detections[i].Manifest < detections[j].Manifest })
+
+	for _, detection := range detections {
+		libraries := strings.Join(detection.Libraries, ", ")
+		if len(libraries) == 0 {
+			libraries = "none"
+		}
+		c.NewLine().Default("test libraries detected by ").Yellow(detection.Manifest).Defaultf(": %s", libraries)
+	}
+}
@@ -0,0 +1,58 @@
+package batchai
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+func TestTestLibraryDetector(t *testing.T) {
+	a := require.New(t)
+
+	fs := afero.NewMemMapFs()
+	comm.WriteFileTextP(fs, "/repo/go.mod", "module example.com/demo\n\nrequire (\n\tgithub.com/stretchr/testify v1.9.0\n\tgo.uber.org/mock v0.4.0\n)\n")
+	comm.WriteFileTextP(fs, "/repo/main.go", "package main\n")
+	comm.WriteFileTextP(fs, "/repo/web/package.json", `{"devDependencies": {"ts-jest": "^29.0.0", "vitest": "^1.0.0", "@testing-library/react": "^14.0.0"}}`)
+	comm.WriteFileTextP(fs, "/repo/web/src/app.ts", "export {}\n")
+	comm.WriteFileTextP(fs, "/repo/java/pom.xml", "<dependency><groupId>org.junit.jupiter</groupId><artifactId>junit-jupiter</artifactId></dependency>\n"+
+		"<dependency><artifactId>mockito-core</artifactId></dependency>\n")
+	comm.WriteFileTextP(fs, "/repo/java/src/main/java/Foo.java", "class Foo {}\n")
+	comm.WriteFileTextP(fs, "/repo/py/requirements.txt", "requests==2.0\n")
+	comm.WriteFileTextP(fs, "/repo/py/app.py", "\n")
+	comm.WriteFileTextP(fs, "/repo/lib.rs", "\n")
+
+	detector := NewTestLibraryDetector(fs, "/repo")
+
+	d := detector.Detect("/repo/main.go")
+	a.Equal("Go", d.Language)
+	a.Equal("go.mod", d.Manifest)
+	a.Equal([]string{"testing", "testify", "gomock"}, d.Libraries)
+
+	d = detector.Detect("/repo/web/src/app.ts")
+	a.Equal("web/package.json", d.Manifest)
+	a.Equal([]string{"Vitest", "Testing Library"}, d.Libraries)
+
+	a.Equal([]string{"JUnit 5", "Mockito"}, detector.Detect("/repo/java/src/main/java/Foo.java").Libraries)
+	a.Equal([]string{"unittest"}, detector.Detect("/repo/py/app.py").Libraries)
+
+	// no Cargo.toml
+	a.Nil(detector.Detect("/repo/lib.rs"))
+	a.Nil(detector.Detect("/repo/README.md"))
+
+	libraries := detector.DetectFiles([]string{"/repo/main.go", "/repo/lib.rs"})
+	a.Equal(map[string][]string{"/repo/main.go": {"testing", "testify", "gomock"}}, libraries)
+}
+
+func TestTestArgsLibrariesOf(t *testing.T) {
+	a := require.New(t)
+
+	args := &TestArgsT{DetectedLibraries: map[string][]string{"/repo/main.go": {"testing"}}}
+	a.Equal([]string{"testing"}, args.LibrariesOf("/repo/main.go"))
+	a.Empty(args.LibrariesOf("/repo/other.go"))
+
+	args.Libraries = []string{"testify"}
+	a.Equal([]string{"testify"}, args.LibrariesOf("/repo/main.go"))
+}
@@ -0,0 +1,452 @@
+package batchai
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// keeps the existing tests, and adds the generated tests not existing yet
+	ON_EXISTING_APPEND = "append"
+	// adds the generated tests, and replaces the existing tests of same names with the generated ones
+	ON_EXISTING_MERGE = "merge"
+	// leaves the existing test file untouched
+	ON_EXISTING_SKIP = "skip"
+	// writes the generated tests into a sibling file
+	ON_EXISTING_NEW_FILE = "new-file"
+)
+
+// the sibling test file is named with this mark, e.g. `foo_batchai_test.go`, `foo_batchai.test.ts`
+const TEST_SIBLING_FILE_MARK = "_batchai"
+
+// the sibling test class of Java is named with this mark, e.g. `FooBatchaiTest`
+const TEST_SIBLING_CLASS_MARK = "Batchai"
+
+// the suffixes of Java test class names, as the default includes of surefire
+var javaTestClassSuffixes = []string{"TestCase", "Tests", "Test"}
+
+// SiblingTestFile returns the test file beside the given one, named to match the default patterns of test tools,
+// e.g. `*_test.go` of go test, `*Test.java` of surefire, `test_*.py` of pytest and `*.test.ts` of jest
+func SiblingTestFile(testFile string) string {
+	if strings.HasSuffix(testFile, "_test.go") {
+		return strings.TrimSuffix(testFile, "_test.go") + TEST_SIBLING_FILE_MARK + "_test.go"
+	}
+
+	dir, base := path.Split(testFile)
+	if strings.HasSuffix(base, ".java") {
+		// the public class must be named as the file, so the class is renamed by RenameJavaClass as well
+		return dir + SiblingJavaTestClass(strings.TrimSuffix(base, ".java")) + ".java"
+	}
+
+	if dot := strings.Index(base, "."); dot > 0 {
+		return dir + base[:dot] + TEST_SIBLING_FILE_MARK + base[dot:]
+	}
+	return testFile + TEST_SIBLING_FILE_MARK
+}
+
+// SiblingJavaTestClass returns the name of sibling test class, e.g. `FooBatchaiTest` of `FooTest`, `TestFooBatchai` of `TestFoo`
+func SiblingJavaTestClass(class string) string {
+	for _, suffix := range javaTestClassSuffixes {
+		if strings.HasSuffix(class, suffix) && len(class) > len(suffix) {
+			return strings.TrimSuffix(class, suffix) + TEST_SIBLING_CLASS_MARK + suffix
+		}
+	}
+	if strings.HasPrefix(class, "Test") {
+		return class + TEST_SIBLING_CLASS_MARK
+	}
+	return class + TEST_SIBLING_CLASS_MARK + "Test"
+}
+
+// RenameJavaClass renames the class, including its constructors and the references to itself
+func RenameJavaClass(code string, class string, newClass string) string {
+	return regexp.MustCompile(`\b`+regexp.QuoteMeta(class)+`\b`).ReplaceAllString(code, newClass)
+}
+
+type goMergeDeclT struct {
+	decl  ast.Decl
+	names []string
+	// the text of declaration, including the doc comment
+	begin int
+	end   int
+}
+
+type goMergeFileT struct {
+	source  string
+	fset    *token.FileSet
+	file    *ast.File
+	decls   []*goMergeDeclT
+	byNames map[string]*goMergeDeclT
+}
+
+func parseGoMergeFile(name string, source string) (*goMergeFileT, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, source, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", name)
+	}
+
+	r := &goMergeFileT{source: source, fset: fset, file: file, byNames: map[string]*goMergeDeclT{}}
+	for _, decl := range file.Decls {
+		if genDecl, isGenDecl := decl.(*ast.GenDecl); isGenDecl && genDecl.Tok == token.IMPORT {
+			continue
+		}
+
+		d := &goMergeDeclT{decl: decl, names: goDeclNames(decl), end: r.offset(decl.End())}
+		d.begin = r.offset(decl.Pos())
+		if doc := goDeclDoc(decl); doc != nil {
+			d.begin = r.offset(doc.Pos())
+		}
+
+		r.decls = append(r.decls, d)
+		for _, name := range d.names {
+			r.byNames[name] = d
+		}
+	}
+	return r, nil
+}
+
+func (me *goMergeFileT) offset(pos token.Pos) int {
+	return me.fset.Position(pos).Offset
+}
+
+func (me *goMergeFileT) text(d *goMergeDeclT) string {
+	return me.source[d.begin:d.end]
+}
+
+// removeImport returns the edit to remove the import spec. `import "fmt"` is removed as a whole,
+// otherwise removes the line of import spec
+func (me *goMergeFileT) removeImport(genDecl *ast.GenDecl, spec ast.Spec) goTextEditT {
+	if !genDecl.Lparen.IsValid() {
+		return goTextEditT{begin: me.offset(genDecl.Pos()), end: me.offset(genDecl.End())}
+	}
+
+	begin, end := me.offset(spec.Pos()), me.offset(spec.End())
+	for begin > 0 && (me.source[begin-1] == ' ' || me.source[begin-1] == '\t') {
+		begin--
+	}
+	if end < len(me.source) && me.source[end] == '\n' {
+		end++
+	}
+	return goTextEditT{begin: begin, end: end}
+}
+
+// applyEdits applies the edits to the source, and formats the result
+func (me *goMergeFileT) applyEdits(edits []goTextEditT, appended []string) (string, error) {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].begin > edits[j].begin })
+	r := me.source
+	for _, edit := range edits {
+		r = r[:edit.begin] + edit.text + r[edit.end:]
+	}
+
+	if len(appended) > 0 {
+		r = strings.TrimRight(r, "\n") + "\n\n" + strings.Join(appended, "\n\n") + "\n"
+	}
+
+	formatted, err := format.Source([]byte(r))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to format the merged test code")
+	}
+	return string(formatted), nil
+}
+
+func goDeclDoc(decl ast.Decl) *ast.CommentGroup {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d.Doc
+	case *ast.GenDecl:
+		return d.Doc
+	}
+	return nil
+}
+
+// goDeclNames returns the declared names, a method is named as `Receiver.Method`
+func goDeclNames(decl ast.Decl) []string {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil || len(d.Recv.List) == 0 {
+			return []string{d.Name.Name}
+		}
+		return []string{goReceiverTypeName(d.Recv.List[0].Type) + "." + d.Name.Name}
+	case *ast.GenDecl:
+		r := []string{}
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				r = append(r, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, name := range s.Names {
+					if name.Name != "_" {
+						r = append(r, name.Name)
+					}
+				}
+			}
+		}
+		return r
+	}
+	return nil
+}
+
+func goReceiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return goReceiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return goReceiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return goReceiverTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+var goImportVersionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// goImportName guesses the name of imported package, by the explicit name or by the import path
+func goImportName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+
+	importPath, _ := strconv.Unquote(spec.Path.Value)
+	elements := strings.Split(importPath, "/")
+	r := elements[len(elements)-1]
+	if goImportVersionSuffix.MatchString(r) && len(elements) > 1 {
+		r = elements[len(elements)-2]
+	}
+	if dot := strings.Index(r, ".v"); dot > 0 {
+		r = r[:dot]
+	}
+	return r
+}
+
+func goImportKey(spec *ast.ImportSpec) string {
+	if spec.Name == nil {
+		return spec.Path.Value
+	}
+	return spec.Name.Name + " " + spec.Path.Value
+}
+
+// goReferencedPackages collects the identifiers used as the package of selector expressions, e.g. `require` in `require.New(t)`
+func goReferencedPackages(nodes ...ast.Node) map[string]bool {
+	r := map[string]bool{}
+	for _, node := range nodes {
+		ast.Inspect(node, func(n ast.Node) bool {
+			if sel, isSelector := n.(*ast.SelectorExpr); isSelector {
+				if ident, isIdent := sel.X.(*ast.Ident); isIdent {
+					r[ident.Name] = true
+				}
+			}
+			return true
+		})
+	}
+	return r
+}
+
+type goTextEditT struct {
+	begin int
+	end   int
+	text  string
+}
+
+// MergeGoTestCode merges the generated Go test code into the existing one, the declarations are de-duplicated by names.
+// If replace is true, the existing declarations of same names are replaced by the generated ones, otherwise they're kept.
+// The imports are merged as well.
+func MergeGoTestCode(existingCode string, generatedCode string, replace bool) (string, error) {
+	existing, err := parseGoMergeFile("existing test code", existingCode)
+	if err != nil {
+		return "", err
+	}
+	generated, err := parseGoMergeFile("generated test code", generatedCode)
+	if err != nil {
+		return "", err
+	}
+	if existing.file.Name.Name != generated.file.Name.Name {
+		return "", fmt.Errorf("package %s of generated test code is different from package %s of existing test code",
+			generated.file.Name.Name, existing.file.Name.Name)
+	}
+
+	edits := []goTextEditT{}
+	appended := []string{}
+	removedNodes := []ast.Node{}
+	addedNodes := []ast.Node{}
+
+	for _, d := range generated.decls {
+		var duplicated *goMergeDeclT
+		for _, name := range d.names {
+			if duplicated = existing.byNames[name]; duplicated != nil {
+				break
+			}
+		}
+
+		if duplicated == nil {
+			appended = append(appended, generated.text(d))
+			addedNodes = append(addedNodes, d.decl)
+			continue
+		}
+
+		// only the declarations of exactly same names can be replaced
+		if replace && strings.Join(duplicated.names, ",") == strings.Join(d.names, ",") {
+			edits = append(edits, goTextEditT{begin: duplicated.begin, end: duplicated.end, text: generated.text(d)})
+			removedNodes = append(removedNodes, duplicated.decl)
+			addedNodes = append(addedNodes, d.decl)
+		}
+	}
+
+	edits = append(edits, mergeGoImports(existing, generated, removedNodes, addedNodes)...)
+
+	return existing.applyEdits(edits, appended)
+}
+
+// DropDuplicatedGoTestCode removes the generated declarations of which the names are declared by the other files of
+// same package, so that the generated test code can be written into a sibling file without redeclaration.
+// The imports no longer used are removed as well. The other files not parsed are ignored.
+func DropDuplicatedGoTestCode(generatedCode string, otherCodes ...string) (string, error) {
+	generated, err := parseGoMergeFile("generated test code", generatedCode)
+	if err != nil {
+		return "", err
+	}
+
+	declared := map[string]bool{}
+	for i, otherCode := range otherCodes {
+		other, err := parseGoMergeFile(fmt.Sprintf("test code %d", i), otherCode)
+		if err != nil || other.file.Name.Name != generated.file.Name.Name {
+			continue
+		}
+		for name := range other.byNames {
+			declared[name] = true
+		}
+	}
+
+	edits := []goTextEditT{}
+	keptNodes := []ast.Node{}
+	for _, d := range generated.decls {
+		duplicated := false
+		for _, name := range d.names {
+			duplicated = duplicated || declared[name]
+		}
+		if duplicated {
+			edits = append(edits, goTextEditT{begin: d.begin, end: d.end})
+		} else {
+			keptNodes = append(keptNodes, d.decl)
+		}
+	}
+	if len(edits) == 0 {
+		return generatedCode, nil
+	}
+
+	keptReferences := goReferencedPackages(keptNodes...)
+	for _, decl := range generated.file.Decls {
+		genDecl, isGenDecl := decl.(*ast.GenDecl)
+		if !isGenDecl || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			name := goImportName(spec.(*ast.ImportSpec))
+			// the name of blank, dot imports can't be told, so keep them
+			if name != "_" && name != "." && !keptReferences[name] {
+				edits = append(edits, generated.removeImport(genDecl, spec))
+			}
+		}
+	}
+
+	return generated.applyEdits(edits, nil)
+}
+
+// mergeGoImports adds the generated imports used by the added declarations, and removes the existing imports
+// only used by the replaced declarations
+func mergeGoImports(existing *goMergeFileT, generated *goMergeFileT, removedNodes []ast.Node, addedNodes []ast.Node) []goTextEditT {
+	edits := []goTextEditT{}
+
+	existingKeys := map[string]bool{}
+	for _, spec := range existing.file.Imports {
+		existingKeys[goImportKey(spec)] = true
+	}
+
+	addedReferences := goReferencedPackages(addedNodes...)
+	generatedReferences := goReferencedPackages(generated.file)
+
+	newImports := []string{}
+	for _, spec := range generated.file.Imports {
+		if existingKeys[goImportKey(spec)] {
+			continue
+		}
+		name := goImportName(spec)
+		// the name of blank, dot imports or the package not named by its path can't be told, so keep them
+		if name != "_" && name != "." && generatedReferences[name] && !addedReferences[name] {
+			continue
+		}
+		newImports = append(newImports, generated.source[generated.offset(spec.Pos()):generated.offset(spec.End())])
+	}
+
+	if len(removedNodes) > 0 {
+		removedReferences := goReferencedPackages(removedNodes...)
+		keptReferences := goReferencedPackages(existingKeptNodes(existing, removedNodes)...)
+		for name := range addedReferences {
+			keptReferences[name] = true
+		}
+
+		for _, decl := range existing.file.Decls {
+			genDecl, isGenDecl := decl.(*ast.GenDecl)
+			if !isGenDecl || genDecl.Tok != token.IMPORT {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				name := goImportName(spec.(*ast.ImportSpec))
+				if !removedReferences[name] || keptReferences[name] {
+					continue
+				}
+				edits = append(edits, existing.removeImport(genDecl, spec))
+			}
+		}
+	}
+
+	if len(newImports) == 0 {
+		return edits
+	}
+
+	text := strings.Join(newImports, "\n")
+	var lastImport *ast.GenDecl
+	for _, decl := range existing.file.Decls {
+		if genDecl, isGenDecl := decl.(*ast.GenDecl); isGenDecl && genDecl.Tok == token.IMPORT {
+			lastImport = genDecl
+		}
+	}
+
+	if lastImport == nil {
+		at := existing.offset(existing.file.Name.End())
+		edits = append(edits, goTextEditT{begin: at, end: at, text: "\n\nimport (\n" + text + "\n)"})
+	} else if lastImport.Rparen.IsValid() {
+		at := existing.offset(lastImport.Rparen)
+		edits = append(edits, goTextEditT{begin: at, end: at, text: text + "\n"})
+	} else {
+		at := existing.offset(lastImport.End())
+		edits = append(edits, goTextEditT{begin: at, end: at, text: "\n\nimport (\n" + text + "\n)"})
+	}
+	return edits
+}
+
+func existingKeptNodes(existing *goMergeFileT, removedNodes []ast.Node) []ast.Node {
+	removed := map[ast.Node]bool{}
+	for _, n := range removedNodes {
+		removed[n] = true
+	}
+
+	r := []ast.Node{}
+	for _, d := range existing.decls {
+		if !removed[d.decl] {
+			r = append(r, d.decl)
+		}
+	}
+	return r
+}
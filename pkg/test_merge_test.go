@@ -0,0 +1,238 @@
+package batchai
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+const testExistingGoTestCode = `package calc
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestAdd is hand-written
+func TestAdd(t *testing.T) {
+	if strings.TrimSpace(" 1 ") != "1" {
+		t.Fail()
+	}
+}
+
+func TestSub(t *testing.T) {}
+`
+
+const testGeneratedGoTestCode = `package calc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestAdd is generated
+func TestAdd(t *testing.T) {
+	require.Equal(t, 2, 1+1)
+}
+
+// TestMul is generated
+func TestMul(t *testing.T) {
+	fmt.Println(2 * 3)
+}
+`
+
+func TestSiblingTestFile(t *testing.T) {
+	a := require.New(t)
+
+	a.Equal("calc/add_batchai_test.go", SiblingTestFile("calc/add_test.go"))
+	a.Equal("src/test/java/FooBatchaiTest.java", SiblingTestFile("src/test/java/FooTest.java"))
+	a.Equal("FooBatchaiTests.java", SiblingTestFile("FooTests.java"))
+	a.Equal("FooBatchaiTestCase.java", SiblingTestFile("FooTestCase.java"))
+	a.Equal("TestFooBatchai.java", SiblingTestFile("TestFoo.java"))
+	a.Equal("FooBatchaiTest.java", SiblingTestFile("Foo.java"))
+	a.Equal("src/foo_batchai.test.ts", SiblingTestFile("src/foo.test.ts"))
+	a.Equal("tests/test_foo_batchai.py", SiblingTestFile("tests/test_foo.py"))
+}
+
+func TestRenameJavaClass(t *testing.T) {
+	a := require.New(t)
+
+	a.Equal("public class FooBatchaiTest {\n  FooBatchaiTest() {}\n  FooTester tester;\n}\n",
+		RenameJavaClass("public class FooTest {\n  FooTest() {}\n  FooTester tester;\n}\n", "FooTest", "FooBatchaiTest"))
+}
+
+func TestDropDuplicatedGoTestCode(t *testing.T) {
+	a := require.New(t)
+
+	deduplicated, err := DropDuplicatedGoTestCode(testGeneratedGoTestCode, testExistingGoTestCode, "package calc_test\n\nfunc TestMul() {}\n", "not go code")
+	a.NoError(err)
+	a.Equal(`package calc
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestMul is generated
+func TestMul(t *testing.T) {
+	fmt.Println(2 * 3)
+}
+`, deduplicated)
+
+	// nothing duplicated
+	deduplicated, err = DropDuplicatedGoTestCode(testGeneratedGoTestCode, "package calc\n")
+	a.NoError(err)
+	a.Equal(testGeneratedGoTestCode, deduplicated)
+
+	_, err = DropDuplicatedGoTestCode("not go code")
+	a.Error(err)
+}
+
+func TestMergeGoTestCodeAppend(t *testing.T) {
+	a := require.New(t)
+
+	merged, err := MergeGoTestCode(testExistingGoTestCode, testGeneratedGoTestCode, false)
+	a.NoError(err)
+	a.Equal(`package calc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestAdd is hand-written
+func TestAdd(t *testing.T) {
+	if strings.TrimSpace(" 1 ") != "1" {
+		t.Fail()
+	}
+}
+
+func TestSub(t *testing.T) {}
+
+// TestMul is generated
+func TestMul(t *testing.T) {
+	fmt.Println(2 * 3)
+}
+`, merged)
+}
+
+func TestMergeGoTestCodeMerge(t *testing.T) {
+	a := require.New(t)
+
+	merged, err := MergeGoTestCode(testExistingGoTestCode, testGeneratedGoTestCode, true)
+	a.NoError(err)
+	a.Equal(`package calc
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// TestAdd is generated
+func TestAdd(t *testing.T) {
+	require.Equal(t, 2, 1+1)
+}
+
+func TestSub(t *testing.T) {}
+
+// TestMul is generated
+func TestMul(t *testing.T) {
+	fmt.Println(2 * 3)
+}
+`, merged)
+}
+
+func TestMergeGoTestCodeImports(t *testing.T) {
+	a := require.New(t)
+
+	merged, err := MergeGoTestCode("package calc\n", "package calc\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) {}\n", false)
+	a.NoError(err)
+	a.Equal("package calc\n\nimport (\n\t\"testing\"\n)\n\nfunc TestA(t *testing.T) {}\n", merged)
+
+	merged, err = MergeGoTestCode("package calc\n\nimport \"strings\"\n\nfunc TestA(t *T) { strings.ToUpper(\"\") }\n",
+		"package calc\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) {}\n", true)
+	a.NoError(err)
+	a.Equal("package calc\n\nimport (\n\t\"testing\"\n)\n\nfunc TestA(t *testing.T) {}\n", merged)
+}
+
+func TestMergeGoTestCodeError(t *testing.T) {
+	a := require.New(t)
+
+	_, err := MergeGoTestCode("package calc\n", "package calc_test\n", false)
+	a.Error(err)
+
+	_, err = MergeGoTestCode("package calc\n", "not go code", false)
+	a.Error(err)
+}
+
+func TestResolveExistingTestFile(t *testing.T) {
+	a := require.New(t)
+
+	x := NewKontext(afero.NewMemMapFs())
+	x.Args = &AppArgsT{Repository: "/repo"}
+	comm.WriteFileTextP(x.Fs, "/repo/FooTest.java", "class FooTest {}\n")
+	comm.WriteFileTextP(x.Fs, "/repo/calc_test.go", testExistingGoTestCode)
+
+	agent := &TestAgentT{}
+	c := comm.NewConsole(false)
+
+	// not existing
+	report := &TestReportT{TestFilePath: "BarTest.java", TestCode: "class BarTest {}\n"}
+	a.Equal("generated", agent.resolveExistingTestFile(x, c, ON_EXISTING_APPEND, report, nil)("generated"))
+	a.Empty(report.OnExisting)
+
+	// written by last execution
+	report = &TestReportT{TestFilePath: "FooTest.java"}
+	lastReport := &TestReportT{TestFilePath: "FooTest.java", TestCode: "class FooTest {}\n"}
+	a.NotNil(agent.resolveExistingTestFile(x, c, ON_EXISTING_SKIP, report, lastReport))
+	a.Equal("FooTest.java", report.TestFilePath)
+
+	report = &TestReportT{TestFilePath: "FooTest.java"}
+	a.Nil(agent.resolveExistingTestFile(x, c, ON_EXISTING_SKIP, report, nil))
+	a.Equal(ON_EXISTING_SKIP, report.OnExisting)
+
+	// not Go, the class is renamed as the sibling file
+	report = &TestReportT{TestFilePath: "FooTest.java"}
+	merge := agent.resolveExistingTestFile(x, c, ON_EXISTING_MERGE, report, nil)
+	a.Equal(ON_EXISTING_NEW_FILE, report.OnExisting)
+	a.Equal("FooBatchaiTest.java", report.TestFilePath)
+	a.Equal("class FooBatchaiTest {}\n", merge("class FooTest {}\n"))
+
+	// the sibling file written by last execution is overwritten, but not the one changed since then
+	comm.WriteFileTextP(x.Fs, "/repo/FooBatchaiTest.java", "class FooBatchaiTest {}\n")
+	report = &TestReportT{TestFilePath: "FooTest.java"}
+	lastReport = &TestReportT{TestFilePath: "FooBatchaiTest.java", TestCode: "class FooBatchaiTest {}\n"}
+	a.NotNil(agent.resolveExistingTestFile(x, c, ON_EXISTING_NEW_FILE, report, lastReport))
+	a.Equal(ON_EXISTING_NEW_FILE, report.OnExisting)
+
+	comm.WriteFileTextP(x.Fs, "/repo/FooBatchaiTest.java", "class FooBatchaiTest { /* by hand */ }\n")
+	report = &TestReportT{TestFilePath: "FooTest.java"}
+	a.Nil(agent.resolveExistingTestFile(x, c, ON_EXISTING_NEW_FILE, report, lastReport))
+	a.Equal(ON_EXISTING_SKIP, report.OnExisting)
+
+	report = &TestReportT{TestFilePath: "calc_test.go", TestCode: testGeneratedGoTestCode}
+	merge = agent.resolveExistingTestFile(x, c, ON_EXISTING_APPEND, report, nil)
+	a.Equal(ON_EXISTING_APPEND, report.OnExisting)
+	a.Contains(merge(testGeneratedGoTestCode), "// TestAdd is hand-written")
+	a.Empty(merge("not go code"))
+
+	// failed to merge
+	report = &TestReportT{TestFilePath: "calc_test.go", TestCode: "package other\n"}
+	agent.resolveExistingTestFile(x, c, ON_EXISTING_APPEND, report, nil)
+	a.Equal(ON_EXISTING_NEW_FILE, report.OnExisting)
+	a.Equal("calc_batchai_test.go", report.TestFilePath)
+
+	// the tests declared by the other files of same package are dropped
+	report = &TestReportT{TestFilePath: "calc_test.go", TestCode: testGeneratedGoTestCode}
+	merge = agent.resolveExistingTestFile(x, c, ON_EXISTING_NEW_FILE, report, nil)
+	a.Equal("calc_batchai_test.go", report.TestFilePath)
+	a.NotContains(merge(testGeneratedGoTestCode), "TestAdd")
+	a.Contains(merge(testGeneratedGoTestCode), "TestMul")
+}
@@ -7,7 +7,14 @@ import (
 type TestMetricsT struct {
 	BaseMetricsT
 
-	TotalTestCases int
+	TotalTestCases int `json:"total_test_cases"`
+
+	Passed      int `json:"passed"`
+	Repaired    int `json:"repaired"`
+	Quarantined int `json:"quarantined"`
+	NotRun      int `json:"not_run"`
+
+	AboveMinCoverage int `json:"above_min_coverage"`
 }
 
 type TestMetrics = *TestMetricsT
@@ -29,4 +36,11 @@ func (me TestMetrics) Print(console comm.Console) {
 		me.TotalTestCases,
 		me.Skipped,
 	)
+	if me.Passed+me.Repaired+me.Quarantined+me.NotRun > 0 {
+		console.NewLine().Greenf("Tests Passed: %d, Repaired: %d, Quarantined: %d, Not Run: %d",
+			me.Passed, me.Repaired, me.Quarantined, me.NotRun)
+	}
+	if me.AboveMinCoverage > 0 {
+		console.NewLine().Greenf("Skipped by Min Coverage: %d", me.AboveMinCoverage)
+	}
 }
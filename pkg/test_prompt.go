@@ -49,11 +49,16 @@ func (me TestPromptVariables) WithPath(path string) TestPromptVariables {
 }
 
 func (me TestPromptVariables) WithLibraries(libraries []string) TestPromptVariables {
-	me.Data["libraries"] = libraries
+	me.Data["libraries"] = strings.Join(libraries, ", ")
+	return me
+}
+
+// WithUncoveredCode provides the code not covered by the existing tests, per the coverage profile
+func (me TestPromptVariables) WithUncoveredCode(uncoveredCode string) TestPromptVariables {
+	me.Data["uncovered_code"] = uncoveredCode
 	return me
 }
 
-// TODO: test coverage
 // TODO: example
 
 type TestPromptT struct {
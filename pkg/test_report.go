@@ -24,6 +24,16 @@ type TestReportT struct {
 	TestCode                   string `json:"test_code"`
 	AmountOfGeneratedTestCases int    `json:"amount_of_generated_test_cases"`
 	SingleTestRunCommand       string `json:"single_test_run_command"`
+
+	// the result of running the generated test, only when the test is run
+	RunStatus           string `json:"run_status,omitempty"`
+	RunCommand          string `json:"run_command,omitempty"`
+	RepairAttempts      int    `json:"repair_attempts,omitempty"`
+	RunOutput           string `json:"run_output,omitempty"`
+	QuarantinedFilePath string `json:"quarantined_file_path,omitempty"`
+
+	// how the generated test code is written into the existing test file, per --on-existing
+	OnExisting string `json:"on_existing,omitempty"`
 }
 
 type TestReport = *TestReportT
@@ -36,6 +46,12 @@ func (me TestReport) Print(console comm.Console) {
 	// console.NewLine().Printf("Test Code: %s", me.TestCode)
 	console.NewLine().Printf("Test File Path: %s", me.TestFilePath)
 	console.NewLine().Printf("Test Command: %s", me.SingleTestRunCommand)
+	if len(me.OnExisting) > 0 {
+		console.NewLine().Printf("Existing Test File: %s", me.OnExisting)
+	}
+	if len(me.RunStatus) > 0 {
+		console.NewLine().Printf("Test Run Status: %s", me.RunStatus)
+	}
 }
 
 func ExtractTestReport(answer string, isGolang bool) (TestReport, string) {
@@ -60,3 +76,13 @@ func ExtractTestReport(answer string, isGolang bool) (TestReport, string) {
 	}
 	return report, remained
 }
+
+// ExtractTestCode extracts the test code surrounded by the test markers, or the first code block if no markers
+func ExtractTestCode(answer string) string {
+	if strings.Contains(answer, TEST_BEGIN) {
+		testCode, _ := ExtractMarkedCode(answer, TEST_BEGIN, TEST_END)
+		return testCode
+	}
+	testCode, _ := comm.ExtractMarkdownCodeBlocksP(answer)
+	return testCode
+}
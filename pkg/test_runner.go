@@ -0,0 +1,95 @@
+package batchai
+
+import (
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+const (
+	// the generated test passed at the first time
+	TEST_RUN_STATUS_PASSED = "passed"
+	// the generated test failed, then the model repaired it
+	TEST_RUN_STATUS_REPAIRED = "repaired"
+	// the generated test never passed, so it is moved aside instead of leaving a broken test in the repository
+	TEST_RUN_STATUS_QUARANTINED = "quarantined"
+	// neither the configured runner nor the model provides the command to run the test
+	TEST_RUN_STATUS_NOT_RUN = "not_run"
+)
+
+// the quarantined test file is renamed with this suffix, so that it is ignored by the test tools
+const TEST_QUARANTINE_SUFFIX = ".quarantined"
+
+// the output of test command is truncated to keep the repair prompt small
+const TEST_RUN_OUTPUT_MAX_LEN = 4000
+
+// TestRunnerT runs the generated tests
+type TestRunnerT struct {
+	// command templates by the extension of test file, e.g. `go: go test ./{{.test_dir}}`
+	Runners           map[string]string
+	MaxRepairAttempts int
+
+	// the test files are written and run one by one, so that a broken test doesn't break the run of others
+	lock sync.Mutex
+}
+
+type TestRunner = *TestRunnerT
+
+func NewTestRunner(runners map[string]string, maxRepairAttempts int) TestRunner {
+	return &TestRunnerT{
+		Runners:           runners,
+		MaxRepairAttempts: max(maxRepairAttempts, 0),
+	}
+}
+
+// Command returns the configured runner for the language of test file,
+// or the single_test_run_command answered by the model if not configured
+func (me TestRunner) Command(report TestReport) string {
+	ext := strings.TrimPrefix(path.Ext(report.TestFilePath), ".")
+	if tmpl := me.Runners[ext]; len(tmpl) > 0 {
+		return comm.RenderAsTemplateP(tmpl, map[string]any{
+			"test_file": report.TestFilePath,
+			"test_dir":  path.Dir(report.TestFilePath),
+			"path":      report.Path,
+		})
+	}
+	return strings.TrimSpace(report.SingleTestRunCommand)
+}
+
+// Run runs the test command under the repository directory, returns false and the output if it fails
+func (me TestRunner) Run(x Kontext, command string) (bool, string) {
+	output, err := comm.ExecShell(x.Context, x.Args.Repository, command)
+	if err != nil {
+		if _, isExitError := err.(*exec.ExitError); !isExitError {
+			output = output + "\n" + err.Error()
+		}
+		return false, comm.TailText(output, TEST_RUN_OUTPUT_MAX_LEN)
+	}
+	return true, comm.TailText(output, TEST_RUN_OUTPUT_MAX_LEN)
+}
+
+// Apply writes the test code then runs it. If the test fails, the test file is reverted.
+func (me TestRunner) Apply(x Kontext, testFile string, testCode string, command string) (bool, string) {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+
+	existed := comm.FileExistsP(x.Fs, testFile)
+	originalText := ""
+	if existed {
+		originalText = comm.ReadFileTextP(x.Fs, testFile)
+	}
+	comm.WriteFileTextP(x.Fs, testFile, testCode)
+
+	ok, output := me.Run(x, command)
+	if !ok {
+		if existed {
+			comm.WriteFileTextP(x.Fs, testFile, originalText)
+		} else {
+			comm.RemoveFileP(x.Fs, testFile)
+		}
+	}
+	return ok, output
+}
@@ -0,0 +1,114 @@
+package batchai
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qiangyt/batchai/comm"
+)
+
+const testRunCommand = "if grep -q broken main_test.go; then echo 'FAIL: main_test.go'; exit 1; fi"
+
+func testTestAnswer(testCode string) string {
+	reportJson, _ := json.Marshal(map[string]any{
+		"test_file_path":                 "main_test.go",
+		"amount_of_generated_test_cases": 1,
+		"single_test_run_command":        testRunCommand,
+	})
+	return "```json\n" + string(reportJson) + "\n```\n" + TEST_BEGIN_LINE + "```go\n" + testCode + "```\n" + TEST_END_LINE
+}
+
+func generateWithTestRunner(t *testing.T, existingTestCode string, onExisting string, answers ...string) (TestReport, afero.Fs, string, int) {
+	fs := afero.NewOsFs()
+	repo := t.TempDir()
+	file := filepath.Join(repo, "main.go")
+	comm.WriteFileTextP(fs, file, "package main\n\nfunc add(a, b int) int { return a + b }\n")
+	if len(existingTestCode) > 0 {
+		comm.WriteFileTextP(fs, filepath.Join(repo, "main_test.go"), existingTestCode)
+	}
+
+	server, requests := newTestAnswerServer(t, answers...)
+	model := newTestModelConfig(MODEL_PROVIDER_OPENAI, server.URL+"/")
+
+	config := &AppConfigT{CacheDir: t.TempDir(), Models: []ModelConfig{model}}
+	config.Test = &TestConfigT{
+		AppConfig: config,
+		ModelId:   model.Id,
+		Prompt:    &TestPromptT{Template: "{{.code_to_test}}"},
+	}
+
+	x := NewKontext(fs)
+	x.Config = config
+	x.Args = &AppArgsT{Repository: repo}
+
+	testArgs := &TestArgsT{Runner: NewTestRunner(nil, 2), OnExisting: onExisting}
+
+	resultChan := make(chan TestResult, 1)
+	NewTestAgent(NewTestReportManager(), NewSymbolManager(), NewModelService(config), file).run(x, testArgs, resultChan, nil)
+	result := <-resultChan
+	require.False(t, result.Failed)
+
+	return result.Report, fs, repo, *requests
+}
+
+func TestTestRunnerCommand(t *testing.T) {
+	a := require.New(t)
+
+	report := &TestReportT{Path: "pkg/a.go", TestFilePath: "pkg/a_test.go", SingleTestRunCommand: " go test ./pkg -run TestA "}
+
+	a.Equal("go test ./pkg -run TestA", NewTestRunner(nil, 2).Command(report))
+	a.Equal("go test ./pkg -run TestA", NewTestRunner(map[string]string{"java": "mvn test"}, 2).Command(report))
+	a.Equal("go test ./pkg # pkg/a_test.go pkg/a.go",
+		NewTestRunner(map[string]string{"go": "go test ./{{.test_dir}} # {{.test_file}} {{.path}}"}, 2).Command(report))
+
+	a.Empty(NewTestRunner(nil, 2).Command(&TestReportT{TestFilePath: "a_test.go"}))
+}
+
+func TestTestRunnerPassed(t *testing.T) {
+	a := require.New(t)
+
+	report, fs, repo, requests := generateWithTestRunner(t, "", ON_EXISTING_APPEND, testTestAnswer("package main\n// passing\n"))
+	a.Equal(TEST_RUN_STATUS_PASSED, report.RunStatus)
+	a.Equal(testRunCommand, report.RunCommand)
+	a.Equal("package main\n// passing\n", comm.ReadFileTextP(fs, filepath.Join(repo, "main_test.go")))
+	a.Equal(1, requests)
+}
+
+func TestTestRunnerRepaired(t *testing.T) {
+	a := require.New(t)
+
+	report, fs, repo, requests := generateWithTestRunner(t, "", ON_EXISTING_APPEND,
+		testTestAnswer("package main\n// broken\n"),
+		TEST_BEGIN_LINE+"```go\npackage main\n// repaired\n```\n"+TEST_END_LINE)
+	a.Equal(TEST_RUN_STATUS_REPAIRED, report.RunStatus)
+	a.Equal(1, report.RepairAttempts)
+	a.Equal("package main\n// repaired\n", report.TestCode)
+	a.Equal("package main\n// repaired\n", comm.ReadFileTextP(fs, filepath.Join(repo, "main_test.go")))
+	a.Equal(2, requests)
+}
+
+func TestTestRunnerQuarantined(t *testing.T) {
+	a := require.New(t)
+
+	report, fs, repo, requests := generateWithTestRunner(t, "", ON_EXISTING_APPEND, testTestAnswer("package main\n// broken\n"))
+	a.Equal(TEST_RUN_STATUS_QUARANTINED, report.RunStatus)
+	a.Equal(2, report.RepairAttempts)
+	a.Contains(report.RunOutput, "FAIL: main_test.go")
+	a.Equal("main_test.go"+TEST_QUARANTINE_SUFFIX, report.QuarantinedFilePath)
+	a.False(comm.FileExistsP(fs, filepath.Join(repo, "main_test.go")))
+	a.Equal("package main\n// broken\n", comm.ReadFileTextP(fs, filepath.Join(repo, report.QuarantinedFilePath)))
+	a.Equal(3, requests)
+
+	// the existing test file is kept
+	existingTestCode := "package main\n\nfunc TestOld(t *testing.T) {}\n"
+	report, fs, repo, _ = generateWithTestRunner(t, existingTestCode, ON_EXISTING_MERGE, testTestAnswer("package main\n\n// broken\nfunc TestNew(t *testing.T) {}\n"))
+	a.Equal(TEST_RUN_STATUS_QUARANTINED, report.RunStatus)
+	a.Equal(ON_EXISTING_MERGE, report.OnExisting)
+	a.Equal(existingTestCode, comm.ReadFileTextP(fs, filepath.Join(repo, "main_test.go")))
+	a.Equal("package main\n\nfunc TestOld(t *testing.T) {}\n\n// broken\nfunc TestNew(t *testing.T) {}\n",
+		comm.ReadFileTextP(fs, filepath.Join(repo, report.QuarantinedFilePath)))
+}
@@ -6,9 +6,11 @@ import (
 	"github.com/rakyll/statik/fs"
 )
 
+
 const Res = "res" // static asset namespace
 
 func init() {
-	data := "PK\x03\x04\x14\x00\x08\x00\x08\x00\xcc}}Y\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x0b\x00	\x00batchai.envUT\x05\x00\x01\xd1\xe1Ig\x8cU\xddn\xdb8\x13\xbd\xf7S\x0c\x90\x8b\xdc\xb8v\x93\xa6?\x9f\x01]\xb8\x8e\xf2\xd5\xcd\x8fSK\xd9m\x80\x02*-\x8e,niR\xcb\xa1\x92h\x03\xbf\xfb\x82\xa4d;\xae[\xec\x9dE\xce\x9c\x999sx\xfcq\x9cN>\x8d\xa7\xd9d<\xf9\x14g\xe7\xd3y\xb4\xa8\x85\xe4\xc3\x05\xb3y\xc9D\xefhv\x1b\xdf\x8c\xa7\xd9\xf8v\x9a]\xc6\xf7\x9b\xef\xd9\xfc\xff\xd9\xf4|\xf3y;\x9f}\x8e'\xa9;jO>\x8e\x938\xbb\x9b_E\xa5\xb5\x15\x8d\x86CV\x89\x81\xaeP11\xc8\xf5j\xf8p2\xecm\x93\xbf\xde\xfb\xd8\xbd\x93$\x9e\xef\x1d\xdd\x8e\x93$\xea\xf5\x8e\xbe\xfc\x19\xdflz\xf2\x1f?\xd5\xe3\x8cJ\xcau\x85\x03&ES\xab\x9c|\xdd\\\xaf*f\xc5B\xe2\xab\x95\xe6\xe8\xfb\xf0\x00\xd7\xe3\xaf\xd9dv3\xb9\x9b\xcf\xe3\x9b4\x9b\xc7_\xee\xe2$M\xa2\x93^\xefhvu5\xbe\x1ew\x05\xa3^\xfb\xfd\xa2\xe6h8\x94:g\xb2\xd4dG''go\xce\xc2\x8c!\xf47\xe8\xdd\n\xd28I\xb3\xeb\xd9y|\x15\x05\x9e\x86\xcb\xca\xbe:\xd3\xafVB\x89m\xd8\xe4S<\xb9\xfcu\\\x17\x16\xf8\x9a\xde$\xf1\xe4n\x1eg\xc9\xe5\xf46\xfb#\x9eO/\xee\xa3\x82I\xc2\x1d\xbcq\x9a\xa5\xf1u<\x1f\xa7w\xf38z=8\xdd\xdc\xb9\x81\xd3\xe9u<\xbbK\xa3\x93\xd3\xd7\xb4\xd7D\x12;\xc4\xf4>Z	\xa5\xcd~\x8b\xf3\xbb\xab8;\x89&%\xe6?`\x8e\x956\x16\x12k\xea\xdc\xd6\x06a\x04i\x89\x90k\x8e\x90\xfb\x08\x83TK\x0b\xab\x9a,\x14\xc2\x90\x05.\xa8\x92\xac\x01\x06&d\x0b\x05\xb6D(\xb4\x94\xfaQ\xa8%|Nf7Ph\xb3bv\x04\xdf\xbf\x7f\xff\x8b\xb4\x82\xe7\xe7\x81G\xccBV\xe6\x0e\xb3\x10\xb4^\xbb\xa8C}\x9eF\x13\xad\xb8\xb0B+&aV\xdb\xaa\xb6#\x98)\xe0h1\xb7\xae\x98 \xaa\x91\xfa\xd0\xe8:t\xa9}ThI<!\x87BH\x04F\xc0\x80\xb0b\x86Y\x04\xc2\xe5\n\x95\x05\xb2\xccx\x94GaK\xd7b!\x9e\xb2\x05.\x85Z\xaf\x81)\x0e\xa8\xf8\xfe5*\xbe^\xf7A\xa8\\\xd6$\x1e\x10t\xe1\x8b9	K\xb4\x08\xda\x88\xa5p\xfd\xe6ZYT\xb6\x0f\xe73\xb8\x99\xa5!\x85#\x85\x9eA\n\x85\x04Z\xc9f\x00\xd3\x02\x94ng\x01f\x1c\x9b\xb5\xe2}\xe0\x1a\x94\xde\xcc\xc4v&\x1a\x1c\xe2\xebM4\xf5\xd0	>\xa0\x11\xb6\x01G\x96l\xbaM\xb9\x89*\xa3s$\xeaj\xd9\x92Y(\xd9\x03zz\xda,]\xc0\xb1\x17\xcf1h\x03\xa5X\x96h\x060]*m\xb0K\x94l\x81\x12\xb9#\xf6\xf8\xf9y\xd0\xe5\xae\xd7\xc7\x87\x1a;\x8b&NS\x17~\xdf\x9e\xf1\x11\\3\xa1,k\xd5\xb3!\xad\xd8\x86l\x88\xe5\xe87\xa0k\x0b\x06w\x02\x84\x05^\x1b\xf7\xcb\xc7y\xc1\xb6\xf3\x1d\xa4\xe7m\x14?U\x92)\xe6\x04\x05WL-k\xb6t\xa2\x1fK	\xb8\xbd\xa2\xa0\xa4\x05:\xb4\x07\xc1\x91;\x91??\x0f$S\xcb\xf5\xfa\x10\xf4\xbb(\xd9a\xef\\\xe7\xb5\x13X(4\x0d\x8c\x8d 5\xc8lG\xa0A\xc9,r -Q6`\xb5S\x90K\"G:o\x11\xc8\x11l\x8dx\x10L\xba&:\x9e\x07\xee\xa1\x12\x02\x95\xba\x96\xdc\x8bd\x81\xed\x9e\x91C\xad\xa4[2U\x98\x8bB\xe4Lz\x11\xfc]#\xb9[mK4\x8f\x82\x0e\x8b\xe8}t\x89Xm7\x82O\x82\x02\xdd+\x87N^FR\xe4\xa8\x08A\xa8\xb0\x0f7\xa7;\xeff8\x88\xfc!\xba\xf0&\x01nr\xb2 \xbb\x0dl\xfat8\x83\xde\x11\x1cH\xfe_t\xa1\x0dP\xb3Zh\xd9\xeaV\x90\x1f\x9cc!\x14r\xaf\x91VNym\x8c{\xe0\xee\xa5\xf4;\xf7R\xdc\xdd\xad:\xc3\nP`\xd9Bb\xc0\xab	\x0d\x8c\x80ku\xdc\xa1\x86\x8cE\xe3\xfc\xc5\x10\xca\xc2\xbd|\xb2\xc8x\x1f\x18Q\xbdj\xd5\xd7\x1c\x1b\xdc4\xe2\x88`\x9240i\x90\xf1\x06\x84\x12V0)\xfeA\x0e(	\x1fK4\xd8o\x15\xfb\xcb\x9e\x1eK\x91\x97n\xc8\x8d\n\x17\x8d\x8fr}\xee\xfdC\xb5\xb6~\xbd\xe3\x80\xa7\x9d\xcd\x91\x17Kk\xdf\x9d\xf5yN\x0c:\xe6\x9dt\xdcV^\xf8\xf67\xb5\xe3\xdcn[/\xcc\xfa\x9b\xea\x87~1\xd7\x8a\xbf\x04u\xe7KTh\xbc\xbc\xfd\xa2I\xd7&o_\xb2\xb7c\xa1\x80\x85\xcf\x85\xd4\xf9\x8f\x9f\x8d\xd8W\xfc\x8d\x13\xfb{o\xc5\x03\xf0C3\xf9\xc8\x1a\xea<\xd67q\xb8t\xdb\xeb\xe0\x00\x7f\xa7\x81\xbf\x9a\xda\\)\x16\x86\x19\x814\xf2\x8f\xbf\xfb\xdaq\x80m\xee\x9b\xe0p\x89m$n\xec\x83\xd8\xca\xff\xf5ll\xccj\x8f| \xff,xS\xab\x03\x17\x943r\xabvSs\x9d\xb7D	\x05\x07\x8ch\x0b\xf36\x9a\xaa\xcd#\xec\x07S\xdb\xc7$\x8b\x15\xb5\xc8\xbf\xc3z\x17\xe8(\xfe\xd3\x9b\xfd9\xfd}H\xef\xa4\x00\x0bmK(YU5P1[\x86\x7f#M\xc2\xba?Q\xd7Xp\x16\x85K\xb6w\x94k\xa3\xd0\x84\x83\x03\x85>D\xce\xbfu\xb1\xaf\xba\x00\xd0\xed\xc2K\xce\xcd,7\x1a\xf4\xef\xd8%\"\xcb\xcb\xdd\x9c\x92\x11\x08g\xc4\x8f*\x1c\xfb\x84\xf6\xb5\x88\x07\x94M\xef\xdf\x00\x00\x00\xff\xffPK\x07\x08\x14,\xf8\xa1(\x05\x00\x00\xa3\x0b\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xcc}}Y\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x0c\x00	\x00batchai.yamlUT\x05\x00\x01\xd1\xe1Ig\xecV[o\xdb6\x18}\xf7\xaf \x8a\x01\x02\x8a\xd8\xb5\x1c'q\xfc\xa6\xba\xdaj4\xb7\xd9\xce\xb6`(XY\xa2m\xd5\x92\xa8\x90\x94/\xe8\xfc\xdf\x07J\xaaC]H\xbak\x92\x87\xc1O$x\x0e?J\xe7|\xe2\x11\xda\xb8A\xe2!\xda\x07\x7f\x1b\xad\xb9\xcf\x8c\x13\xa3EW\x91q\x02\x8c\x15\x8a<L\xf8,\xc2\x1e\x82!\xf6\x92\x00Q\xe3s\xc3u\xdc\x05\x82\x9eO\xfa\xe0\x97o\xef\xad\xc9\xe0\xa35\x84\x03k\xf0\xd1\x86\x1f\x86\xa3]#p\xa29\x87\xae\xac\x9b\xdfv\x0d\x86(\xeb7\x00\x08\xb1\x87\x02\xe8{\xe2\xa6\x89=\x9e\xc0\xeb\xdb\x0f\xf6\xd5\xae\x01\x80\x1f==\xcb\xdbV\xbc\xe5G\xf3\x91-p\x94\xcd]\x9a\x8fq\x9cO\xdcl\\\xe4\xc3~=\x1bH2\xcd\xab\xccq6~uVN6[\xb2l\x0c\x92|\x81\xe4\xc5\xa9\xeb\x04\xf9\x12\xcb\x97\xe2E^\x97\xae\xfdY\xbe/\x0e\x8c\xcf\x0d\x00b\x82\xc38}A\x00\x08\x17(\x9b\x02\xd0\x04oJ/:\xba\xbf\xb2\xa1\xb9{\xa3&tt\x84S\x1d\xa1\xab#\x9c\xe9\x08\xe7:\xc2\x85\x8e\xd0\xd3\x11.u\x04\xb3\xadeh\xb54\xb5b\x9aZ5M\xad\x9c\xa6VOS+\xa8\xa9U\xd4\xd4Jjj5\xed\x944\xbd~\x90wf\x01\xeb(\xb0S\x05\xd6U`g\n\xec\\\x81](\xb0\x9e\x02\xbbT\xbd\xbbR\x18\x952\xe5\x16+\x82*m\xca\x8dU\x04U\xea\x94\xdb\xa9\x08\xaa\xf4)7Q\x11T)\xb4o\x1d\x86\xc28p\x18\xea\x83\x7fr2\x00\x16\x05N\x04<\xb4B\x01\x8e\x11\x01h\x13#\xc2N\xc0\x16'\x06A\x80\xa0\xc7\x04Q\x86<\xc00X\x13\x9f!\xc0S\x01\xb8\x0eE\x94\xdf\xa0+\xdfC\x1ep\xb1\x87\xc0\x0c\x07\x01^\xfb\xd1\x1cLQ\x80\xd7\xf4\xfb\xa5\xba?\xeb\xdb\xb7\x16\xdf\x0c\xd3\xf5\xddn\xbf\xbe\x9f\xdc9l\x01\xf0,+\xc7pzT\x9fo\x8b\x1d\xb6\xa8\xdb`o|\xca\xf8\x89\xd9Ca\x0f\xf5\x81x\x1c\xcaq\x98\x9e\xcb\xf1\xba*\x03\x1c1\x14\xb1\xca\xc9OO\xfe\xe5\xcb\x17\xb1,gA\x86\xd3\xaaBA\xcer\x17\xc8]\xcaBs\xf0\xd1\x1e|\xaaO\xcd\x0f\xd8]\"2\xf3\x03d\x9c\x18o[\x9b0H\xc7\x97\x89\xd2\x05\xe3\xe5\xf3Y9]\xbf\xd2\xef\x07\xfd\xa7\x9c\x8d	f\xb8\x12\xb9\xa1\xb7\x8f^\xfeZt\x91\x0e['\x7f\xcdm\x98E2E+D|\xb6\xad\x8a6\xb6\xff\xb0G\xc3\xc9\xc3\xee\xe0\xe0\xce\xf6\xd5\xdd\x8f5\x0cI\xda\x08\x0cI\xda\x08\x0cI\xda\x08\x0cI\xda\x08\x0cI\xda\x08\x0cI\xda\x08\x0cI\xda\x08\x0cI\xda\x88\x8aI\"\\\xa4\xe8U\x95\x85\xb8H\xd1\xeb*\x8bq\x91\xa2WV\x16\xe4\"E\xaf\xad,\xcaE\x8a^\xdd\x9a0\x97wk\x11\xacf\x96\xbcG\x8b`5\xb3\xe4\x9dY\x04\xab\x99%\xef\xc7\"X\xcd,y\x17\x96DPK\xa4\xd4\xa8&\xd8\x15\x1dWB\x952\xd5d\xbb\xa2\xc5J\xa8R\xa9\x9axW\xf4T\xa9'\x9e%\xe0\xa7[\x90PD(\x8f\xbf4\xc5\x9e\xe2\x9d\xc7R1\xc7S\x82>\xc8\xf9\xc6}=1\xc9\xf7L!{\x8b\xe4jQI\x08\xa7\xf4R\n7\xd2\xf4Ms\xa1	x\x06\xe3\x18E\x8e\xffn\x1e\xb3f\x17\xa7\xd4\xc8	Q\x1f\x08\x0b\\<D\x1c\x96\x10T\x8c\x1fk\x02'\xf6\xb5=\xb2&\xf7#;;\xc7\xe5O\xbdap\xedG\x1e^\xf7\x81\xd9\xe9\xb5\xdb\xed\x14rb\x1f.Q\x9a`\xb7w\xf6\x8d5\x84\xd6\xdd\x10~\xb2\x1f\xb2\x9dS\x87\"\x98\x90@\xc0\xdf[c\x1b\xde\x8f\xae2\x02\xf3C\x84\x13&>\x02/0\x19^\xdb\xb7\xf7\x93\x8c\x13\x13\xbc\xd9\x96\xaa\xdc\x8dn\xffzx*\x93Q\xfc\x88\"7!\x08\xd2\xa5\x1fC\x9e\xad\xb3B\xb6f\x9b\x867c{p?\xb2\xe1\xf8\xd3\xf0\x0e\xf2\x9c\xfd\xf5\xa1p\x10E\xa4z\xd2\xd8\x1e\x89\xa4\xd8\xa1\xb4B\xba\xb3\xc6\xe3\xd4\xec\x1a\x17\x9a\xa1\x1f\xf9\x15+\x9eV\x7f\xca\x8f\xf3\xd3^\xf7h\xc7\x0f\xd8\xd1d	\x99V\xbe\x0ca\xb5\xb6\xe5\x7f\xd8\xa8\xa3\x1d\x87\xd9Q6\xa2\xd6\x82\x9ey\xd99:\xf02\x0e\x9c\xb6\xcej?\x89\xe2z\xdd\xbdsv\xb4\xe4\x85-i\xfa\x11e$q\x99\xcc\x9b\"\xa1lR\xb7}y~\xf4\xe8\x19=b8\x9ao\xfdw\x8fk\x14uZgM\xfecF\x9a\x17\xd3:\x9b\xd4\x9c\x1f1\xa4\xec\xeai\xe7\xe2\xbc\x97\"\xa1\xb3\x81.\x0e\xe3\x001\x1fG\x90\xe1%\x8a\xa8H\x10\xbc\xfc\xfdO\xfbF\xeed\x8a\xfe\x94\x8f\x86\xf1B\xb6\x15\ng&\x19\x86\xf0\xd9\x04\x81\x13:EK\xfa\x82\xdc\xcdYl\x9e\xcb|\xa9'\xfe\x8c9\xe6\xa9\xd9\xbe\xe8\xa8\xdc\xc9\x18\xd5_\xe8\xab+\xeb\xdaR|k\x19\xfe\xff\xf4\xe8\xb1\x0b\xdb\x07y\xb4'\x1e=zu\x8fz\x87z\xd4;z\xf4\x8a\x1e\x99\xddC/\xbbz\xe6\xd1\xa5Ww\xe9\xb1\x0b\xcd\xc3\\\xda3\x8f.\xbd\xbeK\xaa\x0b\xaf\x9eyt\xe9Y\\\xfa7\x00\x00\xff\xffPK\x07\x08\x82|\x8en\xe0\x04\x00\x00\xf1#\x00\x00PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xcc}}Y\x14,\xf8\xa1(\x05\x00\x00\xa3\x0b\x00\x00\x0b\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\x00\x00\x00\x00batchai.envUT\x05\x00\x01\xd1\xe1IgPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xcc}}Y\x82|\x8en\xe0\x04\x00\x00\xf1#\x00\x00\x0c\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81j\x05\x00\x00batchai.yamlUT\x05\x00\x01\xd1\xe1IgPK\x05\x06\x00\x00\x00\x00\x02\x00\x02\x00\x85\x00\x00\x00\x8d\n\x00\x00\x00\x00"
-	fs.RegisterWithNamespace("res", data)
-}
+	data := "PK\x03\x04\x14\x00\x08\x00\x08\x00\xb7\x1cQ]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x0b\x00	\x00batchai.envUT\x05\x00\x01\x8b\xed\xd2j\xc4Y[s\xdb\xb6\xb3\x7f\xcf\xa7\xd8\x99>\xe4E\x91sq\xd2\x1e\x9f\xe1\x83*3\x89\x12Kr)\xb9\x89\xcftF\x85\x88\x95\x88\x86\x04X\x00\x94\xcd\xe3\xd1w?\xb3\x00H]L%\xe9if\xfeO6\x81\xc5b\xef\xfb[\xe8\xd7\xc1|\xf8~0Z\x0c\x07\xc3\xf7\xf1\xe2r\x94D\xcbJ\xe4\xfcl\xc9l\x9a1\xf1\xe4\xa7\xe9u<\x19\x8c\x16\x83\xeb\xd1\xe2c|\xdb~O\x93w\x8b\xd1e\xfby\x9dL?\xc4\xc39-\x85\x95_\x07\xb3xq\x93\\E\x99\xb5\xa5\xb98;c\xa5\xe8\xab\x12%\x13\xfdT\x15g\x9b\x17g\x0d\xe9u2\xfd|\xebh\x8fVfqr\xb4t=\x98\xcd\xda\xa5\xf1\xe0\xf3b8\x9d\x0co\x92$\x9e\xcc\x17I\xfc\xdbM<\x9b\xcf\xa2\x17\x0dA\xb3\xb2\xb8\x8e\x93\xc5x4\xb9\x99\xc7\xd1\xf3fs>\xfd\x18O\x8e\xb6\x9e\xfc4\x98\xcc\xdf'\xd3\xeb\xd1\xb0\xd5x\xb7\xd2\xa9\x12\x936\xd3\xaa\x14i\xab\xd5\xee\xc0\x9eb\x8f\x16\x9dn\xc7\xab^\xbd\xdd\xeai\x0dw4\xddJ\xee\xf6\xbb\xf5|\x17\x8fG\x93\x9d[\xc3\xe7#\x0d\xd7(Q3+6\x983\xb9\xae\xd8\x1a\xfbk\xa5\xd69\xb2R\x98\xa0\xf1\x12-;k8\xec\xa9|\xb8\xe2\xf4=X\xf2\xca\x86\xa5\xd3\x9a\x06\x82n5\xc3f\xb7\x8e\xbf}\x8a'\xad\x86\xee\xe3\x91~\x9c\x99\xcc\xa4\xaa\xc4>\xcbE]\xc9\xd4+\x95\xaa\xa2dV,s|V(\x8e.X\x1d\x83\xd3b\xba\xedn!\xddV\xb7\x88\xd3\xab\xab\xc1x\xd0\x08\x19=	\xdf\x07r^\x9c\x9d\xe5*ey\xa6\x8c\xbdx\xf1\xe2\xfc\xd5\xb9\x93'\x90\x9e\x96(\x10t\xcb\x146;\xa5jj\xc2<\x9e\xcd\x17\xe3\xe9e|\x15\xf9\xc4=[\x97\xf6\xd9\xb9zV\x08)vd\xc3\xf7\xf1\xf0\xe3w\xd0\xc5\x9f\xaf\xaf\x06\xa3\xc9\xf7p\x9c\x8e\xc7\x94\xd1\xdf\xa6L\xe2\xb7\x83\xe1|\x9a|\x17\xe9\xef\xa3\xf8\xd3i\xc2\x86\xa5\x8f\xe0\xd1d\x16\x0fo\x92x1\xfb8\xba^\xfc\x1e'\xa3\xb7\xb7\xd1\x8a\xe5\x06[\xc2\xe1\xfb\xc1|1\x8f\xc7q2\x98\xdf$q\xf4\xbc\xff\xb2\xdd#\x87\xceG\xe3xz3\x8f^\xbc|n\xda\x8d$\x9e'\xb7\xcei\x83\xf9<\x1e_\xcfg\xd1\xab\xa3M\xe7\xfc\xcb\xf8jp\x1b\xbd\xec:\xe8\xb7\xde<b\xfaa4\x9f\xc7\xc9\x81\x18\xde5\xb3\x98\xe4\x9f\xdfF\x85\x90J\xb7\xa7\xfc&m\xbd\xbdu&\x1fL.\xa3\xa3]\x124\x89\xaf\x07\xa3d'\xefNK\x1f _%	V?\x12\xa1\xdd\xf6\xb7$7W\xf1\xe2E4\xcc0\xfd\x02	\x96J[\x98Y]\xa5\xb6\xd2\x08\x170\xcf\x10R\xc5\x11RG\xa1\xd1T\xb9\x85\xa22\x16VB\x1b\x0b\\\x982g50\xd0\xfe\xb4\x90`3\x84\x95\xcasu'\xe4\x1a>\xcc\xa6\x13X)]0{\x01\x7f\xfe\xf9\xe7_FIxx\xe8;\x8e\x0b\x7fjA\x8b\x0bO\xb4\xdd\x12U\x97\x9c/\xa3\xa1\x92\\X\xa1$\xcbaZ\xd9\xb2\xb2\x170\x95\xc0\xd1bj\xe92aL\x85\xa6\x07\xb5\xaa\xbc\x94\xcaQy\x91\xc4=rX\x89\x1c\x81\x19``\xb0d\x9aY\x04\x83\xeb\x02\xa5\x05c\x99v\\\xee\x84\xcdH\xc4\x95\xb8_,q-\xe4v\x0bLr@\xc9\x8f\xb7Q\xf2\xed\xb6\x07B\xa6ye\xc4\x06A\xad\xdceT\xc7r\xb4\x08J\x8b\xb5 yS%-J\xdb\x83\xcb)L\xa6s\x7f\x84\xa3\xf12C.$\x1aP2\xaf\xfb0Z\x81TA\x17`\x9a\xacYI\xde\x03\xae@\xaaV'\xb6\xa7Q\xbf\xcb^\xaf\xa2\x11\x99\x03f\xb8A-l\x0dd\xac\xbcn<E\x1a\x95Z\xa5h\x82\x0c\x06l\xc6,dl\x83\xce<\xe1\x94Z\xc1S\x17<OAi\xc8\xc4:C\xdd\x87\xd1Z*\x8d\xcd\xc1\x9c-1GN\x86}\xfa\xf0\xd0o\xcen\xb7O\xbb\x04;\x8f\x86\x14So\x9d\xbf\x9d\xc5/`\xcc\x84\xb4,DOk\xb4\xd5\x8e\xa45,G\xe7\x01UY\xd0\xb8G ,\xf0J\x13\xa9\xa3\xa3\xf0j\xf4\xeb4\xcf\xeb(\xbe/s&\x19\x05\x14\\\x85.\x0b\x170\xc8s\xc0\xdd\x96\xf1\x91\xb4D\xe2\xb6\x11\x1c9\x05\xf9\xc3C\x9f\x1a\xf3v\xdb\xc5\xfaM\xd4\xda\\\xad\xe0R\xa5\x15\x05\x98\xbf\xc8\xf9\xc4Pnid\xb61\xa0\xc6\x9cY\xe4`T\x8ey\x0dVQ\x04\xd1!CF\xe7\x81\x83!\x03[-6\x82\xe5$Dc\xe7>%\xaaA0\x99\xaar\xee\x82d\x89\xc1\xcf\xc8\xa1\x9299\xd9\x94\x98\x8a\x95HY\xee\x82\xe0\xef\n\x0d\xed*\x9b\xa1\xbe\x13\xa6;\x88~\x8e>\"\x96;\x8f\xe0\xbd0\xde\xdc\x05q7.\x8cr\x91\xa24\x08Bz\x7f\x90Ai\xbd\xd1\xa1\x93\xf3/\xd1[W$\x8047\x16\x1a\x9c\xb3\x93\x93\xf8\xf4\x9f\xfc\x04\x1d\x87\xff+z\xab4\x98\xbaX\xaa<\xc4\xad0Nq\x8e+!\x91\xbb\x18	\xe1\x94VZS\x82S\xee\xf7\x9a\xea%9\xc5I\xd1\x14,\xcf\n,[\xe6\xe8\xf9U\x065\\\x00W\xf2i\xc3\x95(\x0bX\xd6T_\xb4\xc1|E\x99o,2\xde\x03fLU\x84\xe8\xab\x9fj\x0cG\xb83\x04\xcb\x8d\x02\x96kd\xbc\x06!\x85\x15,\x17\xff\x8b\x1c07x\x97\xa1\xc6^(\xb1'e\xba\xcbD\x9a\x91\x92m\x14.k\xa2vrv\xd9\xf7\xc5\xf3hV\x95\xa5Fc\xc8\x1fc\xa6\xbf\xa0\xa6\xc0\xbbt\x1a\x85*\x10\xe2O\xf9\x1b\xa9\nAA\x94\x8e=s\xd9,\\\xaa/\xdc\xb2\xden\x9f6n\xed\x81T\xfa\xe0\xe4_\xd4\x18\x96\x98\xab;\xb7\xe6O\xf4\xe1S\x862T-\x1b,\xb4W\xbbz )\x8cAc\xa1\xa8\x80jH3&\xd7\xce\xd8\xdd\xf7\x93E\xf76\xc8\xad\x8f\xa53\xfd]\xb3s\xed2\xf4\xba\xf1^[x\xd9\xd4~\xe32(\xf4\xb4\xb0\xe6\x03E#\x85c\xa3\xd5A3\xfbC\xee\xb53\x8b\xc6\x1et\xb0?d\xcfi`0U\x92\x1f2\xa5\xf5\x00\xf0\x91\x03\x1d\x05\xa3*\x9d\x86\xf2F\nQ\\2\xff\xb9\xccU\xfa\xe5qw\xa2c_kOn\xdf\xf5\xa7>8\xa5Y~\xc7j\xd34\x1e'D\xf7\xd5A\xd6~\x87\xfd^z\xfbU&\x9c\xcd\xc5R3-\xd0\\\xc0\xc3\x83XA\xbf]\xd8n\x1f\x1e\x0e\xbf(\xd6\xb7[\xba\xb6P\xc6B\xa9\xca*g\x14@h\xa8\xd0;\x81\xda\"\xb0_\xee\xadr\x97=<8m:\xa4z\xe5\x1b\xca\xcc\xd69\xb6\xd5\xda\xb0\xc2u\xfac6\x1d\xe7\xcf}+\x08iGD)3\x94Ydo\xae\xd2\xe0\x02!\xa1\xa3\xee\xef\x82\xebu4\x92m\xcd\xeb\x01v\xf14\x16K\x138\x7f\x8d\xd7\x1bo\xe8\xd5w\x95\xc8\xc7\xa2\xfc\xec\x8f7A\x06Ke3\xc8XY\xd6P2\x9b\xb9\x14*\x95\x114`\x02	\xe6\x0b\xb9\xc45;ZJ\x95\x96\xa8=M\xc7E\xbfD\xd4.\xd5\xea8\x9e\x1d}\xeb\x0b\x17\xccd\xcd\xbc\x8dnW6\xe9 \xb24\xdb?\x931\x03\x82\xfa\xde\x9d\xf4\xcb.\x1dB\x1e\xd2<\\?\x9elBn7^<\x81d\xf7\x9az+Y\x03\xa6\xcc\xff\x03\xc2\x06\x07\x7f\x1b\xc46\x03XH\xa1YU\x14L\xd7\x01_\x9b\xf0\xd5H\xd4\x1a\xca !\xc64\xb4#\x8e&\xd5b\xe9P\x1aBY\xe9R\x99\x16n\x1eb\xc0\x83\xeb^\x1d\xc0\x9c\xa1G\xa1\x1d\x069\xe4O\xe6V\xd2\x88\xa5\xc8\x85\x15\x04\xa9\x0b\ne\x82\xb5JRu\xed9@\xabU\x0e+*\xf7\x14OR\xf9\xee\xc9\xd1\x885\xa1\xf2TP\xe71\xfbB\xb6\x0d\x93\x165\x96\xc8\xda\x8e\xe0\xea\x8fk@\xcb\xda\xfd=\xa1PH\xd6\x1f\x86\xdb\x0e\xac\xf5:\xba<\x04\xd9\xb2v\x82\xf5\x9c\x86G\x00\\\xd6\x1e<\xc1\x9d\xd2\xdc\x00\xde\xa7X\xfa\xe2\xee\"\xc6\x87E\xff\xf1t\x1d\xa2\xd5O0\x07\xf3\xd6~s:\x18#BU\xf9\x87\x03L8\xf5\xb5.\xd1\x90\xfc\x80A&\xb0B\xde1\xcc\x84=\x90\x88\xdcPA_\"0N>Q\x1a\xaa\x92S\xe18\x1ep\x1e\x07v\xf3<\x11\xf2h\x18\x98\xce\xe8\x1d	\x9a\x0eT\x08C\x95fA\xb3\xd4v\xeb&\x1e\xc6\xc9wi#\xc5\xae\xdd\xe0=e.\xf2C$\xe9& \xa9\x0e\x8f\xd4h}\x10|!<\xec\x0f\x07(\xdc\xd2T\xd2C\x17\xdeo\xba\xdd\xa0\xebf\x82;\xf5\xf1\xdd\x9e\xb97\xc5!\xfb\x031\x84sK\xed\xc6B!Y\x9aV\x9a\x0e(M~\x08\xd1\xd2?\xee\x94\x07v{\x15]\xe1\x06sJ\xc0K\xb4L\xe4\xad\xe5\xb8\xfbD\xbe\xdd~\xd2\xc2\x12\x82\xf5\xdf\x07\x12\xf4\x9aJ\xd1\xe4m(E=\xa0h,\xd0\xa26=\xd0h+-a\xc3r7\x8f\xa3\xd6J\xfb~b\x04G\xc0\xd5\nSk\xda\xf2\xef\xcd\xdfZ\xcd_o\x04\xa9sty[\x1dS%Sa\xf6\xaa$A\x88#f\xa7\x8dp\xde\x06\xcf\xd1\xe0\xd7\xdc\xd4\x96\xe3;-\xacEyb\xe6\xdb\xe7\xf9z\x17\x90\x0e\x88\\@\x18p\xc8\x9b\x82\x0bE\x93Q\xba\xaf\x0f\x18G\x18R\xad\xd4j\xadY\xe1\x86\x88\x06\x07\xf5\x00\xfb\xeb>\xac\x15\x9d\"\x0d\xdf\xa9\x1e|`\x1b\xd6|\xd3\xff\x948\xa9\xb14\xfb\xfa\xd0\xbe\xaem\xb6\x8f\x0b\x0e\xc4|\x13\xb9\x9c\x08\xb2\xd2@0tAKe/\xa3\xbb}M\xb3\x19\xdb\x81\x99>\x84\xb2\xd8L\xdc\xe4a\xc9\n\xa4\xbfJs\xd4\x14\x81+q\xdf\xa2\xad\xbd\\\xf9\xc7\xb3c\xbf\xe3\x99\xf1\xddtp\xb5\x88?\xcf\x93\xc1p\xbe\x18\xc7\xf3\xf7\xd3\xcb(\xbe\xb7\x9a\xa5\x16rERk\xe0\x88e^\x83\xf4s\xad\xc3l.\xc4\xa8\x80\x83\x90V\xc1\x1d\xe6\xf93\x92\x9bC\x816S\xdc\x8d\xd7\xabJ\xa6t\xaf\xe9?~\xdet\xf7&\xf1x\xfa{\xbc\xb8\xbc\xb9\xbe\x1a\x0d\x07\xf3\xd1t\x12%~Z\xe1U\x99\x8b\x94\xaaW\xc0\xea\x94\xd7N\xaa][+\n%)9,ao\xab@ce\\\xa3\x0c\"\xf4v\x02\x904\xb6.\xf1\xa4$\xe3\xe9e\x9cLF\xff\x13/f\xb7\x93\xf9\xe0stCH\xdcAj\x8eZ\x82\xa9\xa5e\xf7\xce\xf6\xc62\xc9\x99\xe6\x01\xa3\xd7\xb0BFm\xa6\xed\xc7_\x9d\xbb\xf7\xfb4\xf1W\x95uE\xda\xc1\xf5S\xd2\xcdF\xe3\xeb+\xff\xa89\xb9\x1c\x91\x9d\x06W\xb3hFy,Vu\xe8%\xf7\x90\xee\x9e\xf1L\x08p\x1a(\xd6\x15I\x9b\xe6\xacj\xe0(2\xed\x9e*\xa8\x96\x98}\x89\xc8\xd3\xce\xcfB\xaeO	3\x1a_'\xe4\xb5\xc9`<\x9a\xbc\x8b\x12\x17\xafT\xa2sd\x1a\xdc\xc3>l\x98\x16\xe4\n\xb3_\xbd\xc2\xcb\x98\xd8Pam\x1b\x83kY4\xf3?\x0b\xb0O\xc8\xf5\x7f7\x1dK{\xe6G%}\x7f\x04m\xdd\x18\x9a\x7f\x82+\x96Z\xa5OaU\xdd\xec\xff\xc87\xd7\x86\xe9\xb7\x11\xeb\xa1\xb8'_^	\x02\xf9\x96\xd7\xfd\xe6\xda\\\xf8\x0fqK+\xe7W\x80KK\xf3#\x90\x8bS\xa1\x03\xb7\xf8\x82\xd8\x82\x96F\xd5\xe0\xf5\xd3\x8av\xc4d\xe8\xbd\xbfb\xc66\x82j\xb4F\x83zC*]@\x13\x0d\xf4\xe5lH\xec\xf7\x9eA\xf0\xde\xa2\xa6\xe7\xb7es\xbcU\x92\xb0\xa9\x0c\xd33\xe1\xee]\x8e?\x8e\xc6n\xa1\xce\xa3w\x8a\xe5\xf0V\xa5\x95A\xde\xbc\x12\x17\xec\x0b\x86\x97T2\x0e=Q\xfe]	\xbd{wZ+\x97\xbe\xcd[\xec\x0e\x0d\x87\x03\xa7l\xf0\xfa_\xbf\xfe6/a;#\xf1S\x97\xbd\xf9\xc1\xc3\xc2\xa1\xe5~\xd0\xe3\xe8\x9e\xec\xee\x97\xb2\xb6Dl\x04\xde\x9d.\x10nw\xbf<\xfc\xcbq\xd6s\xfc\x9e\xda\xb0\x93\xf2etE\x13\xdb\xa4*\x96T9\xbdh\xeeMqAC\x80O`WP\xf7\x16Q\xf2\x16^\xb5\xaf\x8e2\xb0\x08\xc5L:\xdd6n\x82\xa4\xc7U(5\xfa\xdf;\xac\xf20\x8fX5i\xc0\xc5j\xd5\x87	\x01k\xd7J\x1eq\x0dt\xfe\x8d1$z\xbfS\xa3W\x8d\xdd\x9b\xa9\"\xfcf\xe2\xd6\x88\x87\x1f[H\xa5\x83\xb2\xe1\x01\x0f\x11T\xf2\xb0\x9e0\x07v\xdb\xc4)\xfc[\xe3\x8e\xca\x95\xa5{\x1b\xaa\x0f\x01\xfb\xf6\x89\x97\x10\x9f\xc6\x15j\x87pi\xa2\xe9\x16\xfa\xfc\xeb?\xf5|\xeb\xe7\x9d\xc3\x9fl\xf6~\xe6\xe9\xbc\xecG\xffp\x12~\xa9\xfc\xcf\xfcr\xd2)\xc5\xcf'^\x01\x9a\x11?\xc4\xa8\xa9\xd6k4\xa4\xea\xbfx\x1e8\xf9k_{7`Q\xda\xba\xd1\x97i\xcd\xea\xfe\x93\xff\x1b\x00PK\x07\x08\xf7\\\xca\xe6\xf6\x0b\x00\x00?$\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x1b\x1cQ]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x0c\x00	\x00batchai.yamlUT\x05\x00\x01g\xec\xd2j\xec\\]s\xa3\xb8\xb6}\xcf\xafP\xf5\xdc*WM\xc5n\x0bc\x8c\xf3\xc68t\xb7o\xc7\x1f\xe383\x9d{k\x8a\x10\x90m& \xd1B$\xf1\xc9\xc9\x7f?%\xc06\xfe\x92\x94\xee\xcc\x9c\xe9\x14\xf3b\x0fk\xed-\xb4\xd0\xce^`\xd1\xe8\xd1\x0bS\x1f%g\xe0\xffk\x8dy\xc0j\xa7\xb5Fr\x8fk\xa7\xa0v\x8f\xb0O(\xff\x86\x89\x8f\x9c\x88\xf8i\x88\x92\xda\x1f'\x9e\xeb-\x90\xe3\x07\xf4\x0c\xfc\xcf\xd3/\xd6\xb4\xf7\xc9\xea;=\xab\xf7\xc9v\xce\xfb\x93\xe7\x93\xd0\xc5s\x0e]X\xc3\x8f\xcf'\x0c%\xec\xec\x04\x80\x88\xf8(t\x02\xbf\x1c4\xb5/\xa7\xce`tn_<\x9f\x00\x10\xe0\xcd\xb9\xfc\xdc\x88\x97|h\xfe\xc9\x16$;\xa1\x9f\x1b^R|\xc6q\xf1\xc5\xcb?\x17\xc5\xc7\xfax\xfe\xff4\xbd-\xb2\xccI\xfe\xf9\xa7{\xef\xe6\xdf\xeeX\xfe\x19\xa6\xc5\x01Z$O<7,\x0e\xb1\xe2P\xbc(\xf2&\x0f\xc1\xac\x88\x8b\xc3\xda\x1f'\x00\xfc\x04<\x12E.\xf6\x01CQ\x1c\xba\x0c%\x80\x11@S\x0c\xd8\x02\x819\xc2\x88\xba\x0cq8a	x\x08\xd8\x02\xdc\xd4\xeb4\xc57\xa7\xe0v\x99\x91\xd0#C8	\x08\x06d\x96\xf1\xc0,\x08\xd1)@\x8dy#\x1b\x02\x8099\x03s\x92\x83\x8d\xf7OO\x0d\xfe\x8d_\x83g\xae\xdcOY\x9a$\xc0\xf3\x10\xe5\x1c>\xfe\xea\xc4\\\x9c< \x8a\xfc\xd5p\xd9\x95\x00A\x02\xd2\x04\xf9 \x98\x01L\x18\xf0\x08\x9e\x05\xf3\x94\"\xff\x04\xf0h\x8chr\x06\x9ex\xf6\xc8}t(\x8a\xdd\x80:.\xe3\xb3d\xc9\xfeE\xb4\xbe8\x13{l\xf5'\x8e5\x9d\xda\x83\xf1\xf4\x92\x87\xc6\x94Dqv\xf9y\xd2\x10%\xf9W\x00\xea\xe0\xddN\x86\xc9\xd5\x85\xed\xc0\xe7wb\x82&#\xb4d\x04]Fh\xcb\x08\x86\x8c\xd0\x91\x11L\x19\xa1+#\xc0\xa6\x94!\xd5\x12J\xc5\x84R5\xa1TN(\xd5\x13J\x05\x85RE\xa1TR(\xd5T\xdb\xd1tp]\x96S\x80i\x02\xac%\xc0t\x01\xd6\x16`\x86\x00\xeb\x080S\x80u\x05\x18l\x8a@\x912P\x13E\x8a\xb4\x81\xba(R\xa4\x0e4D\x91\"}\xa0)\x8a\x14)\xb4^:\xab&p\x06\xfe]d\x02\xc0J\x80\x8b\x81\x8f\xeeQHbD\x01z\x8c\x11e\xa7`I\xd2\x1aE\x80\xa2\xaf)J\xb2\x16A\xc0\x03\x0dX\xf1'\xdcs\x13\x94\xf0\xbf\xa0\xf7\x81\x8f|\xe0\x11\x1f\x81\x19	C\xf2\x10\xe09\xb8E!yHV\x7fT\xd7c\xad:Cv<\xeb\x0d\xf9\x7f\xeb/c\x97-x\x9b\xc9\xd2\xb1\xbc\xa3\x9c\xf1\xb0\xd8e\x8bC\x01\xf6c\x900>\"\xcf\x9b\x85\x9dm\xb2==5P\x81;\x1cw8~(K\x8f`\x860\xdb\x1bys\xe6777\xeb\xefOO\x0d\x9e\xc8a$\xcb\xfa\xfc|\x84U\xe7\xdd\xab\x91b\x8f\xdc\xf3\x06\xb7\x1a}\x9d\xe7\x17.\x12\x08\x03\x8c\x12>2o\x92\xe5y\x03\x97\xa2\xa2\xf9\xdd\x97\x1b\xe4jJE\xcf^\"\xd6\x00\x1f\x88\x97&\x80\xe4]\xbdt}\xb2\xa13y\x16(:\x05\xdc\x08\xf8\x04\xd7\x18\xf0\xd38\x0c<\x97_M>lFvC\x8a\\\x7f\xb9\x1a\xaf4\xfb\xa7\xa7\xfdi\xacf\xc1\xe7\x89\xb0\xff\xfc|\xe2-\x90ww\xccL\xf5>\xd9\xbd\xcf\x87\xdd\xd49\xf1\xee\x10\xe5\xa6\xa2vZ\xfb\xb9\xf1\x18\x85\xb5\xd3\xbf\xccb-\x18O\xcf)\x0b\x16\xed\xba\xae?\x93\x95\x97\xfb&\xff\x15S\xc2\xc8\x9e\x15\x8b\xfc\x02\xcd\xa7\x95,\xb2\x8f\xa5[Ls\x19\xe5V-A\xf7\x88\x06lY6/\xb9h\x97\xf6o\xf6\xa4?\xbd\xe6\xbaq\xcel\xe9\x14\xe6i\x9f\xcb\x99\x1f\xae\x9d\xdeh0\xb0\x86\xe7\n\xfe\xa8\xb8.\xdfc\x90\xf2\x14\"\x87TbhRFK\xca\xd0\xa5\x8c\xb6\x94aH\x19\x1d)\xc3\x942\xbaR\x06lJ%\x83rU\xa1&\xa7\xc8u\x85\xba\x9c\"W\x16\x1ar\x8a\\[h\xca)ru\x0f\x98\xa6\xf2\x18\"P\x13\x81-\x11\xa8\x8b\xc0\xb6\x084D`G\x04\x9a\"\xb0+\x02aS\x88\n5\x82\x9a0V\xa8\x12\xd4\x85\xb1B\x9d\xa0!\x8c\x15*\x05Ma\xacP\xab\xd71R\xb7K~oK\xb3;\xf1\xacgnl\x14o\x82\xdb}7#\xc8\x0d\x13\x0f\\\xe7+;\xa6C\x1eg\x9b\xbcf\xc8\xccNv*\x12\xb7\xe3\x07\xb3Y\xd9\xe3\x8cp\xb8\xcc\xdd \xf0\x16.\x9eo\xacNv\x12\xdc\xe20\x02nQ~\xe2\xc8\xdf\xb12y\x8c\x9f\x9b\xa4\xb2\x7f\xa1(&\x94\x81 I\xd2M\xca\x14o\xf1A\x8aC\x94$\xdc\xfb,\xb3\x91\xdc\xd9\x0cyl\xe3\xa4\x8a3*	~ss\xc3gP\x9a\xd6jF\xc7&\x9d[\x9f\x13\xf4\x18\x87n\x80\x8f\xb9\x1f\xfb\xcb\xf8\xc2\xea\x0f\x95\xfc\xcf?\xf2\xd1\xd2ak#\xb14j\xcfWV\xda\x88\x0c\xc4\x16GS\xe0\xb4\x148\xba\x02\xa7\xad\xc01\x148\x1d\x05\x8e\xa9\xc0\xe9*p`SAD\xa8\xa24\xd4TH*ZC]\x85\xa4\xa264TH*zCS\x85\xa4\xa2\xf8\x01\x9b\xb1=\x92\x18\xd6\xc4pK\x0c\xebb\xb8-\x86\x0d1\xdc\x11\xc3\xa6\x18\xee\x8aa\xd8\x94\xe0\x12\xdd\xa0&\x89\x97(\x07uI\xbcD;hH\xe2%\xeaAS\x12/\xd1\xef\xf5\xedH\xd1\xc6D\x86\xa4\xa0\xa8[\x92\"@\xd5\x94\xac\xe8\xfb\x89\x8f\xd8\x92\"`\xa7G\x9fx$\x8a\x10>\xfa\xe3\x0e\xbfC\xb6\x87?\xd6\xef;G\x9b\xb0z\xb3]M[\xd4l\xb78\x9a\x02\xa7\xa5\xc0\xd1\x158m\x05\x8e\xa1\xc0\xe9(pL\x05NW\x81\x03\x9b\n\"B\x15\xa5\xa1\xa6BR\xd1\x1a\xea*$\x15\xb5\xa1\xa1BR\xd1\x1b\x9a*$\x15\xc5\x0f4\xdb\xed\x91\xc4\xb0&\x86[bX\x17\xc3m1l\x88\xe1\x8e\x186\xc5pW\x0c\xc3\xa6\x04\x97\xe8\x065I\xbcD9\xa8K\xe2%\xdaAC\x12/Q\x0f\x9a\x92x\x89~\xaf\xdfl\x8b\x0e%\xbc\xfb\xcf)/\xb8\xff/\xba\x9eb\xb3]\xd1\xd7\x1cY\xb3-\x02v\x9b-E3\xd7c\x84\x1e\xeb\xb6\x13\xfb\x83\xd5\x9b\x8e&?T\xbb\xdd\xef\xb1s\xe2\x86E_E\x8f\x8c\xba\x1e\xabG\x88-\x88\x7f\x06\xde\x1d\x98\xec\xc7\x91u\xe1\xd8_\xa6\x13\xab7u\x06\xf6\xf4\xd3\xe8\xbcXC\x14E\xe4\x1e\xd5W\xbf\xc2\x04\x04\x0b2L\xec\xc1\xe87\xdb9\xbf\x1a_\xf4{\xd6\xb4?\x1a\x16Y\xf8V	\x8a\x83\x7f\xa1z\xb2\xc4\xcc}\x14\xe4\xe0\xbaO\x86\xfd\xff\xb3\x9d\xcb\xeb\xe1\xd4\xfaRdH\x82(\x0e\x83\xd9\xb2\xee\x11\xec\x07\xfc<\xf8\x04\x8f\xa7\xb9\xec\x0f\xc6\x17\xf9\x0f\x0c\xc3\xf3>?\x15\xeb\xe2\xb2\xc8\x15D|!\xa3:v\xa3\x00\xcf\x05I\xfa\x83\xf1\x84Ohh\x0d\xfa\xc3\x8f\xcf\xef\x94\x9f\x14\xacW\x91\xc8\xbdl\x934\x15RK\x85\xa4\xab\x90\xda*$C\x85\xd4Q!\x99*\xa4\xae\n	6U\xd4\x84J\x9aCM\x89\xa5\xa4:\xd4\x95XJ\xbaCC\x89\xa5\xa4<4\x95XJ\xda\x1f04;\x83IpM\x82\xb7$\xb8.\xc1\xdb\x12\xdc\x90\xe0\x1d	nJ\xf0\xae\x04\x87M\x19A\xa6 \xd4d\x19d\x1aB]\x96A\xa6\"4d\x19d:BS\x96A\xa6\xe4\xeb\x9b\x9c\x953\xd8v9\x80\x91\x07\x97\xfaI\xf1\xd3@\xd1W\xd7#==5\xb2Ce\xf7\xf2!\xdb\\\"\xd8Y\xb2\x1aI\xdd,\xad\"T\xdd\xd2\x9a\xaf\xfclb\x15\xb1\xef\x97\xee\x03\xf4p\xdc-\xfd\xd6\xb7\x7f\xaf6K\xbcl\xb3D\xa1Zy\xb7\x84\xda\x0f\x10E\xa0\xd8Tl(\x9a\x9c\xd2\x92St9\xa5-\xa7\x18rJGN1\xe5\x94\xae\x9c\x02\x9br\xed\xa0\x82\xbeP\x93_\x03\xd8\x92_'\xa8\xcb\xaf%l\xcb\xaf74\xe4k\x02v\xe4\xeb\x06\x9a\xf2\xb5\x05\xbb\n\xeboG\xe7\xc1\xf5\xd6\xf2\x84BT\x13\xa2-!\xaa\x0b\xd1\xb6\x105\x84hG\x88\x9aB\xb4+D\x0fZ\x83R\xb9\x8b\xd5\x82\x9a\x18\x16\xeb\x05u1,V\x0c\x1abX\xac\x194\xc5\xb0X\xb5\xbf\xc2\x06\xf0\x86W\xfe\xd5\x9d7\xe2mO\x10`\xe0\x828\x0d\xc3U\x8emG\x90\xa7XwwAW\xe7\xbc\xc3=}\xb3\x05a\x9b}\xca\x07Oq0\x0b\x90\x0f\xf8/\xfe`Fh\xe4\xb2\xfc\x8d\x88$\xa5\x94\xa4\xd8\xe7\x1b)=\xfe\x10\xe5\x91\xe5;\x12\x1a\xc0v\xbdE\xf6\x9d\xbf\xb1\x10S4\x0b\x1e\xf3\xfd\x05\x01K\xf2\xe38\x8dn\x11\xe5\xf9\xf9\xe41z\xc8N\x90\xbfOq\n\xd0\xa3\x87b\x96\x01\xf9\x13\x81b\xa7\xc3\x8bw$\x9c\xf0G\x01\xdcB\xf1e\xce\xdfb!1\xc2n\xf0~\x1e\xb3\xbaN2*v#t\x06J\x07\xf8\xb5\xe5o\x7f\xa4\x14mo\x08\xb4\xa6\xce\xd4\x1e\xd8\x13kz5\xb1\xb9\xff\x00\xabY;\x0f\x01\xf6\xc9\xc3\x19\x80\x9a\xd9l63\xc8\x8d\x03\xe7\x0ee\x9dx4\xb6\x87V\xdf\xb1\xc6}\xe7\xb3\x9dmW\x04\xe0\xd6M\x90\x93\xd2\xb0\x84\xffb]\xda\xce\xd5$\xfb\xd5\x05\x00\x16D\x88\xa4\xac|\n<\xc1\xb4?\xb0GW\xd3\x9c\xc3\xdf\xf1\xf0\x08\xf6RJ\xb3\xa7a\xf9\x9e\xe4\xa4\x94\x93o`\xec\x8d\x86\xbd\xab\xc9${\xa2g\xffze_\xe6oy\x80\xd5rJ\x9c\x18Q'\np\xcaP)t\xc5u\xc6\xf6\xc4\x19\xf4\x87W\xd3b\xd2\x8c\xdc!|$h:\xfal\x0f\xf7CbJ\x1e\x97;\xd3\x1dOF_\xae7\xf3\xcd)\x01N\x90\x97R\xe4$wA\xec\xe4\xbb:\xcb\x12\xe4A\xfd\xe1\xa5\xdd\xbb\x9a\xd8\xce\xe5\xe7\xfe\xb8\xd8\xdb\xb95P\x82\xe8\xfeH\x97\xf6\xa4L\x8a\xdd\xa4\xacT\x9eyl]\xae\xc5at\xb9\xf2E\\\xe8Co\xd1L\xec\xe9\xe4:\x13\xb9\xfc\xfe\xcc\xfa\xfa\xfa(t\xb7N?\xe7g\x17\xfa\xdc\xbe\xb0\x8a\x93\xce\xf3\x1f!\xf3\xe4[\xdc?\x03\xc6\xd0\xd6[\\y\xd6\xff\xedO\xa7|\x86\x87\xd7z=\np\xb0\xb7\xe07G\xbfk\xd5\x1b-S\xaf\x16}\xb5\xe8\xffa\x8b\xbe\xceRz\xbb\xf7W\xbet\xf4\xe0\x9f\xef\x17\x97C\xb5\xe8\xabE\xff\xcfY\xf4\xbb\xcb\xfd\xa0O1aW\xab\xd6y\xb5\xce\x7f\xdcu\xdej\xb4\x0f\xfey\xdf>\xbe\xf7\x07\xdeh\x99\xedj\xe1W\x0b\xff\x0d,\xfcz\x80\x13FS\x8f\x1d\xab\x80m\xc2n)\xe8\xcd\xaeQUBU	?X%\xb8\x98-(\x89\x03\xef\xbd\x17\xba\xa9\x8f\xea\xadz\xbb\x9e\x10\x8c\x11\xabg\xff\x8eG\xb9\x1c\x84\x94\xe2!\x1b-\xe5|q=\xec\x16\x95\xd6l\xae\x9e\xff\xf0\xd9{$\x8aC\xc4\xb7\x8f8\xf9\xfa*9\xaf\xd2]\x835\x9c~\x9a\x8c\xc6\xfd\xde\xf1\x1b\x87\x0d\xe5U\xef\x1d6i\xbf\xe5\xf6a\x13\xfd\xb2\xb2\xda\xc4\xa9\xdc9o\xd8\x7fCq\xed\x0dv\xe4\xfey\x97\xf7\x96n\xa1\x0f\x96\xd8\xc2\x0d\xeeRa\x85\xed1\xaa\x02\xab\n\xac*\xb0\x03\x056G\xfc\xb1\xeb\xfb\xfc\xa3\x0e\x1b\xedzL\xb7\xeec\xf6\x81M-\xe5\xe0+t\xaan\x07\xb6\xb5\x97V\xd2G{\xd0\x1f\n\\_\x81\xbfj\x93*r~K\x87*B_V=E\x90Jo*\xa8\x7fC\xddl\x8ft\xa4+m\x91\xdeRK\xda\xaf\x98Y\xe8&\x8b\xc35\xb3\x81^\xbdj`S7\xdb\x1d\xa3\xaa\x9a\xaaj~\x80\xaaa\x04\xcf\x97\xc1\xfb\xaf\x0f\x08k\x8dv\x9do\xbe\xa3\xf5\xce\xed\xa1\x07\x07b\xce\xf74\x9a\x96\xd61LQ\xc1l\x08\xa5[\xa2_\x7f\xb7\x87\xc7\xbbL\x86\xbej\x8f\xc92~K\x87\xc9\x02_V)Y\x88\xbc\xbb\xd4j\x7fQYl%\xce[G\xad\xf6F\x96<	C7r\xb7\x97\xfcYi9\xd7g14JmC\x81\xf8=\x8b\x1f\xb6`\xb3#4Y9c\x7f\xcb\xc8\xc5\x855\xb0\x8eW@\x81\xbfj\x0d\x149\xbf\xa5\n\x8a\xd0\x97\xd5A\x11TU\xc2\x7f\xa7\x12\xbe\xeaNS\xa9\x12\xd6\xc4\xaa\x12\xaaJx\x93\x95`\xaaV\x82YUBU	o\xac\x12\xa0~\xc8\xf5\x1cj\n\x87\x99UW\xa8\xba\xc2[\xe9\n[+\xfc\xab\xee\xc0\xa3m\xe10\xb3\xaa\x85\xaa\x16\xdef-\x88,\xd2afU\x0bU-\xfc8\xb5\xf0\x9f\x01\x00PK\x07\x08q9\xe3\xe7\xc0\x0b\x00\x00Sg\x00\x00PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xb7\x1cQ]\xf7\\\xca\xe6\xf6\x0b\x00\x00?$\x00\x00\x0b\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb4\x81\x00\x00\x00\x00batchai.envUT\x05\x00\x01\x8b\xed\xd2jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x1b\x1cQ]q9\xe3\xe7\xc0\x0b\x00\x00Sg\x00\x00\x0c\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb4\x818\x0c\x00\x00batchai.yamlUT\x05\x00\x01g\xec\xd2jPK\x05\x06\x00\x00\x00\x00\x02\x00\x02\x00\x85\x00\x00\x00;\x18\x00\x00\x00\x00"
+		fs.RegisterWithNamespace("res", data)
+	}
+	
\ No newline at end of file